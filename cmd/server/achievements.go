@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// checkAchievements awards every badge and achievement whose criteria the
+// user now meets. The user row is locked so concurrent checks cannot award
+// the same thing twice. Like recordActivity, it runs after the triggering
+// event has been committed and logs failures.
+func checkAchievements(db *gorm.DB, userID uint) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user store.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return err
+		}
+		stats, err := store.GetAchievementStats(tx, &user)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+
+		badges, err := store.GetUnearnedBadges(tx, user.ID, now)
+		if err != nil {
+			return err
+		}
+		for i := range badges {
+			if !badges[i].Qualifies(stats, now) {
+				continue
+			}
+			if err := grantBadge(tx, &user, &badges[i]); err != nil {
+				return err
+			}
+		}
+
+		achievements, err := store.GetUnearnedAchievements(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		for i := range achievements {
+			if !achievements[i].Qualifies(stats) {
+				continue
+			}
+			if err := grantAchievement(tx, &user, &achievements[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("achievements: failed to check user %d: %v", userID, err)
+	}
+}
+
+// grantBadge awards a badge the user earned, pays its XP reward and tells
+// them. Secret badges are announced as discoveries.
+func grantBadge(tx *gorm.DB, user *store.User, badge *store.Badge) error {
+	// An earlier award in this check, such as a bingo bundle, may have given it
+	held, err := store.HasUserBadge(tx, user.ID, int(badge.ID))
+	if err != nil || held {
+		return err
+	}
+	if _, err := awardBadge(tx, user, int(badge.ID)); err != nil {
+		return err
+	}
+	if badge.XPReward > 0 {
+		description := fmt.Sprintf("Earned the \"%s\" badge", badge.Name)
+		if _, err := store.AwardXP(tx, user.ID, badge.XPReward, "bonus", "badge", badge.ID, description); err != nil {
+			return err
+		}
+	}
+
+	title := "New badge unlocked!"
+	message := fmt.Sprintf("You earned the \"%s\" badge.", badge.Name)
+	if badge.IsSecret {
+		title = "Secret badge discovered!"
+		message = fmt.Sprintf("You uncovered the secret \"%s\" badge.", badge.Name)
+	}
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"badge_id":  badge.ID,
+		"xp":        badge.XPReward,
+		"is_secret": badge.IsSecret,
+	})
+	userID := int(user.ID)
+	notification := &store.Notification{
+		UserID:           &userID,
+		NotificationType: "reward_unlocked",
+		Title:            title,
+		Message:          message,
+		ActionURL:        stringPtr(fmt.Sprintf("/badges/%d", badge.ID)),
+		Data:             stringPtr(string(dataJSON)),
+	}
+	return store.CreateNotification(tx, notification)
+}
+
+// grantAchievement records an achievement the user earned, pays its XP and
+// coins and tells them.
+func grantAchievement(tx *gorm.DB, user *store.User, achievement *store.Achievement) error {
+	awarded, err := store.AwardAchievement(tx, user.ID, achievement.ID)
+	if err != nil || !awarded {
+		return err
+	}
+
+	description := fmt.Sprintf("Unlocked the \"%s\" achievement", achievement.Name)
+	if achievement.XP > 0 {
+		if _, err := store.AwardXP(tx, user.ID, achievement.XP, "bonus", "achievement", achievement.ID, description); err != nil {
+			return err
+		}
+	}
+	if achievement.Coins > 0 {
+		if _, err := store.CreditCoins(tx, user.ID, achievement.Coins, description, "achievement", achievement.ID); err != nil {
+			return err
+		}
+	}
+
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"achievement_id": achievement.ID,
+		"xp":             achievement.XP,
+		"coins":          achievement.Coins,
+	})
+	userID := int(user.ID)
+	notification := &store.Notification{
+		UserID:           &userID,
+		NotificationType: "reward_unlocked",
+		Title:            "Achievement unlocked!",
+		Message:          fmt.Sprintf("You unlocked \"%s\".", achievement.Name),
+		Data:             stringPtr(string(dataJSON)),
+	}
+	return store.CreateNotification(tx, notification)
+}
+
+// achievementQueue re-checks users whose XP changed. XP listeners run once
+// the awarding transaction has committed; the checks run on their own
+// goroutine so they do not hold up the caller, and a user queued several
+// times before their check starts is checked once.
+type achievementQueue struct {
+	db      *gorm.DB
+	mu      sync.Mutex
+	pending map[uint]bool
+	wake    chan struct{}
+}
+
+// watchAchievements re-checks achievements whenever a user's XP changes.
+func watchAchievements(db *gorm.DB) {
+	q := &achievementQueue{
+		db:      db,
+		pending: make(map[uint]bool),
+		wake:    make(chan struct{}, 1),
+	}
+	store.OnXPChange(q.add)
+	go q.run()
+}
+
+func (q *achievementQueue) add(userID uint) {
+	q.mu.Lock()
+	q.pending[userID] = true
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *achievementQueue) run() {
+	for range q.wake {
+		q.mu.Lock()
+		pending := q.pending
+		q.pending = make(map[uint]bool)
+		q.mu.Unlock()
+
+		for userID := range pending {
+			checkAchievements(q.db, userID)
+		}
+	}
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rohit21755/gg_server.git/internal/store"
@@ -261,6 +267,12 @@ func adminGetPendingSubmissionsHandler(db *gorm.DB) http.HandlerFunc {
 // Admin: Review submission
 func adminReviewSubmissionHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			writeJSONError(w, http.StatusUnauthorized, "authentication required")
+			return
+		}
+
 		submissionIDStr := chi.URLParam(r, "id")
 		submissionID, err := strconv.ParseUint(submissionIDStr, 10, 32)
 		if err != nil {
@@ -269,16 +281,18 @@ func adminReviewSubmissionHandler(db *gorm.DB) http.HandlerFunc {
 		}
 
 		var req struct {
-			Status  string `json:"status"` // approved, rejected
-			Comment string `json:"comment,omitempty"`
+			Status           string     `json:"status"` // approved, rejected, needs_revision
+			Comment          string     `json:"comment,omitempty"`
+			RevisionDeadline *time.Time `json:"revision_deadline,omitempty"`
+			RevisionHours    int        `json:"revision_hours,omitempty"`
 		}
 		if err := readJSON(w, r, &req); err != nil {
 			writeJSONError(w, http.StatusBadRequest, "invalid request body")
 			return
 		}
 
-		if req.Status != "approved" && req.Status != "rejected" {
-			writeJSONError(w, http.StatusBadRequest, "status must be 'approved' or 'rejected'")
+		if req.Status != "approved" && req.Status != "rejected" && req.Status != "needs_revision" {
+			writeJSONError(w, http.StatusBadRequest, "status must be 'approved', 'rejected' or 'needs_revision'")
 			return
 		}
 
@@ -288,22 +302,110 @@ func adminReviewSubmissionHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		submission.Status = req.Status
-		if err := db.Save(&submission).Error; err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "failed to update submission")
+		if req.Status == "needs_revision" {
+			if req.Comment == "" {
+				writeJSONError(w, http.StatusBadRequest, "comment is required when requesting a revision")
+				return
+			}
+
+			deadline := time.Now().Add(defaultRevisionWindow)
+			if req.RevisionDeadline != nil {
+				deadline = *req.RevisionDeadline
+			} else if req.RevisionHours > 0 {
+				deadline = time.Now().Add(time.Duration(req.RevisionHours) * time.Hour)
+			}
+			if !deadline.After(time.Now()) {
+				writeJSONError(w, http.StatusBadRequest, "revision deadline must be in the future")
+				return
+			}
+
+			if err := store.RequestSubmissionRevision(db, &submission, int(admin.ID), req.Comment, deadline); err != nil {
+				if errors.Is(err, store.ErrSubmissionAlreadyReviewed) {
+					writeJSONError(w, http.StatusConflict, err.Error())
+				} else {
+					writeJSONError(w, http.StatusInternalServerError, "failed to update submission")
+				}
+				return
+			}
+
+			notifySubmissionReviewed(db, &submission)
+
+			writeJSON(w, http.StatusOK, submission)
+			return
+		}
+
+		// Status change, reviewer details and payout happen atomically
+		if err := store.ReviewSubmission(db, &submission, req.Status, int(admin.ID), req.Comment); err != nil {
+			if errors.Is(err, store.ErrSubmissionAlreadyReviewed) {
+				writeJSONError(w, http.StatusConflict, err.Error())
+			} else {
+				writeJSONError(w, http.StatusInternalServerError, "failed to update submission")
+			}
 			return
 		}
 
-		// Update user stats if approved
-		if req.Status == "approved" {
-			db.Model(&store.User{}).Where("id = ?", submission.UserID).
-				UpdateColumn("approved_submissions", gorm.Expr("approved_submissions + 1"))
+		notifySubmissionReviewed(db, &submission)
+
+		if submission.Status == "approved" && submission.UserID != nil {
+			recordActivity(db, uint(*submission.UserID), store.QuestEvent{
+				Type:       store.QuestStepSubmissionApproved,
+				CampaignID: submission.CampaignID,
+				TaskID:     submission.TaskID,
+			})
 		}
 
 		writeJSON(w, http.StatusOK, submission)
 	}
 }
 
+// notifySubmissionReviewed tells the author of a submission about its review outcome.
+func notifySubmissionReviewed(db *gorm.DB, submission *store.Submission) {
+	if submission.UserID == nil {
+		return
+	}
+
+	var title, message string
+	switch submission.Status {
+	case "approved":
+		title = "Submission Approved"
+		message = fmt.Sprintf("Your submission was approved. You earned %d XP and %d coins.", submission.XPAwarded, submission.CoinsAwarded)
+	case "rejected":
+		title = "Submission Rejected"
+		message = "Your submission was rejected."
+	case "needs_revision":
+		title = "Revision Requested"
+		message = "Your submission needs changes."
+		if submission.RevisionDeadline != nil {
+			message = fmt.Sprintf("Your submission needs changes. Resubmit before %s.", submission.RevisionDeadline.Format(time.RFC1123))
+		}
+	default:
+		return
+	}
+	if submission.ReviewComments != nil && *submission.ReviewComments != "" {
+		message = fmt.Sprintf("%s Reviewer comment: %s", message, *submission.ReviewComments)
+	}
+
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"submission_id":     submission.ID,
+		"status":            submission.Status,
+		"xp_awarded":        submission.XPAwarded,
+		"coins_awarded":     submission.CoinsAwarded,
+		"revision_deadline": submission.RevisionDeadline,
+	})
+	notification := &store.Notification{
+		UserID:           submission.UserID,
+		NotificationType: "submission_status",
+		Title:            title,
+		Message:          message,
+		Data:             stringPtr(string(dataJSON)),
+		IsActionable:     true,
+		ActionURL:        stringPtr(fmt.Sprintf("/submissions/%d", submission.ID)),
+	}
+	if err := store.CreateNotification(db, notification); err != nil {
+		log.Printf("failed to notify user about submission %d: %s", submission.ID, err)
+	}
+}
+
 // Admin: Get submission statistics
 func adminGetSubmissionStatsHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -341,27 +443,18 @@ func adminAwardXPHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Create XP transaction
-		userIDInt := int(req.UserID)
-		desc := req.Description
-		tx := &store.XPTransaction{
-			UserID:          &userIDInt,
-			Amount:          req.Amount,
-			TransactionType: "correction",
-			BalanceAfter:    user.XP + req.Amount,
-			Description:     &desc,
-		}
-		if err := store.CreateXPTransaction(db, tx); err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "failed to create transaction")
+		// Award XP, recording the admin as the source
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			writeJSONError(w, http.StatusUnauthorized, "authentication required")
 			return
 		}
-
-		// Update user XP
-		user.XP += req.Amount
-		if err := store.UpdateUser(db, user); err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "failed to update user XP")
+		tx, err := store.AwardXP(db, user.ID, req.Amount, "correction", "admin", admin.ID, req.Description)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "failed to award XP")
 			return
 		}
+		user.XP = tx.BalanceAfter
 
 		writeJSON(w, http.StatusOK, map[string]interface{}{
 			"message": "XP awarded successfully",
@@ -390,35 +483,18 @@ func adminPenalizeXPHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Create XP transaction
-		userIDInt := int(req.UserID)
-		desc := req.Description
-		newXP := user.XP - req.Amount
-		if newXP < 0 {
-			newXP = 0
-		}
-		tx := &store.XPTransaction{
-			UserID:          &userIDInt,
-			Amount:          -req.Amount,
-			TransactionType: "correction",
-			BalanceAfter:    newXP,
-			Description:     &desc,
-		}
-		if err := store.CreateXPTransaction(db, tx); err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "failed to create transaction")
+		// Deduct XP, recording the admin as the source (never below 0)
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			writeJSONError(w, http.StatusUnauthorized, "authentication required")
 			return
 		}
-
-		// Update user XP (ensure it doesn't go below 0)
-		newXPValue := user.XP - req.Amount
-		if newXPValue < 0 {
-			newXPValue = 0
-		}
-		user.XP = newXPValue
-		if err := store.UpdateUser(db, user); err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "failed to update user XP")
+		tx, err := store.AwardXP(db, user.ID, -req.Amount, "correction", "admin", admin.ID, req.Description)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "failed to penalize XP")
 			return
 		}
+		user.XP = tx.BalanceAfter
 
 		writeJSON(w, http.StatusOK, map[string]interface{}{
 			"message": "XP penalized successfully",
@@ -440,14 +516,14 @@ func adminAwardBadgeHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		userIDInt := int(req.UserID)
-		badgeIDInt := int(req.BadgeID)
-		userBadge := &store.UserBadge{
-			UserID:  userIDInt,
-			BadgeID: badgeIDInt,
+		user, err := store.GetUserByID(db, req.UserID)
+		if err != nil {
+			writeJSONError(w, http.StatusNotFound, "user not found")
+			return
 		}
 
-		if err := store.CreateUserBadge(db, userBadge); err != nil {
+		userBadge, err := awardBadge(db, user, int(req.BadgeID))
+		if err != nil {
 			writeJSONError(w, http.StatusInternalServerError, "failed to award badge")
 			return
 		}
@@ -481,3 +557,35 @@ func adminDashboardHandler(db *gorm.DB) http.HandlerFunc {
 	}
 }
 
+
+// recordAdminAction writes an audit row for an admin operation. Failures are
+// logged rather than returned so auditing never blocks the action itself.
+func recordAdminAction(db *gorm.DB, r *http.Request, admin *store.User, actionType, resourceType string, resourceID uint, changes interface{}) {
+	adminID := int(admin.ID)
+	resID := int(resourceID)
+	action := &store.AdminAction{
+		AdminID:      &adminID,
+		ActionType:   actionType,
+		ResourceType: resourceType,
+		ResourceID:   &resID,
+		UserAgent:    stringPtr(r.UserAgent()),
+	}
+
+	if changes != nil {
+		if changesJSON, err := json.Marshal(changes); err == nil {
+			action.Changes = stringPtr(string(changesJSON))
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if net.ParseIP(host) != nil {
+		action.IPAddress = &host
+	}
+
+	if err := store.CreateAdminAction(db, action); err != nil {
+		log.Printf("failed to record admin action %s on %s %d: %s", actionType, resourceType, resourceID, err)
+	}
+}
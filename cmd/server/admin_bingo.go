@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// Admin Bingo Request
+type AdminBingoRequest struct {
+	Title       *string             `json:"title" validate:"omitempty,min=1,max=200"`
+	Description *string             `json:"description"`
+	Card        *store.BingoCard    `json:"card"`
+	Rewards     *store.BingoRewards `json:"rewards"`
+	StartDate   *time.Time          `json:"start_date"`
+	EndDate     *time.Time          `json:"end_date"`
+	IsActive    *bool               `json:"is_active"`
+}
+
+// applyTo copies every field set on the request onto the bingo.
+func (req *AdminBingoRequest) applyTo(bingo *store.BadgeBingo) {
+	if req.Title != nil {
+		bingo.Title = *req.Title
+	}
+	if req.Description != nil {
+		bingo.Description = req.Description
+	}
+	if req.Card != nil {
+		cardJSON, _ := json.Marshal(req.Card)
+		bingo.BingoCard = string(cardJSON)
+	}
+	if req.Rewards != nil {
+		rewardsJSON, _ := json.Marshal(req.Rewards)
+		bingo.BundleRewards = stringPtr(string(rewardsJSON))
+	}
+	if req.StartDate != nil {
+		bingo.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		bingo.EndDate = *req.EndDate
+	}
+	if req.IsActive != nil {
+		bingo.IsActive = *req.IsActive
+	}
+}
+
+// validateBingo checks the parts of a bingo that the request tags cannot: the
+// window, the card layout and the badges it refers to.
+func validateBingo(db *gorm.DB, bingo *store.BadgeBingo) error {
+	if !bingo.EndDate.After(bingo.StartDate) {
+		return errors.New("end_date must be after start_date")
+	}
+
+	card, err := bingo.ParseCard()
+	if err != nil {
+		return fmt.Errorf("invalid card: %w", err)
+	}
+	for i, cell := range card.Cells {
+		if cell.BadgeID != nil {
+			if _, err := store.GetBadgeByID(db, uint(*cell.BadgeID)); err != nil {
+				return fmt.Errorf("badge %d for cell %d not found", *cell.BadgeID, i+1)
+			}
+		}
+	}
+
+	rewards, err := bingo.ParseRewards()
+	if err != nil {
+		return fmt.Errorf("invalid rewards: %w", err)
+	}
+	for _, kind := range []string{store.BingoLineRow, store.BingoLineColumn, store.BingoLineDiagonal} {
+		reward := rewards.For(kind)
+		if reward == nil {
+			continue
+		}
+		if reward.XP < 0 || reward.Coins < 0 {
+			return fmt.Errorf("%s reward cannot be negative", kind)
+		}
+		if reward.BadgeID != nil {
+			if _, err := store.GetBadgeByID(db, uint(*reward.BadgeID)); err != nil {
+				return fmt.Errorf("badge %d for %s reward not found", *reward.BadgeID, kind)
+			}
+		}
+	}
+	return nil
+}
+
+// Admin: List bingo cards
+func adminGetBingosHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bingos, err := store.ListBadgeBingos(db)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		response := make([]map[string]interface{}, 0, len(bingos))
+		for i := range bingos {
+			payload := bingoCardPayload(&bingos[i], nil)
+			payload["created_at"] = bingos[i].CreatedAt
+			response = append(response, payload)
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Create bingo card
+func adminCreateBingoHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		var req AdminBingoRequest
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if req.Title == nil || req.Card == nil || req.StartDate == nil || req.EndDate == nil {
+			badRequestResponse(w, r, errors.New("title, card, start_date and end_date are required"))
+			return
+		}
+
+		bingo := &store.BadgeBingo{IsActive: true}
+		req.applyTo(bingo)
+
+		if err := validateBingo(db, bingo); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := store.CreateBadgeBingo(db, bingo); err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+		// Create skips a false is_active in favour of the column default
+		if !bingo.IsActive {
+			if err := db.Model(bingo).Update("is_active", false).Error; err != nil {
+				internalServerError(w, r, err)
+				return
+			}
+		}
+
+		recordAdminAction(db, r, admin, "bingo_created", "badge_bingo", bingo.ID, map[string]interface{}{
+			"start_date": bingo.StartDate,
+			"end_date":   bingo.EndDate,
+		})
+
+		if err := jsonResponse(w, http.StatusCreated, bingoCardPayload(bingo, nil)); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Update bingo card
+func adminUpdateBingoHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		bingoIDStr := chi.URLParam(r, "id")
+		bingoID, err := strconv.ParseUint(bingoIDStr, 10, 32)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("invalid bingo ID"))
+			return
+		}
+
+		bingo, err := store.GetBadgeBingoByID(db, uint(bingoID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("bingo not found"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		var req AdminBingoRequest
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		req.applyTo(bingo)
+
+		if err := validateBingo(db, bingo); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := store.UpdateBadgeBingo(db, bingo); err != nil {
+			if errors.Is(err, store.ErrBingoNotEditable) {
+				conflictResponse(w, r, err)
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		recordAdminAction(db, r, admin, "bingo_updated", "badge_bingo", bingo.ID, req)
+
+		if err := jsonResponse(w, http.StatusOK, bingoCardPayload(bingo, nil)); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// Admin Campaign Request
+type AdminCampaignRequest struct {
+	Title            *string          `json:"title" validate:"omitempty,min=1,max=200"`
+	Description      *string          `json:"description"`
+	CampaignType     *string          `json:"campaign_type" validate:"omitempty,oneof=brand_specific thematic seasonal gg_led flash weekly_vibe limited_edition"`
+	Category         *string          `json:"category" validate:"omitempty,oneof=solo group online offline"`
+	BannerImageURL   *string          `json:"banner_image_url" validate:"omitempty,url"`
+	StartDate        *time.Time       `json:"start_date"`
+	EndDate          *time.Time       `json:"end_date"`
+	MaxParticipants  *int             `json:"max_participants" validate:"omitempty,gte=0"`
+	Priority         *string          `json:"priority" validate:"omitempty,oneof=low medium high"`
+	IsLimitedEdition *bool            `json:"is_limited_edition"`
+	IsGGLed          *bool            `json:"is_gg_led"`
+	Metadata         *json.RawMessage `json:"metadata"`
+}
+
+// applyTo copies every field set on the request onto the campaign.
+func (req *AdminCampaignRequest) applyTo(campaign *store.Campaign) {
+	if req.Title != nil {
+		campaign.Title = *req.Title
+	}
+	if req.Description != nil {
+		campaign.Description = req.Description
+	}
+	if req.CampaignType != nil {
+		campaign.CampaignType = *req.CampaignType
+	}
+	if req.Category != nil {
+		campaign.Category = req.Category
+	}
+	if req.BannerImageURL != nil {
+		campaign.BannerImageURL = req.BannerImageURL
+	}
+	if req.StartDate != nil {
+		campaign.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		campaign.EndDate = *req.EndDate
+	}
+	if req.MaxParticipants != nil {
+		campaign.MaxParticipants = req.MaxParticipants
+	}
+	if req.Priority != nil {
+		campaign.Priority = *req.Priority
+	}
+	if req.IsLimitedEdition != nil {
+		campaign.IsLimitedEdition = *req.IsLimitedEdition
+	}
+	if req.IsGGLed != nil {
+		campaign.IsGGLed = *req.IsGGLed
+	}
+	if req.Metadata != nil {
+		campaign.Metadata = stringPtr(string(*req.Metadata))
+	}
+}
+
+// getCampaignFromURL loads the campaign referenced by the {id} URL parameter,
+// writing the error response itself when it cannot.
+func getCampaignFromURL(db *gorm.DB, w http.ResponseWriter, r *http.Request) (*store.Campaign, bool) {
+	campaignIDStr := chi.URLParam(r, "id")
+	campaignID, err := strconv.ParseUint(campaignIDStr, 10, 32)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid campaign ID"))
+		return nil, false
+	}
+
+	campaign, err := store.GetCampaignByID(db, uint(campaignID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			notFoundResponse(w, r, errors.New("campaign not found"))
+		} else {
+			internalServerError(w, r, err)
+		}
+		return nil, false
+	}
+	return campaign, true
+}
+
+// Admin: Create campaign
+func adminCreateCampaignHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		var req AdminCampaignRequest
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if req.Title == nil || req.CampaignType == nil || req.StartDate == nil || req.EndDate == nil {
+			badRequestResponse(w, r, errors.New("title, campaign_type, start_date and end_date are required"))
+			return
+		}
+
+		adminID := int(admin.ID)
+		campaign := &store.Campaign{
+			UUID:      uuid.New().String(),
+			Status:    "draft",
+			Priority:  "medium",
+			CreatedBy: &adminID,
+		}
+		req.applyTo(campaign)
+
+		if !campaign.EndDate.After(campaign.StartDate) {
+			badRequestResponse(w, r, errors.New("end_date must be after start_date"))
+			return
+		}
+
+		if err := store.CreateCampaign(db, campaign); err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		recordAdminAction(db, r, admin, "campaign_created", "campaign", campaign.ID, map[string]interface{}{
+			"status": campaign.Status,
+		})
+
+		if err := jsonResponse(w, http.StatusCreated, campaign); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Update campaign
+func adminUpdateCampaignHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		campaign, ok := getCampaignFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		if campaign.Status == "completed" || campaign.Status == "cancelled" {
+			badRequestResponse(w, r, errors.New("campaign can no longer be edited"))
+			return
+		}
+
+		var req AdminCampaignRequest
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		req.applyTo(campaign)
+
+		if !campaign.EndDate.After(campaign.StartDate) {
+			badRequestResponse(w, r, errors.New("end_date must be after start_date"))
+			return
+		}
+
+		if err := store.UpdateCampaign(db, campaign); err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		recordAdminAction(db, r, admin, "campaign_updated", "campaign", campaign.ID, req)
+
+		if err := jsonResponse(w, http.StatusOK, campaign); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Change campaign status
+func adminTransitionCampaignHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		campaign, ok := getCampaignFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Status string `json:"status" validate:"required,oneof=draft active paused completed cancelled"`
+			Reason string `json:"reason"`
+		}
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		from := campaign.Status
+		if !store.CanTransitionCampaign(from, req.Status) {
+			conflictResponse(w, r, errors.New("campaign cannot move from "+from+" to "+req.Status))
+			return
+		}
+
+		var leaderboard *store.Leaderboard
+		var certificates []store.Certificate
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := store.TransitionCampaign(tx, campaign, req.Status); err != nil {
+				return err
+			}
+			if req.Status == "completed" {
+				var err error
+				leaderboard, certificates, err = settleCompletedCampaign(tx, campaign)
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, store.ErrInvalidCampaignTransition) {
+				conflictResponse(w, r, errors.New("campaign status changed concurrently, please retry"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		recordAdminAction(db, r, admin, "campaign_status_changed", "campaign", campaign.ID, map[string]interface{}{
+			"from":   from,
+			"to":     req.Status,
+			"reason": req.Reason,
+		})
+
+		for i := range certificates {
+			notifyCertificateIssued(db, &certificates[i])
+		}
+
+		response := map[string]interface{}{
+			"campaign": campaign,
+		}
+		if leaderboard != nil {
+			response["leaderboard_id"] = leaderboard.ID
+			response["certificates_issued"] = len(certificates)
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// settleCompletedCampaign freezes the final standings of a campaign that has
+// just completed and issues its certificates.
+func settleCompletedCampaign(tx *gorm.DB, campaign *store.Campaign) (*store.Leaderboard, []store.Certificate, error) {
+	leaderboard, err := store.FreezeCampaignLeaderboard(tx, campaign)
+	if err != nil {
+		return nil, nil, err
+	}
+	certificates, err := store.IssueCampaignCertificates(tx, campaign, leaderboard)
+	if err != nil {
+		return nil, nil, err
+	}
+	return leaderboard, certificates, nil
+}
+
+// Admin: Delete campaign
+func adminDeleteCampaignHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		campaign, ok := getCampaignFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		// Only drafts are removed outright; anything that went live must be cancelled
+		if campaign.Status != "draft" {
+			conflictResponse(w, r, errors.New("only draft campaigns can be deleted, cancel the campaign instead"))
+			return
+		}
+
+		if err := store.DeleteCampaign(db, campaign.ID); err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		recordAdminAction(db, r, admin, "campaign_deleted", "campaign", campaign.ID, map[string]interface{}{
+			"title": campaign.Title,
+		})
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
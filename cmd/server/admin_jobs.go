@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// Admin: List scheduled jobs
+func adminGetJobsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := 50
+		offset := 0
+
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+				limit = l
+			}
+		}
+		if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+				offset = o
+			}
+		}
+
+		status := r.URL.Query().Get("status")
+		if err := Validate.Var(status, "omitempty,oneof=pending running completed failed cancelled"); err != nil {
+			badRequestResponse(w, r, errors.New("invalid job status"))
+			return
+		}
+
+		jobs, total, err := store.ListScheduledJobs(db, status, r.URL.Query().Get("job_type"), limit, offset)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		response := make([]map[string]interface{}, len(jobs))
+		for i := range jobs {
+			response[i] = jobPayload(&jobs[i])
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"jobs":   response,
+			"total":  total,
+			"limit":  limit,
+			"offset": offset,
+		})
+	}
+}
+
+// Admin: Cancel a pending job
+func adminCancelJobHandler(db *gorm.DB) http.HandlerFunc {
+	return adminJobAction(db, "job_cancelled", store.CancelJob)
+}
+
+// Admin: Re-run a finished job
+func adminRerunJobHandler(db *gorm.DB) http.HandlerFunc {
+	return adminJobAction(db, "job_rerun", store.RerunJob)
+}
+
+// adminJobAction applies a state change to the job in the {id} URL parameter
+// and records it in the admin log.
+func adminJobAction(db *gorm.DB, actionType string, apply func(db *gorm.DB, id uint) (*store.ScheduledJob, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		jobID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("invalid job ID"))
+			return
+		}
+
+		job, err := apply(db, uint(jobID))
+		if err != nil {
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				notFoundResponse(w, r, errors.New("job not found"))
+			case errors.Is(err, store.ErrJobNotCancellable), errors.Is(err, store.ErrJobNotRerunnable):
+				conflictResponse(w, r, err)
+			default:
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		recordAdminAction(db, r, admin, actionType, "scheduled_job", job.ID, map[string]interface{}{
+			"job_type": job.JobType,
+			"status":   job.Status,
+		})
+
+		writeJSON(w, http.StatusOK, jobPayload(job))
+	}
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// AssignmentTarget describes who a task is assigned to. Only the list
+// matching the task's AssignmentType is used.
+type AssignmentTarget struct {
+	Roles      []string `json:"roles,omitempty"`
+	CollegeIDs []int    `json:"college_ids,omitempty"`
+	StateIDs   []int    `json:"state_ids,omitempty"`
+	UserIDs    []int    `json:"user_ids,omitempty"`
+}
+
+// Admin Task Request
+type AdminTaskRequest struct {
+	CampaignID             *int              `json:"campaign_id" validate:"omitempty,gt=0"`
+	Title                  *string           `json:"title" validate:"omitempty,min=1,max=200"`
+	Description            *string           `json:"description" validate:"omitempty,min=1"`
+	TaskType               *string           `json:"task_type" validate:"omitempty,oneof=solo group online offline"`
+	ProofType              *string           `json:"proof_type" validate:"omitempty,oneof=screenshot url pdf video text"`
+	XPReward               *int              `json:"xp_reward" validate:"omitempty,gte=0"`
+	CoinReward             *int              `json:"coin_reward" validate:"omitempty,gte=0"`
+	DurationHours          *int              `json:"duration_hours" validate:"omitempty,gt=0"`
+	Priority               *string           `json:"priority" validate:"omitempty,oneof=low medium high flash"`
+	AssignmentType         *string           `json:"assignment_type" validate:"omitempty,oneof=role college state individual"`
+	AssignmentTarget       *AssignmentTarget `json:"assignment_target"`
+	MaxSubmissions         *int              `json:"max_submissions" validate:"omitempty,gt=0"`
+	IsActive               *bool             `json:"is_active"`
+	SubmissionInstructions *string           `json:"submission_instructions"`
+}
+
+// applyTo copies every field set on the request onto the task.
+func (req *AdminTaskRequest) applyTo(task *store.Task) error {
+	if req.CampaignID != nil {
+		task.CampaignID = req.CampaignID
+	}
+	if req.Title != nil {
+		task.Title = *req.Title
+	}
+	if req.Description != nil {
+		task.Description = *req.Description
+	}
+	if req.TaskType != nil {
+		task.TaskType = *req.TaskType
+	}
+	if req.ProofType != nil {
+		task.ProofType = *req.ProofType
+	}
+	if req.XPReward != nil {
+		task.XPReward = *req.XPReward
+	}
+	if req.CoinReward != nil {
+		task.CoinReward = *req.CoinReward
+	}
+	if req.DurationHours != nil {
+		task.DurationHours = req.DurationHours
+	}
+	if req.Priority != nil {
+		task.Priority = *req.Priority
+	}
+	if req.AssignmentType != nil {
+		task.AssignmentType = req.AssignmentType
+	}
+	if req.AssignmentTarget != nil {
+		targetJSON, err := json.Marshal(req.AssignmentTarget)
+		if err != nil {
+			return err
+		}
+		task.AssignmentTarget = stringPtr(string(targetJSON))
+	}
+	if req.MaxSubmissions != nil {
+		task.MaxSubmissions = *req.MaxSubmissions
+	}
+	if req.IsActive != nil {
+		task.IsActive = *req.IsActive
+	}
+	if req.SubmissionInstructions != nil {
+		task.SubmissionInstructions = req.SubmissionInstructions
+	}
+	return nil
+}
+
+// errInvalidAssignmentTarget marks assignment targets the admin must fix, as
+// opposed to database failures while checking them.
+var errInvalidAssignmentTarget = errors.New("invalid assignment target")
+
+// buildTaskAssignments turns a task's assignment target into TaskAssignment rows.
+func buildTaskAssignments(db *gorm.DB, task *store.Task, assignedBy int) ([]store.TaskAssignment, error) {
+	if task.AssignmentType == nil {
+		return nil, nil
+	}
+
+	var target AssignmentTarget
+	if task.AssignmentTarget != nil && *task.AssignmentTarget != "" {
+		if err := json.Unmarshal([]byte(*task.AssignmentTarget), &target); err != nil {
+			return nil, errInvalidAssignmentTarget
+		}
+	}
+
+	taskID := int(task.ID)
+	var assignments []store.TaskAssignment
+	newAssignment := func(assigneeType string, assigneeID *int, role *string) store.TaskAssignment {
+		return store.TaskAssignment{
+			TaskID:       &taskID,
+			AssigneeType: assigneeType,
+			AssigneeID:   assigneeID,
+			AssigneeRole: role,
+			AssignedBy:   &assignedBy,
+			Status:       "assigned",
+		}
+	}
+
+	switch *task.AssignmentType {
+	case "role":
+		if len(target.Roles) == 0 {
+			return nil, fmt.Errorf("%w: must list at least one role", errInvalidAssignmentTarget)
+		}
+		for _, role := range target.Roles {
+			if role != "ca" && role != "admin" && role != "state_lead" && role != "moderator" {
+				return nil, fmt.Errorf("%w: invalid role %s", errInvalidAssignmentTarget, role)
+			}
+			assignments = append(assignments, newAssignment("role", nil, stringPtr(role)))
+		}
+	case "college":
+		if len(target.CollegeIDs) == 0 {
+			return nil, fmt.Errorf("%w: must list at least one college", errInvalidAssignmentTarget)
+		}
+		var count int64
+		if err := db.Model(&store.College{}).Where("id IN ?", target.CollegeIDs).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if int(count) != len(target.CollegeIDs) {
+			return nil, fmt.Errorf("%w: references unknown colleges", errInvalidAssignmentTarget)
+		}
+		for _, id := range target.CollegeIDs {
+			assignments = append(assignments, newAssignment("college", intPtr(id), nil))
+		}
+	case "state":
+		if len(target.StateIDs) == 0 {
+			return nil, fmt.Errorf("%w: must list at least one state", errInvalidAssignmentTarget)
+		}
+		var count int64
+		if err := db.Model(&store.State{}).Where("id IN ?", target.StateIDs).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if int(count) != len(target.StateIDs) {
+			return nil, fmt.Errorf("%w: references unknown states", errInvalidAssignmentTarget)
+		}
+		for _, id := range target.StateIDs {
+			assignments = append(assignments, newAssignment("state", intPtr(id), nil))
+		}
+	case "individual":
+		if len(target.UserIDs) == 0 {
+			return nil, fmt.Errorf("%w: must list at least one user", errInvalidAssignmentTarget)
+		}
+		var count int64
+		if err := db.Model(&store.User{}).Where("id IN ?", target.UserIDs).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if int(count) != len(target.UserIDs) {
+			return nil, fmt.Errorf("%w: references unknown users", errInvalidAssignmentTarget)
+		}
+		for _, id := range target.UserIDs {
+			assignments = append(assignments, newAssignment("user", intPtr(id), nil))
+		}
+	}
+
+	return assignments, nil
+}
+
+// Admin: Create task
+func adminCreateTaskHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		var req AdminTaskRequest
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if req.CampaignID == nil || req.Title == nil || req.Description == nil || req.TaskType == nil || req.ProofType == nil {
+			badRequestResponse(w, r, errors.New("campaign_id, title, description, task_type and proof_type are required"))
+			return
+		}
+		if (req.AssignmentType == nil) != (req.AssignmentTarget == nil) {
+			badRequestResponse(w, r, errors.New("assignment_type and assignment_target must be provided together"))
+			return
+		}
+
+		if _, err := store.GetCampaignByID(db, uint(*req.CampaignID)); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("campaign not found"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		adminID := int(admin.ID)
+		task := &store.Task{
+			UUID:           uuid.New().String(),
+			Priority:       "medium",
+			MaxSubmissions: 1,
+			IsActive:       true,
+			CreatedBy:      &adminID,
+		}
+		if err := req.applyTo(task); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := store.CreateTask(tx, task); err != nil {
+				return err
+			}
+			assignments, err := buildTaskAssignments(tx, task, adminID)
+			if err != nil {
+				return err
+			}
+			return store.ReplaceTaskAssignments(tx, task.ID, assignments)
+		})
+		if err != nil {
+			if errors.Is(err, errInvalidAssignmentTarget) {
+				badRequestResponse(w, r, err)
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		assignments, _ := store.GetTaskAssignmentsByTask(db, task.ID)
+
+		response := map[string]interface{}{
+			"task":        task,
+			"assignments": assignments,
+		}
+
+		if err := jsonResponse(w, http.StatusCreated, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Update task
+func adminUpdateTaskHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		taskIDStr := chi.URLParam(r, "id")
+		taskID, err := strconv.ParseUint(taskIDStr, 10, 32)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("invalid task ID"))
+			return
+		}
+
+		task, err := store.GetTaskByID(db, uint(taskID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("task not found"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		var req AdminTaskRequest
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if req.CampaignID != nil {
+			if _, err := store.GetCampaignByID(db, uint(*req.CampaignID)); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					notFoundResponse(w, r, errors.New("campaign not found"))
+				} else {
+					internalServerError(w, r, err)
+				}
+				return
+			}
+		}
+
+		if err := req.applyTo(task); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		retarget := req.AssignmentType != nil || req.AssignmentTarget != nil
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := store.UpdateTask(tx, task); err != nil {
+				return err
+			}
+			if !retarget {
+				return nil
+			}
+			assignments, err := buildTaskAssignments(tx, task, int(admin.ID))
+			if err != nil {
+				return err
+			}
+			return store.ReplaceTaskAssignments(tx, task.ID, assignments)
+		})
+		if err != nil {
+			if errors.Is(err, errInvalidAssignmentTarget) {
+				badRequestResponse(w, r, err)
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		assignments, _ := store.GetTaskAssignmentsByTask(db, task.ID)
+
+		response := map[string]interface{}{
+			"task":        task,
+			"assignments": assignments,
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Delete task
+func adminDeleteTaskHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskIDStr := chi.URLParam(r, "id")
+		taskID, err := strconv.ParseUint(taskIDStr, 10, 32)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("invalid task ID"))
+			return
+		}
+
+		task, err := store.GetTaskByID(db, uint(taskID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("task not found"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		submissionCount, err := store.CountSubmissionsByTask(db, task.ID)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		// Tasks with submissions are deactivated so their history survives
+		if submissionCount > 0 {
+			task.IsActive = false
+			if err := store.UpdateTask(db, task); err != nil {
+				internalServerError(w, r, err)
+				return
+			}
+
+			response := map[string]interface{}{
+				"message": "Task has submissions and was deactivated instead of deleted",
+				"task":    task,
+			}
+			if err := jsonResponse(w, http.StatusOK, response); err != nil {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		if err := store.DeleteTask(db, task.ID); err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
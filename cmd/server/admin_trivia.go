@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// Questions answered correctly by at least triviaTooEasyRate of players are
+// flagged too easy, and by at most triviaTooHardRate too hard. Questions with
+// fewer than triviaMinAnswersForRating answers are not rated.
+const (
+	triviaTooEasyRate         = 0.9
+	triviaTooHardRate         = 0.2
+	triviaMinAnswersForRating = 10
+)
+
+// Admin: Per-question answer stats for a trivia tournament
+func adminGetTriviaQuestionStatsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		triviaID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("invalid trivia ID"))
+			return
+		}
+
+		trivia, err := store.GetTriviaTournamentByID(db, uint(triviaID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("trivia not found"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		questions, err := trivia.ParseQuestions()
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		stats, err := store.GetTriviaQuestionStats(db, trivia.ID)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+		byQuestion := make(map[int]store.TriviaQuestionStat, len(stats))
+		for _, stat := range stats {
+			byQuestion[stat.QuestionIndex] = stat
+		}
+
+		participants, err := store.CountTriviaParticipants(db, trivia.ID)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		response := make([]map[string]interface{}, 0, len(questions))
+		for i, question := range questions {
+			stat := byQuestion[i]
+
+			var correctRate *float64
+			difficulty := "unrated"
+			if stat.Answered > 0 {
+				rate := float64(stat.Correct) / float64(stat.Answered)
+				correctRate = &rate
+				if stat.Answered >= triviaMinAnswersForRating {
+					switch {
+					case rate >= triviaTooEasyRate:
+						difficulty = "too_easy"
+					case rate <= triviaTooHardRate:
+						difficulty = "too_hard"
+					default:
+						difficulty = "balanced"
+					}
+				}
+			}
+
+			response = append(response, map[string]interface{}{
+				"question_id":    i,
+				"question":       question["question"],
+				"correct_answer": question["correct_answer"],
+				"answered":       stat.Answered,
+				"correct":        stat.Correct,
+				"correct_rate":   correctRate,
+				"difficulty":     difficulty,
+			})
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"trivia_id":    trivia.ID,
+			"participants": participants,
+			"questions":    response,
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// Admin War Request
+type AdminWarRequest struct {
+	Name        *string           `json:"name" validate:"omitempty,min=1,max=200"`
+	Description *string           `json:"description"`
+	WarType     *string           `json:"war_type" validate:"omitempty,oneof=campus_vs_campus state_vs_state"`
+	StartDate   *time.Time        `json:"start_date"`
+	EndDate     *time.Time        `json:"end_date"`
+	Metrics     *json.RawMessage  `json:"metrics"`
+	Rewards     *store.WarRewards `json:"rewards"`
+}
+
+// applyTo copies every field set on the request onto the war.
+func (req *AdminWarRequest) applyTo(war *store.CampusWar) {
+	if req.Name != nil {
+		war.Name = *req.Name
+	}
+	if req.Description != nil {
+		war.Description = req.Description
+	}
+	if req.WarType != nil {
+		war.WarType = req.WarType
+	}
+	if req.StartDate != nil {
+		war.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		war.EndDate = *req.EndDate
+	}
+	if req.Metrics != nil {
+		war.Metrics = stringPtr(string(*req.Metrics))
+	}
+	if req.Rewards != nil {
+		rewardsJSON, _ := json.Marshal(req.Rewards)
+		war.Rewards = stringPtr(string(rewardsJSON))
+	}
+}
+
+// validateWar checks the parts of a war that the request tags cannot: the
+// window, the metric weights and the reward tiers.
+func validateWar(db *gorm.DB, war *store.CampusWar) error {
+	if !war.EndDate.After(war.StartDate) {
+		return errors.New("end_date must be after start_date")
+	}
+
+	metrics, err := war.ParseMetrics()
+	if err != nil {
+		return fmt.Errorf("invalid metrics: %w", err)
+	}
+	if metrics.XP == 0 && metrics.Submissions == 0 && metrics.Referrals == 0 {
+		return errors.New("at least one metric must count towards the score")
+	}
+
+	rewards, err := war.ParseRewards()
+	if err != nil {
+		return fmt.Errorf("invalid rewards: %w", err)
+	}
+	for i, place := range rewards.Places {
+		if place.XP < 0 || place.Coins < 0 {
+			return fmt.Errorf("rewards for place %d cannot be negative", i+1)
+		}
+		if place.BadgeID != nil {
+			if _, err := store.GetBadgeByID(db, uint(*place.BadgeID)); err != nil {
+				return fmt.Errorf("badge %d for place %d not found", *place.BadgeID, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// getWarFromURL loads the war referenced by the {id} URL parameter, writing
+// the error response itself when it cannot.
+func getWarFromURL(db *gorm.DB, w http.ResponseWriter, r *http.Request) (*store.CampusWar, bool) {
+	warIDStr := chi.URLParam(r, "id")
+	warID, err := strconv.ParseUint(warIDStr, 10, 32)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid war ID"))
+		return nil, false
+	}
+
+	war, err := store.GetCampusWarByID(db, uint(warID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			notFoundResponse(w, r, errors.New("war not found"))
+		} else {
+			internalServerError(w, r, err)
+		}
+		return nil, false
+	}
+	return war, true
+}
+
+// adminWarPayload is the admin view of a war.
+func adminWarPayload(db *gorm.DB, war *store.CampusWar) map[string]interface{} {
+	var participantCount int64
+	db.Model(&store.WarParticipant{}).Where("war_id = ?", war.ID).Count(&participantCount)
+
+	payload := map[string]interface{}{
+		"id":                war.ID,
+		"name":              war.Name,
+		"description":       war.Description,
+		"war_type":          war.WarType,
+		"start_date":        war.StartDate,
+		"end_date":          war.EndDate,
+		"status":            war.Status,
+		"created_by":        war.CreatedBy,
+		"created_at":        war.CreatedAt,
+		"settled_at":        war.SettledAt,
+		"participant_count": participantCount,
+	}
+	if metrics, err := war.ParseMetrics(); err == nil {
+		payload["metrics"] = metrics
+	}
+	if rewards, err := war.ParseRewards(); err == nil {
+		payload["rewards"] = rewards
+	}
+	return payload
+}
+
+// Admin: Create campus war
+func adminCreateWarHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		var req AdminWarRequest
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if req.Name == nil || req.WarType == nil || req.StartDate == nil || req.EndDate == nil {
+			badRequestResponse(w, r, errors.New("name, war_type, start_date and end_date are required"))
+			return
+		}
+
+		adminID := int(admin.ID)
+		war := &store.CampusWar{
+			Status:    "upcoming",
+			CreatedBy: &adminID,
+		}
+		req.applyTo(war)
+
+		if err := validateWar(db, war); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := store.CreateCampusWar(db, war); err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		recordAdminAction(db, r, admin, "war_created", "campus_war", war.ID, map[string]interface{}{
+			"war_type":   war.WarType,
+			"start_date": war.StartDate,
+			"end_date":   war.EndDate,
+		})
+
+		if err := jsonResponse(w, http.StatusCreated, adminWarPayload(db, war)); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Update campus war
+func adminUpdateWarHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		war, ok := getWarFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		// Running wars are only extended or cancelled
+		if war.Status != "upcoming" {
+			conflictResponse(w, r, store.ErrWarNotEditable)
+			return
+		}
+
+		var req AdminWarRequest
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if req.WarType != nil && war.WarType != nil && *req.WarType != *war.WarType {
+			var participantCount int64
+			db.Model(&store.WarParticipant{}).Where("war_id = ?", war.ID).Count(&participantCount)
+			if participantCount > 0 {
+				conflictResponse(w, r, errors.New("war_type cannot change once participants are enrolled"))
+				return
+			}
+		}
+
+		req.applyTo(war)
+
+		if err := validateWar(db, war); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := store.UpdateCampusWar(db, war); err != nil {
+			if errors.Is(err, store.ErrWarNotEditable) {
+				conflictResponse(w, r, err)
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		recordAdminAction(db, r, admin, "war_updated", "campus_war", war.ID, req)
+
+		if err := jsonResponse(w, http.StatusOK, adminWarPayload(db, war)); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Enrol colleges or states in a campus war
+func adminEnrollWarParticipantsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		war, ok := getWarFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		if war.Status != "upcoming" && war.Status != "active" {
+			conflictResponse(w, r, errors.New("participants can only be enrolled in upcoming or active wars"))
+			return
+		}
+
+		entityType := war.EntityType()
+		if entityType == "" {
+			conflictResponse(w, r, errors.New("this war type has no participant entities"))
+			return
+		}
+
+		var req struct {
+			EntityIDs []int                  `json:"entity_ids" validate:"omitempty,max=1000,dive,gt=0"`
+			Filter    *store.WarEntityFilter `json:"filter"`
+		}
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if (len(req.EntityIDs) == 0) == (req.Filter == nil) {
+			badRequestResponse(w, r, errors.New("provide either entity_ids or filter"))
+			return
+		}
+
+		entityIDs := req.EntityIDs
+		if req.Filter != nil {
+			var err error
+			entityIDs, err = store.FindWarEntities(db, entityType, *req.Filter)
+			if err != nil {
+				internalServerError(w, r, err)
+				return
+			}
+		} else {
+			missing, err := store.MissingWarEntities(db, entityType, entityIDs)
+			if err != nil {
+				internalServerError(w, r, err)
+				return
+			}
+			if len(missing) > 0 {
+				badRequestResponse(w, r, fmt.Errorf("%s IDs not found: %v", entityType, missing))
+				return
+			}
+		}
+
+		enrolled, err := store.EnrollWarParticipants(db, war, entityType, entityIDs)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		recordAdminAction(db, r, admin, "war_participants_enrolled", "campus_war", war.ID, map[string]interface{}{
+			"entity_type": entityType,
+			"entity_ids":  req.EntityIDs,
+			"filter":      req.Filter,
+			"enrolled":    enrolled,
+		})
+
+		if err := jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"entity_type":      entityType,
+			"matched":          len(entityIDs),
+			"enrolled":         enrolled,
+			"already_enrolled": int64(len(entityIDs)) - enrolled,
+		}); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Cancel campus war
+func adminCancelWarHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		war, ok := getWarFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Reason string `json:"reason" validate:"max=500"`
+		}
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		from := war.Status
+		if err := store.CancelCampusWar(db, war); err != nil {
+			if errors.Is(err, store.ErrWarNotCancellable) {
+				conflictResponse(w, r, err)
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		recordAdminAction(db, r, admin, "war_cancelled", "campus_war", war.ID, map[string]interface{}{
+			"from":   from,
+			"reason": req.Reason,
+		})
+
+		if err := jsonResponse(w, http.StatusOK, adminWarPayload(db, war)); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Extend campus war
+func adminExtendWarHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		war, ok := getWarFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			EndDate time.Time `json:"end_date" validate:"required"`
+		}
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if !req.EndDate.After(war.EndDate) || !req.EndDate.After(time.Now()) {
+			badRequestResponse(w, r, errors.New("end_date must be later than the current end date and in the future"))
+			return
+		}
+
+		previous := war.EndDate
+		if err := store.ExtendCampusWar(db, war, req.EndDate); err != nil {
+			if errors.Is(err, store.ErrWarNotExtendable) {
+				conflictResponse(w, r, err)
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		recordAdminAction(db, r, admin, "war_extended", "campus_war", war.ID, map[string]interface{}{
+			"from": previous,
+			"to":   war.EndDate,
+		})
+
+		if err := jsonResponse(w, http.StatusOK, adminWarPayload(db, war)); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/env"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// Appeal Request
+type AppealRequest struct {
+	Reason string `json:"reason" validate:"required,min=10,max=2000"`
+}
+
+// appealWindow is how long after a rejection a user may appeal it,
+// configured through APPEAL_WINDOW_HOURS.
+func appealWindow() time.Duration {
+	hours, err := strconv.Atoi(env.Get("APPEAL_WINDOW_HOURS", "72"))
+	if err != nil || hours <= 0 {
+		hours = 72
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// Appeal Submission
+func appealSubmissionHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		submissionIDStr := chi.URLParam(r, "id")
+		submissionID, err := strconv.ParseUint(submissionIDStr, 10, 32)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("invalid submission ID"))
+			return
+		}
+
+		var req AppealRequest
+		if err := readJSON(w, r, &req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		submission, err := store.GetSubmissionByID(db, uint(submissionID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("submission not found"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		// Check if user owns the submission
+		if submission.UserID == nil || uint(*submission.UserID) != user.ID {
+			writeJSONError(w, http.StatusForbidden, "not authorized to appeal this submission")
+			return
+		}
+
+		// A submission can only be appealed once
+		if _, err := store.GetSubmissionAppealBySubmission(db, submission.ID); err == nil {
+			conflictResponse(w, r, errors.New("submission has already been appealed"))
+			return
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			internalServerError(w, r, err)
+			return
+		}
+
+		if submission.Status != "rejected" {
+			badRequestResponse(w, r, errors.New("only rejected submissions can be appealed"))
+			return
+		}
+
+		rejectedAt := submission.UpdatedAt
+		if submission.ReviewedAt != nil {
+			rejectedAt = *submission.ReviewedAt
+		}
+		if time.Now().After(rejectedAt.Add(appealWindow())) {
+			badRequestResponse(w, r, errors.New("appeal window has closed"))
+			return
+		}
+
+		appeal := &store.SubmissionAppeal{
+			SubmissionID: int(submission.ID),
+			UserID:       int(user.ID),
+			Reason:       req.Reason,
+			Status:       "pending",
+		}
+		if err := store.CreateSubmissionAppeal(db, appeal); err != nil {
+			conflictResponse(w, r, err)
+			return
+		}
+
+		response := map[string]interface{}{
+			"appeal":  appeal,
+			"message": "Appeal submitted successfully",
+		}
+
+		if err := jsonResponse(w, http.StatusCreated, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Admin: Get submission appeals
+func adminGetSubmissionAppealsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := r.URL.Query().Get("status")
+		if status == "" {
+			status = "pending"
+		}
+
+		var appeals []store.SubmissionAppeal
+		if err := db.Where("status = ?", status).
+			Preload("Submission").
+			Preload("Submission.Task").
+			Preload("User").
+			Order("created_at ASC").
+			Find(&appeals).Error; err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "failed to fetch appeals")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"appeals": appeals,
+		})
+	}
+}
+
+// Admin: Resolve submission appeal
+func adminResolveSubmissionAppealHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := GetUserFromContext(r)
+		if !ok {
+			writeJSONError(w, http.StatusUnauthorized, "authentication required")
+			return
+		}
+
+		appealIDStr := chi.URLParam(r, "id")
+		appealID, err := strconv.ParseUint(appealIDStr, 10, 32)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid appeal ID")
+			return
+		}
+
+		var req struct {
+			Decision string `json:"decision" validate:"required,oneof=uphold overturn"`
+			Comment  string `json:"comment,omitempty"`
+		}
+		if err := readJSON(w, r, &req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if err := Validate.Struct(req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "decision must be 'uphold' or 'overturn'")
+			return
+		}
+
+		appeal, err := store.GetSubmissionAppealByID(db, uint(appealID))
+		if err != nil {
+			writeJSONError(w, http.StatusNotFound, "appeal not found")
+			return
+		}
+
+		overturn := req.Decision == "overturn"
+		if err := store.ResolveSubmissionAppeal(db, appeal, overturn, int(admin.ID), req.Comment); err != nil {
+			if errors.Is(err, store.ErrAppealAlreadyResolved) {
+				writeJSONError(w, http.StatusConflict, err.Error())
+			} else {
+				writeJSONError(w, http.StatusInternalServerError, "failed to resolve appeal")
+			}
+			return
+		}
+
+		recordAdminAction(db, r, admin, "appeal_"+appeal.Status, "submission_appeal", appeal.ID, map[string]interface{}{
+			"submission_id": appeal.SubmissionID,
+			"comment":       req.Comment,
+		})
+
+		// Notify the user of the decision
+		title := "Appeal Rejected"
+		message := "Your appeal was reviewed and the original decision stands."
+		if overturn {
+			title = "Appeal Accepted"
+			message = "Your appeal was accepted and your submission has been approved."
+		}
+		if req.Comment != "" {
+			message = fmt.Sprintf("%s Reviewer comment: %s", message, req.Comment)
+		}
+		dataJSON, _ := json.Marshal(map[string]interface{}{
+			"appeal_id":     appeal.ID,
+			"submission_id": appeal.SubmissionID,
+			"decision":      appeal.Status,
+		})
+		userID := appeal.UserID
+		notification := &store.Notification{
+			UserID:           &userID,
+			NotificationType: "submission_status",
+			Title:            title,
+			Message:          message,
+			Data:             stringPtr(string(dataJSON)),
+		}
+		store.CreateNotification(db, notification)
+
+		if overturn {
+			if submission, err := store.GetSubmissionByID(db, uint(appeal.SubmissionID)); err == nil {
+				recordActivity(db, uint(appeal.UserID), store.QuestEvent{
+					Type:       store.QuestStepSubmissionApproved,
+					CampaignID: submission.CampaignID,
+					TaskID:     submission.TaskID,
+				})
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"message": "appeal resolved",
+			"appeal":  appeal,
+		})
+	}
+}
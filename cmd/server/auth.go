@@ -1,9 +1,12 @@
 package main
 
 import (
+	cryptorand "crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"math/rand"
 	"net/http"
 	"strings"
@@ -12,11 +15,15 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/rohit21755/gg_server.git/internal/env"
+	"github.com/rohit21755/gg_server.git/internal/services"
 	"github.com/rohit21755/gg_server.git/internal/store"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+// passwordResetTTL is how long a password reset link stays valid
+const passwordResetTTL = time.Hour
+
 // Request/Response structs
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -166,6 +173,7 @@ func loginHandler(db *gorm.DB) http.HandlerFunc {
 			// Log but don't fail login
 			fmt.Printf("Error updating streak: %v\n", err)
 		}
+		checkAchievements(db, user.ID)
 
 		// Response
 		response := map[string]interface{}{
@@ -275,24 +283,21 @@ func registerHandler(db *gorm.DB) http.HandlerFunc {
 			}
 			if err := store.CreateReferral(db, referral); err != nil {
 				fmt.Printf("Error creating referral: %v\n", err)
+			} else {
+				recordActivity(db, *referrerID, store.QuestEvent{Type: store.QuestStepReferral})
 			}
 
 			// Award XP to referrer
-			userID := int(*referrerID)
-			sourceID := int(referral.ID)
-			xpTransaction := &store.XPTransaction{
-				UserID:          &userID,
-				TransactionType: "referral",
-				Amount:          500,
-				SourceType:      stringPtr("referral"),
-				SourceID:        &sourceID,
-				Description:     stringPtr("Referral bonus"),
-			}
-			if err := store.CreateXPTransaction(db, xpTransaction); err != nil {
+			if _, err := store.AwardXP(db, *referrerID, 500, "referral", "referral", referral.ID, "Referral bonus"); err != nil {
 				fmt.Printf("Error awarding referral XP: %v\n", err)
 			}
 		}
 
+		// Send verification email
+		if err := sendVerificationEmail(db, user); err != nil {
+			log.Printf("failed to send verification email to user %d: %s", user.ID, err)
+		}
+
 		// Generate tokens
 		accessToken, refreshToken, err := generateToken(user)
 		if err != nil {
@@ -324,14 +329,15 @@ func registerHandler(db *gorm.DB) http.HandlerFunc {
 			"token_type":    "Bearer",
 			"expires_in":    24 * 3600,
 			"user": map[string]interface{}{
-				"id":            user.ID,
-				"email":         user.Email,
-				"first_name":    user.FirstName,
-				"last_name":     user.LastName,
-				"role":          user.Role,
-				"xp":            user.XP,
-				"referral_code": user.ReferralCode,
-				"college_id":    user.CollegeID,
+				"id":             user.ID,
+				"email":          user.Email,
+				"first_name":     user.FirstName,
+				"last_name":      user.LastName,
+				"role":           user.Role,
+				"xp":             user.XP,
+				"referral_code":  user.ReferralCode,
+				"college_id":     user.CollegeID,
+				"email_verified": false,
 			},
 		}
 
@@ -456,31 +462,55 @@ func forgotPasswordHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
+		// Validate request
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		// Same response whether or not the account exists
+		response := map[string]string{
+			"message": "If an account exists with this email, you will receive a password reset link",
+		}
+
 		// Find user
-		_, err := store.GetUserByEmail(db, req.Email)
+		user, err := store.GetUserByEmail(db, req.Email)
 		if err != nil {
-			// Don't reveal if user exists or not
-			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(map[string]string{
-				"message": "If an account exists with this email, you will receive a password reset link",
-			})
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				internalServerError(w, r, err)
+				return
+			}
+			if err := jsonResponse(w, http.StatusOK, response); err != nil {
+				internalServerError(w, r, err)
+			}
 			return
 		}
 
-		// Generate reset token
-		// resetToken := generateSecureToken(32)
-		// resetExpiry := time.Now().Add(1 * time.Hour)
-
-		// Store reset token (you'd create a password_reset_tokens table)
-		// For now, we'll just return success
+		// Generate reset token, only its hash is stored
+		resetToken, err := generateRandomToken(32)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+		if err := store.CreatePasswordResetToken(db, user.ID, resetToken, passwordResetTTL); err != nil {
+			internalServerError(w, r, err)
+			return
+		}
 
-		// Send email (in production)
-		// sendPasswordResetEmail(user.Email, resetToken)
+		resetLink := env.Get("APP_URL", "http://localhost:3000") + "/reset-password?token=" + resetToken
+		mail := services.Mail{
+			To:      user.Email,
+			Subject: "Reset your password",
+			Body: fmt.Sprintf("Hi %s,\n\nUse the link below to reset your password. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+				user.FirstName, int(passwordResetTTL.Minutes()), resetLink),
+		}
+		if err := services.SendMail(mail); err != nil {
+			log.Printf("failed to send password reset email to user %d: %s", user.ID, err)
+		}
 
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "Password reset email sent",
-		})
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
 	}
 }
 
@@ -493,27 +523,50 @@ func resetPasswordHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Validate token (check in password_reset_tokens table)
-		// For now, we'll skip token validation
-
-		// Get user from token (you'd look up by token)
-		// userID := getUserIdFromResetToken(req.Token)
+		// Validate request
+		if err := Validate.Struct(req); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
 
 		// Hash new password
-		// hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-		// if err != nil {
-		// 	internalServerError(w, r, err)
-		// 	return
-		// }
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
 
-		// Update password
-		// user.PasswordHash = string(hashedPassword)
-		// store.UpdateUser(db, user)
+		// Consume the token, update the password and revoke sessions together
+		err = db.Transaction(func(tx *gorm.DB) error {
+			resetToken, err := store.ConsumePasswordResetToken(tx, req.Token)
+			if err != nil {
+				return err
+			}
 
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "Password reset successful",
+			if err := tx.Model(&store.User{}).
+				Where("id = ?", resetToken.UserID).
+				Update("password_hash", string(hashedPassword)).Error; err != nil {
+				return err
+			}
+
+			return store.DeleteSessionsByUser(tx, uint(resetToken.UserID))
 		})
+		if err != nil {
+			if errors.Is(err, store.ErrInvalidResetToken) {
+				badRequestResponse(w, r, err)
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		response := map[string]string{
+			"message": "Password reset successful",
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
 	}
 }
 
@@ -526,13 +579,23 @@ func verifyEmailHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Validate email verification token
-		// Update user's email_verified status
+		// Validate email verification token and mark the user verified
+		if _, err := store.VerifyUserEmail(db, token); err != nil {
+			if errors.Is(err, store.ErrInvalidVerificationToken) {
+				badRequestResponse(w, r, err)
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
 
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{
+		response := map[string]string{
 			"message": "Email verified successfully",
-		})
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
 	}
 }
 
@@ -556,6 +619,15 @@ func generateSecureToken(length int) string {
 	return string(b)
 }
 
+// generateRandomToken returns a hex encoded token built from n random bytes.
+func generateRandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 func extractToken(authHeader string) string {
 	parts := strings.Split(authHeader, " ")
 	if len(parts) == 2 && parts[0] == "Bearer" {
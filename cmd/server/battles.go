@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// settleContentBattle ranks a battle whose voting has closed, pays the
+// configured place rewards and posts the results to the social feed. Only
+// submissions that received votes are paid.
+func settleContentBattle(tx *gorm.DB, battle *store.ContentBattle) error {
+	rewards, err := battle.ParseRewards()
+	if err != nil {
+		return fmt.Errorf("invalid rewards for battle %d: %w", battle.ID, err)
+	}
+
+	submissions, err := store.SettleContentBattle(tx, battle)
+	if err != nil {
+		return err
+	}
+
+	var placed []store.BattleSubmission
+	for _, submission := range submissions {
+		if submission.VoteCount == 0 {
+			break
+		}
+		if submission.UserID == nil {
+			// Author deleted their account; the places below still pay
+			continue
+		}
+		placed = append(placed, submission)
+
+		rank := *submission.Rank
+		if rank > len(rewards.Places) {
+			continue
+		}
+		if err := payBattleReward(tx, battle, &submission, rewards.Places[rank-1]); err != nil {
+			return err
+		}
+	}
+
+	// The top remaining author counts as the winner if the winner's account
+	// was deleted
+	if len(placed) == 0 {
+		return nil
+	}
+	winnerID := uint(*placed[0].UserID)
+	store.AfterCommit(tx, func(db *gorm.DB) {
+		recordActivity(db, winnerID, store.QuestEvent{Type: store.QuestStepBattleWon})
+	})
+	return postBattleResults(tx, battle, placed)
+}
+
+// payBattleReward credits one placed submission's author and tells them.
+func payBattleReward(tx *gorm.DB, battle *store.ContentBattle, submission *store.BattleSubmission, reward store.Reward) error {
+	user, err := store.GetUserByID(tx, uint(*submission.UserID))
+	if err != nil {
+		return err
+	}
+	rank := *submission.Rank
+	description := fmt.Sprintf("Placed #%d in content battle \"%s\"", rank, battle.Title)
+
+	if reward.XP > 0 {
+		if _, err := store.AwardXP(tx, user.ID, reward.XP, "battle_win", "content_battle", battle.ID, description); err != nil {
+			return err
+		}
+	}
+	if reward.Coins > 0 {
+		if _, err := store.CreditCoins(tx, user.ID, reward.Coins, description, "content_battle", battle.ID); err != nil {
+			return err
+		}
+	}
+	if reward.BadgeID != nil {
+		if err := awardRewardBadge(tx, user, *reward.BadgeID); err != nil {
+			return err
+		}
+	}
+
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"battle_id":     battle.ID,
+		"submission_id": submission.ID,
+		"rank":          rank,
+		"votes":         submission.VoteCount,
+		"xp":            reward.XP,
+		"coins":         reward.Coins,
+		"badge_id":      reward.BadgeID,
+	})
+	title := fmt.Sprintf("You placed #%d!", rank)
+	if submission.IsWinner {
+		title = "You won the battle!"
+	}
+	notification := &store.Notification{
+		UserID:           submission.UserID,
+		NotificationType: "winner_announcement",
+		Title:            title,
+		Message:          fmt.Sprintf("Your entry in \"%s\" finished #%d with %d votes.", battle.Title, rank, submission.VoteCount),
+		ActionURL:        stringPtr(fmt.Sprintf("/battles/%d", battle.ID)),
+		Data:             stringPtr(string(dataJSON)),
+	}
+	return store.CreateNotification(tx, notification)
+}
+
+// awardRewardBadge gives a reward badge unless the user already holds it. A
+// badge that no longer exists is skipped so it cannot block settlement.
+func awardRewardBadge(tx *gorm.DB, user *store.User, badgeID int) error {
+	if _, err := store.GetBadgeByID(tx, uint(badgeID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("rewards: reward badge %d not found, skipping", badgeID)
+			return nil
+		}
+		return err
+	}
+	held, err := store.HasUserBadge(tx, user.ID, badgeID)
+	if err != nil || held {
+		return err
+	}
+	_, err = awardBadge(tx, user, badgeID)
+	return err
+}
+
+// postBattleResults publishes the final standings as an achievement post by
+// the top placed author, showing their entry.
+func postBattleResults(tx *gorm.DB, battle *store.ContentBattle, placed []store.BattleSubmission) error {
+	var lines []string
+	for _, submission := range placed {
+		if len(lines) == 3 {
+			break
+		}
+		name := "Someone"
+		if user, err := store.GetUserByID(tx, uint(*submission.UserID)); err == nil {
+			name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		}
+		lines = append(lines, fmt.Sprintf("#%d %s (%d votes)", *submission.Rank, name, submission.VoteCount))
+	}
+
+	winner := placed[0]
+	mediaJSON, _ := json.Marshal([]string{winner.MediaURL})
+	post := &store.SocialPost{
+		UserID:    uint(*winner.UserID),
+		Content:   fmt.Sprintf("Results are in for the \"%s\" content battle!\n%s", battle.Title, strings.Join(lines, "\n")),
+		MediaURLs: stringPtr(string(mediaJSON)),
+		PostType:  "achievement",
+		IsPublic:  true,
+	}
+	return store.CreateSocialPost(tx, post)
+}
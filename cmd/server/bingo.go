@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// bingoCardPayload renders a bingo card and, when the user has one, their
+// progress on it: marked cells and the state of every line.
+func bingoCardPayload(bingo *store.BadgeBingo, progress *store.UserBingoProgress) map[string]interface{} {
+	payload := map[string]interface{}{
+		"id":          bingo.ID,
+		"title":       bingo.Title,
+		"description": bingo.Description,
+		"start_date":  bingo.StartDate,
+		"end_date":    bingo.EndDate,
+		"is_active":   bingo.IsActive,
+	}
+	if rewards, err := bingo.ParseRewards(); err == nil {
+		payload["rewards"] = rewards
+	}
+
+	card, err := bingo.ParseCard()
+	if err != nil {
+		return payload
+	}
+
+	marked := make(map[int]bool)
+	claimed := make(map[string]bool)
+	if progress != nil {
+		for _, index := range progress.MarkedCells() {
+			marked[index] = true
+		}
+		for _, key := range progress.ClaimedLines() {
+			claimed[key] = true
+		}
+	}
+
+	cells := make([]map[string]interface{}, len(card.Cells))
+	for index, cell := range card.Cells {
+		cells[index] = map[string]interface{}{
+			"index":       index,
+			"row":         index / card.Size,
+			"column":      index % card.Size,
+			"label":       cell.Label,
+			"badge_id":    cell.BadgeID,
+			"activity":    cell.Activity,
+			"campaign_id": cell.CampaignID,
+			"task_id":     cell.TaskID,
+			"free":        cell.Free,
+			"marked":      cell.Free || marked[index],
+		}
+	}
+
+	var lines []map[string]interface{}
+	for _, line := range card.Lines() {
+		lines = append(lines, map[string]interface{}{
+			"kind":      line.Kind,
+			"index":     line.Index,
+			"cells":     card.LineCells(line),
+			"completed": claimed[line.Key()],
+		})
+	}
+
+	payload["size"] = card.Size
+	payload["cells"] = cells
+	payload["lines"] = lines
+	if progress != nil {
+		payload["completed_rows"] = progress.CompletedRows
+		payload["completed_columns"] = progress.CompletedColumns
+		payload["completed_diagonals"] = progress.CompletedDiagonals
+		payload["updated_at"] = progress.UpdatedAt
+	}
+	return payload
+}
+
+// getUserBingoProgress returns the user's progress on a bingo, or nil if
+// they have not marked a cell yet.
+func getUserBingoProgress(db *gorm.DB, userID, bingoID uint) (*store.UserBingoProgress, error) {
+	progress, err := store.GetUserBingoProgress(db, userID, bingoID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return progress, err
+}
+
+// Get Active Bingo Cards
+func getActiveBingosHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		bingos, err := store.GetActiveBadgeBingos(db, time.Now())
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		response := make([]map[string]interface{}, 0, len(bingos))
+		for i := range bingos {
+			progress, err := getUserBingoProgress(db, user.ID, bingos[i].ID)
+			if err != nil {
+				internalServerError(w, r, err)
+				return
+			}
+			response = append(response, bingoCardPayload(&bingos[i], progress))
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Get Bingo Card
+func getBingoHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		bingoIDStr := chi.URLParam(r, "id")
+		bingoID, err := strconv.ParseUint(bingoIDStr, 10, 32)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("invalid bingo ID"))
+			return
+		}
+
+		bingo, err := store.GetBadgeBingoByID(db, uint(bingoID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("bingo not found"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		progress, err := getUserBingoProgress(db, user.ID, bingo.ID)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		if err := jsonResponse(w, http.StatusOK, bingoCardPayload(bingo, progress)); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// recordBingoEvent marks the cells an event matches on the user's bingo cards
+// and pays the bundle for every line it completes. Like recordActivity, it
+// logs failures rather than undoing the event.
+func recordBingoEvent(db *gorm.DB, userID uint, event store.BingoEvent) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		claims, err := store.MarkBingoCells(tx, userID, event, time.Now())
+		if err != nil {
+			return err
+		}
+		for i := range claims {
+			if err := payBingoReward(tx, userID, &claims[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("bingo: failed to mark cards for user %d: %v", userID, err)
+	}
+}
+
+// payBingoReward credits the bundle for a completed line and tells the user.
+func payBingoReward(tx *gorm.DB, userID uint, claim *store.BingoClaim) error {
+	bingo := claim.Bingo
+	rewards, err := bingo.ParseRewards()
+	if err != nil {
+		return fmt.Errorf("invalid rewards for bingo %d: %w", bingo.ID, err)
+	}
+
+	user, err := store.GetUserByID(tx, userID)
+	if err != nil {
+		return err
+	}
+	description := fmt.Sprintf("Completed a %s in \"%s\" bingo", claim.Line.Kind, bingo.Title)
+
+	reward := rewards.For(claim.Line.Kind)
+	if reward != nil {
+		if reward.XP > 0 {
+			if _, err := store.AwardXP(tx, user.ID, reward.XP, "bonus", "badge_bingo", bingo.ID, description); err != nil {
+				return err
+			}
+		}
+		if reward.Coins > 0 {
+			if _, err := store.CreditCoins(tx, user.ID, reward.Coins, description, "badge_bingo", bingo.ID); err != nil {
+				return err
+			}
+		}
+		if reward.BadgeID != nil {
+			if err := awardRewardBadge(tx, user, *reward.BadgeID); err != nil {
+				return err
+			}
+		}
+	}
+
+	data := map[string]interface{}{
+		"bingo_id": bingo.ID,
+		"line":     claim.Line,
+	}
+	if reward != nil {
+		data["xp"] = reward.XP
+		data["coins"] = reward.Coins
+		data["badge_id"] = reward.BadgeID
+	}
+	dataJSON, _ := json.Marshal(data)
+	userIDInt := int(userID)
+	notification := &store.Notification{
+		UserID:           &userIDInt,
+		NotificationType: "reward_unlocked",
+		Title:            "Bingo!",
+		Message:          fmt.Sprintf("You completed a %s on \"%s\".", claim.Line.Kind, bingo.Title),
+		ActionURL:        stringPtr(fmt.Sprintf("/bingo/%d", bingo.ID)),
+		Data:             stringPtr(string(dataJSON)),
+	}
+	return store.CreateNotification(tx, notification)
+}
@@ -144,6 +144,10 @@ func joinCampaignHandler(db *gorm.DB) http.HandlerFunc {
 		}
 
 		// Check if campaign is active
+		if campaign.Status == "paused" {
+			badRequestResponse(w, r, errors.New("campaign is paused"))
+			return
+		}
 		if campaign.Status != "active" {
 			badRequestResponse(w, r, errors.New("campaign is not active"))
 			return
@@ -245,42 +249,56 @@ func getCampaignLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Get top performers in campaign
-		var leaderboard []struct {
-			UserID      uint   `json:"user_id"`
-			FirstName   string `json:"first_name"`
-			LastName    string `json:"last_name"`
-			College     string `json:"college"`
-			TotalXP     int    `json:"total_xp"`
-			Submissions int    `json:"submissions"`
-			Rank        int    `json:"rank"`
-		}
-
-		result := db.Model(&store.Submission{}).
-			Select(`
-				users.id as user_id,
-				users.first_name,
-				users.last_name,
-				colleges.name as college,
-				SUM(submissions.xp_awarded) as total_xp,
-				COUNT(submissions.id) as submissions
-			`).
-			Joins("JOIN users ON users.id = submissions.user_id").
-			Joins("LEFT JOIN colleges ON colleges.id = users.college_id").
-			Where("submissions.campaign_id = ? AND submissions.status = 'approved'", campaignID).
-			Group("users.id, colleges.name").
-			Order("total_xp DESC").
-			Limit(20).
-			Scan(&leaderboard)
+		asOf, err := parseAsOf(r)
+		if err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
 
-		if result.Error != nil {
-			internalServerError(w, r, result.Error)
+		campaign, err := store.GetCampaignByID(db, uint(campaignID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("campaign not found"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		// Served from snapshots; completed campaigns end on the one frozen at
+		// completion
+		leaderboard := []map[string]interface{}{}
+		snapshot, err := store.GetCampaignLeaderboard(db, campaign.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			internalServerError(w, r, err)
 			return
 		}
+		if err == nil {
+			entries, _, err := store.GetLeaderboardSnapshot(db, snapshot.ID, asOf, 20)
+			if err != nil {
+				internalServerError(w, r, err)
+				return
+			}
 
-		// Add ranks
-		for i := range leaderboard {
-			leaderboard[i].Rank = i + 1
+			for _, entry := range entries {
+				row := map[string]interface{}{
+					"user_id":       entry.UserID,
+					"total_xp":      entry.XP,
+					"submissions":   entry.SubmissionsCount,
+					"rank":          entry.Rank,
+					"previous_rank": entry.PreviousRank,
+					"trend":         entry.Trend,
+				}
+				if entry.User != nil {
+					row["first_name"] = entry.User.FirstName
+					row["last_name"] = entry.User.LastName
+					row["profile_skin"] = entry.User.ProfileSkin
+				}
+				if entry.College != nil {
+					row["college"] = entry.College.Name
+				}
+				leaderboard = append(leaderboard, row)
+			}
 		}
 
 		if err := jsonResponse(w, http.StatusOK, leaderboard); err != nil {
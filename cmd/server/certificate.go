@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/services"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// renderCertificate renders the certificate PDF with its template, falling
+// back to the built-in layout when the template is missing.
+func renderCertificate(db *gorm.DB, certificate *store.Certificate) ([]byte, error) {
+	var template *store.CertificateTemplate
+	if certificate.TemplateID != nil {
+		t, err := store.GetCertificateTemplateByID(db, uint(*certificate.TemplateID))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		template = t
+	}
+	return services.RenderCertificate(certificate, template)
+}
+
+// notifyCertificateIssued tells the user a new certificate is ready to download.
+func notifyCertificateIssued(db *gorm.DB, certificate *store.Certificate) {
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"certificate_id":    certificate.ID,
+		"verification_code": certificate.VerificationCode,
+	})
+	notification := &store.Notification{
+		UserID:           certificate.UserID,
+		NotificationType: "reward_unlocked",
+		Title:            "New Certificate",
+		Message:          "You have been awarded the certificate \"" + certificate.Title + "\".",
+		Data:             stringPtr(string(dataJSON)),
+	}
+	if err := store.CreateNotification(db, notification); err != nil {
+		log.Printf("certificates: failed to notify user about certificate %d: %v", certificate.ID, err)
+	}
+}
+
+// awardBadge gives a badge to a user, marks it on their bingo cards and issues
+// the badge's certificate, if it carries one.
+func awardBadge(db *gorm.DB, user *store.User, badgeID int) (*store.UserBadge, error) {
+	userBadge := &store.UserBadge{
+		UserID:  int(user.ID),
+		BadgeID: badgeID,
+	}
+	if err := store.CreateUserBadge(db, userBadge); err != nil {
+		return nil, err
+	}
+	recordBingoEvent(db, user.ID, store.BingoEvent{BadgeID: &badgeID})
+
+	badge, err := store.GetBadgeByID(db, uint(badgeID))
+	if err != nil {
+		log.Printf("certificates: failed to load badge %d: %v", badgeID, err)
+		return userBadge, nil
+	}
+	certificate, err := store.IssueBadgeCertificate(db, user, badge)
+	if err != nil {
+		log.Printf("certificates: failed to issue certificate for badge %d to user %d: %v", badgeID, user.ID, err)
+		return userBadge, nil
+	}
+	if certificate != nil {
+		notifyCertificateIssued(db, certificate)
+	}
+	return userBadge, nil
+}
+
+// Verify Certificate
+func verifyCertificateHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		code := chi.URLParam(r, "code")
+
+		certificate, err := store.GetCertificateByVerificationCode(db, code)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("no certificate matches this verification code"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		details := certificate.Details()
+		response := map[string]interface{}{
+			"valid":             certificate.RevokedAt == nil,
+			"verification_code": certificate.VerificationCode,
+			"title":             certificate.Title,
+			"certificate_type":  certificate.CertificateType,
+			"recipient_name":    details.RecipientName,
+			"issuing_authority": certificate.IssuingAuthority,
+			"issue_date":        certificate.IssueDate.Format("2006-01-02"),
+		}
+		if details.CampaignTitle != "" {
+			response["campaign"] = details.CampaignTitle
+		}
+		if details.BadgeName != "" {
+			response["badge"] = details.BadgeName
+		}
+		if details.Rank > 0 {
+			response["rank"] = details.Rank
+		}
+		if certificate.RevokedAt != nil {
+			response["revoked_at"] = certificate.RevokedAt
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
@@ -67,18 +67,20 @@ func getCollegeLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 			}
 		}
 
-		var users []store.User
-		if err := db.Where("college_id = ? AND is_active = ?", collegeID, true).
-			Order("xp DESC").
-			Limit(limit).
-			Find(&users).Error; err != nil {
+		asOf, err := parseAsOf(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		board := store.BoardCollege
+		payload, err := leaderboardSnapshotPayload(db, board, store.LeaderboardName(board, uint(collegeID)), asOf, limit)
+		if err != nil {
 			writeJSONError(w, http.StatusInternalServerError, "failed to fetch leaderboard")
 			return
 		}
 
-		writeJSON(w, http.StatusOK, map[string]interface{}{
-			"leaderboard": users,
-		})
+		writeJSON(w, http.StatusOK, payload)
 	}
 }
 
@@ -134,18 +136,20 @@ func getStateLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 			}
 		}
 
-		var users []store.User
-		if err := db.Where("state_id = ? AND is_active = ?", stateID, true).
-			Order("xp DESC").
-			Limit(limit).
-			Find(&users).Error; err != nil {
+		asOf, err := parseAsOf(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		board := store.BoardState
+		payload, err := leaderboardSnapshotPayload(db, board, store.LeaderboardName(board, uint(stateID)), asOf, limit)
+		if err != nil {
 			writeJSONError(w, http.StatusInternalServerError, "failed to fetch leaderboard")
 			return
 		}
 
-		writeJSON(w, http.StatusOK, map[string]interface{}{
-			"leaderboard": users,
-		})
+		writeJSON(w, http.StatusOK, payload)
 	}
 }
 
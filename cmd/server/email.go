@@ -1,12 +1,19 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/rohit21755/gg_server.git/internal/env"
+	"github.com/rohit21755/gg_server.git/internal/services"
 	"github.com/rohit21755/gg_server.git/internal/store"
 	"gorm.io/gorm"
 )
 
+// emailVerificationTTL is how long an email verification link stays valid
+const emailVerificationTTL = 48 * time.Hour
+
 // Get email preferences
 func getEmailPreferencesHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -78,17 +85,44 @@ func updateEmailPreferencesHandler(db *gorm.DB) http.HandlerFunc {
 // Resend verification email
 func resendVerificationEmailHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, ok := GetUserFromContext(r)
+		user, ok := GetUserFromContext(r)
 		if !ok {
 			writeJSONError(w, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
-		// TODO: Implement email sending logic
-		// For now, just return success
+		if user.EmailVerifiedAt != nil {
+			writeJSONError(w, http.StatusConflict, "email is already verified")
+			return
+		}
+
+		if err := sendVerificationEmail(db, user); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "failed to send verification email")
+			return
+		}
+
 		writeJSON(w, http.StatusOK, map[string]string{
 			"message": "verification email sent",
 		})
 	}
 }
 
+// sendVerificationEmail issues a fresh verification token for the user and
+// mails them the verification link.
+func sendVerificationEmail(db *gorm.DB, user *store.User) error {
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	if err := store.CreateEmailVerificationToken(db, user.ID, token, emailVerificationTTL); err != nil {
+		return err
+	}
+
+	verifyLink := env.Get("APP_URL", "http://localhost:3000") + "/verify-email/" + token
+	return services.SendMail(services.Mail{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %d hours.\n\n%s\n",
+			user.FirstName, int(emailVerificationTTL.Hours()), verifyLink),
+	})
+}
@@ -124,8 +124,9 @@ func participateFlashChallengeHandler(db *gorm.DB) http.HandlerFunc {
 func getActiveTriviaHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var trivia []store.TriviaTournament
-		result := db.Where("status = 'active' AND start_date <= ? AND end_date >= ?",
-			time.Now(), time.Now()).
+		// Live tournaments are listed before they start so players can join
+		result := db.Where("(status = 'active' AND start_date <= ? AND end_date >= ?) OR (mode = ? AND status = 'upcoming' AND end_date >= ?)",
+			time.Now(), time.Now(), store.TriviaModeLive, time.Now()).
 			Order("end_date ASC").
 			Find(&trivia)
 
@@ -151,6 +152,8 @@ func getActiveTriviaHandler(db *gorm.DB) http.HandlerFunc {
 				"question_count": len(questions),
 				"entry_fee":      t.EntryFeeXP,
 				"time_remaining": int(t.EndDate.Sub(time.Now()).Seconds()),
+				"status":         t.Status,
+				"mode":           t.Mode,
 			})
 		}
 
@@ -183,70 +186,78 @@ func startTriviaHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
+		if trivia.Mode == store.TriviaModeLive {
+			badRequestResponse(w, r, errors.New("live trivia is played over the WebSocket"))
+			return
+		}
+
 		// Check if trivia is active
 		if trivia.Status != "active" || time.Now().Before(trivia.StartDate) || time.Now().After(trivia.EndDate) {
 			badRequestResponse(w, r, errors.New("trivia is not active"))
 			return
 		}
 
-		// Check if user has already participated
-		var participant store.TriviaParticipant
-		db.Where("trivia_id = ? AND user_id = ?", trivia.ID, user.ID).First(&participant)
-		if participant.ID > 0 {
-			conflictResponse(w, r, errors.New("you have already participated in this trivia"))
+		questions, err := trivia.ParseQuestions()
+		if err != nil {
+			internalServerError(w, r, err)
 			return
 		}
 
-		// Check entry fee
-		if trivia.EntryFeeXP > 0 && user.XP < trivia.EntryFeeXP {
-			badRequestResponse(w, r, errors.New("insufficient XP for entry fee"))
+		// An unfinished attempt is resumed with the same questions and deadline
+		participant, err := store.GetTriviaParticipant(db, trivia.ID, user.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			internalServerError(w, r, err)
 			return
 		}
-
-		// Deduct entry fee
-		if trivia.EntryFeeXP > 0 {
-			userIDInt := int(user.ID)
-			triviaIDInt := int(trivia.ID)
-			xpTransaction := &store.XPTransaction{
-				UserID:          &userIDInt,
-				TransactionType: "quiz",
-				Amount:          -trivia.EntryFeeXP,
-				SourceType:      stringPtr("trivia"),
-				SourceID:        &triviaIDInt,
-				Description:     stringPtr("Trivia entry fee"),
+		if err == nil {
+			if participant.SubmittedAt != nil || participant.StartedAt == nil {
+				conflictResponse(w, r, errors.New("you have already participated in this trivia"))
+				return
+			}
+			if time.Now().After(*participant.DeadlineAt) {
+				conflictResponse(w, r, errors.New("your trivia attempt has expired"))
+				return
+			}
+		} else {
+			// Check entry fee
+			if trivia.EntryFeeXP > 0 && user.XP < trivia.EntryFeeXP {
+				badRequestResponse(w, r, errors.New("insufficient XP for entry fee"))
+				return
 			}
-			store.CreateXPTransaction(db, xpTransaction)
-
-			user.XP -= trivia.EntryFeeXP
-			store.UpdateUser(db, user)
-		}
 
-		// Create participant record
-		triviaIDInt := int(trivia.ID)
-		userIDInt := int(user.ID)
-		participant = store.TriviaParticipant{
-			TriviaID:       &triviaIDInt,
-			UserID:         &userIDInt,
-			ParticipatedAt: time.Now(),
+			err = db.Transaction(func(tx *gorm.DB) error {
+				participant, err = store.StartTriviaAttempt(tx, &trivia, user.ID, store.ShuffleTriviaQuestions(questions))
+				if err != nil {
+					return err
+				}
+				if trivia.EntryFeeXP > 0 {
+					_, err = store.AwardXP(tx, user.ID, -trivia.EntryFeeXP, "quiz", "trivia", trivia.ID, "Trivia entry fee")
+				}
+				return err
+			})
+			if err != nil {
+				if errors.Is(err, store.ErrTriviaAlreadyStarted) {
+					conflictResponse(w, r, err)
+				} else {
+					internalServerError(w, r, err)
+				}
+				return
+			}
 		}
-		store.CreateTriviaParticipant(db, &participant)
-
-		// Return questions (without answers)
-		var questions []map[string]interface{}
-		json.Unmarshal([]byte(trivia.Questions), &questions)
 
-		// Remove correct answers
-		for i := range questions {
-			delete(questions[i], "correct_answer")
+		order, err := participant.Order()
+		if err != nil {
+			internalServerError(w, r, err)
+			return
 		}
 
 		response := map[string]interface{}{
 			"trivia_id":      trivia.ID,
 			"title":          trivia.Title,
 			"duration":       trivia.DurationMinutes,
-			"questions":      questions,
-			"start_time":     time.Now(),
-			"end_time":       time.Now().Add(time.Duration(trivia.DurationMinutes) * time.Minute),
+			"questions":      store.ArrangeTriviaQuestions(questions, order),
+			"start_time":     participant.StartedAt,
+			"end_time":       participant.DeadlineAt,
 			"participant_id": participant.ID,
 		}
 
@@ -256,6 +267,10 @@ func startTriviaHandler(db *gorm.DB) http.HandlerFunc {
 	}
 }
 
+// triviaSubmitGrace allows for network latency when answers arrive just after
+// the attempt deadline.
+const triviaSubmitGrace = 5 * time.Second
+
 // Submit Trivia Answers
 func submitTriviaAnswersHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -277,13 +292,16 @@ func submitTriviaAnswersHandler(db *gorm.DB) http.HandlerFunc {
 				QuestionID int    `json:"question_id"`
 				Answer     string `json:"answer"`
 			} `json:"answers" validate:"required"`
-			TimeTaken int `json:"time_taken" validate:"required"`
+			// TimeTaken is accepted for older clients but ignored; the time
+			// taken is measured by the server
+			TimeTaken int `json:"time_taken"`
 		}
 
 		if err := readJSON(w, r, &req); err != nil {
 			badRequestResponse(w, r, err)
 			return
 		}
+		submittedAt := time.Now()
 
 		// Get trivia
 		var trivia store.TriviaTournament
@@ -293,74 +311,95 @@ func submitTriviaAnswersHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Check if trivia is still active
-		if time.Now().After(trivia.EndDate) {
-			badRequestResponse(w, r, errors.New("trivia has ended"))
+		if trivia.Mode == store.TriviaModeLive {
+			badRequestResponse(w, r, errors.New("live trivia is played over the WebSocket"))
 			return
 		}
 
 		// Get participant
-		var participant store.TriviaParticipant
-		db.Where("trivia_id = ? AND user_id = ?", trivia.ID, user.ID).First(&participant)
-		if participant.ID == 0 {
-			notFoundResponse(w, r, errors.New("participant not found"))
+		participant, err := store.GetTriviaParticipant(db, trivia.ID, user.ID)
+		if err != nil || participant.StartedAt == nil {
+			notFoundResponse(w, r, errors.New("start the trivia before submitting answers"))
 			return
 		}
 
 		// Check if already submitted
-		if participant.Score > 0 {
+		if participant.SubmittedAt != nil {
 			conflictResponse(w, r, errors.New("answers already submitted"))
 			return
 		}
 
-		// Calculate score
-		var questions []map[string]interface{}
-		json.Unmarshal([]byte(trivia.Questions), &questions)
+		if submittedAt.After(participant.DeadlineAt.Add(triviaSubmitGrace)) {
+			badRequestResponse(w, r, errors.New("trivia attempt deadline has passed"))
+			return
+		}
 
+		questions, err := trivia.ParseQuestions()
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		// Calculate score. Only the first answer to each question counts.
 		score := 0
 		correctAnswers := 0
+		answers := make([]store.TriviaAnswer, 0, len(req.Answers))
+		seen := make(map[int]bool, len(req.Answers))
 
 		for _, answer := range req.Answers {
-			if answer.QuestionID < len(questions) {
-				question := questions[answer.QuestionID]
-				if correctAnswer, ok := question["correct_answer"].(string); ok && correctAnswer == answer.Answer {
-					score += 10 // 10 points per correct answer
-					correctAnswers++
-				}
+			if answer.QuestionID < 0 || answer.QuestionID >= len(questions) || seen[answer.QuestionID] {
+				continue
+			}
+			seen[answer.QuestionID] = true
+
+			correctAnswer, ok := questions[answer.QuestionID]["correct_answer"].(string)
+			isCorrect := ok && correctAnswer == answer.Answer
+			if isCorrect {
+				score += 10 // 10 points per correct answer
+				correctAnswers++
 			}
+			answers = append(answers, store.TriviaAnswer{
+				ParticipantID: participant.ID,
+				TriviaID:      trivia.ID,
+				QuestionIndex: answer.QuestionID,
+				Answer:        answer.Answer,
+				IsCorrect:     isCorrect,
+				AnsweredAt:    submittedAt,
+			})
 		}
 
-		// Update participant
+		timeTaken := int(submittedAt.Sub(*participant.StartedAt).Seconds())
 		participant.Score = score
 		participant.CorrectAnswers = correctAnswers
-		participant.TimeTakenSeconds = intPtr(req.TimeTaken)
-		db.Save(&participant)
-
-		// Award XP based on score
-		if score > 0 {
-			xpEarned := score * 5 // 5 XP per point
-			userIDInt := int(user.ID)
-			triviaIDInt := int(trivia.ID)
-			xpTransaction := &store.XPTransaction{
-				UserID:          &userIDInt,
-				TransactionType: "quiz",
-				Amount:          xpEarned,
-				SourceType:      stringPtr("trivia"),
-				SourceID:        &triviaIDInt,
-				Description:     stringPtr("Trivia competition reward"),
+		participant.TimeTakenSeconds = intPtr(timeTaken)
+
+		xpEarned := score * 5 // 5 XP per point
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := store.CompleteTriviaAttempt(tx, participant, answers, submittedAt); err != nil {
+				return err
 			}
-			store.CreateXPTransaction(db, xpTransaction)
 
-			user.XP += xpEarned
-			store.UpdateUser(db, user)
+			// Award XP based on score
+			if xpEarned > 0 {
+				_, err = store.AwardXP(tx, user.ID, xpEarned, "quiz", "trivia", trivia.ID, "Trivia competition reward")
+			}
+			return err
+		})
+		if errors.Is(err, store.ErrTriviaAlreadySubmitted) {
+			conflictResponse(w, r, errors.New("answers already submitted"))
+			return
+		}
+		if err != nil {
+			internalServerError(w, r, err)
+			return
 		}
 
 		response := map[string]interface{}{
 			"score":           score,
 			"correct_answers": correctAnswers,
 			"total_questions": len(questions),
-			"xp_earned":       score * 5,
-			"time_taken":      req.TimeTaken,
+			"xp_earned":       xpEarned,
+			"time_taken":      timeTaken,
 		}
 
 		if err := jsonResponse(w, http.StatusOK, response); err != nil {
@@ -442,18 +481,12 @@ func openMysteryBoxHandler(db *gorm.DB) http.HandlerFunc {
 		// Deduct XP
 		userIDInt := int(user.ID)
 		boxIDInt := int(box.ID)
-		xpTransaction := &store.XPTransaction{
-			UserID:          &userIDInt,
-			TransactionType: "mystery_box",
-			Amount:          -box.CostXP,
-			SourceType:      stringPtr("mystery_box"),
-			SourceID:        &boxIDInt,
-			Description:     stringPtr("Mystery box purchase"),
+		xpTransaction, err := store.AwardXP(db, user.ID, -box.CostXP, "mystery_box", "mystery_box", box.ID, "Mystery box purchase")
+		if err != nil {
+			internalServerError(w, r, err)
+			return
 		}
-		store.CreateXPTransaction(db, xpTransaction)
-
-		user.XP -= box.CostXP
-		store.UpdateUser(db, user)
+		user.XP = xpTransaction.BalanceAfter
 
 		// Record redemption
 		redemption := &store.MysteryBoxRedemption{
@@ -471,28 +504,15 @@ func openMysteryBoxHandler(db *gorm.DB) http.HandlerFunc {
 
 		switch rewardType {
 		case "xp":
-			userIDInt := int(user.ID)
-			redemptionIDInt := int(redemption.ID)
-			xpTransaction := &store.XPTransaction{
-				UserID:          &userIDInt,
-				TransactionType: "mystery_box",
-				Amount:          rewardValue,
-				SourceType:      stringPtr("mystery_box"),
-				SourceID:        &redemptionIDInt,
-				Description:     stringPtr("Mystery box reward"),
+			xpTransaction, err := store.AwardXP(db, user.ID, rewardValue, "mystery_box", "mystery_box", redemption.ID, "Mystery box reward")
+			if err != nil {
+				internalServerError(w, r, err)
+				return
 			}
-			store.CreateXPTransaction(db, xpTransaction)
-
-			user.XP += rewardValue
-			store.UpdateUser(db, user)
+			user.XP = xpTransaction.BalanceAfter
 
 		case "badge":
-			userIDInt := int(user.ID)
-			userBadge := &store.UserBadge{
-				UserID:  userIDInt,
-				BadgeID: rewardValue,
-			}
-			store.CreateUserBadge(db, userBadge)
+			awardBadge(db, user, rewardValue)
 
 			// Add other reward types as needed
 		}
@@ -571,27 +591,15 @@ func redeemSecretCodeHandler(db *gorm.DB) http.HandlerFunc {
 			if secretCode.Description != nil {
 				description = description + ": " + *secretCode.Description
 			}
-			xpTransaction := &store.XPTransaction{
-				UserID:          &userIDInt,
-				TransactionType: "bonus",
-				Amount:          secretCode.XPReward,
-				SourceType:      stringPtr("secret_code"),
-				SourceID:        &secretCodeIDInt,
-				Description:     stringPtr(description),
+			if _, err := store.AwardXP(db, user.ID, secretCode.XPReward, "bonus", "secret_code", secretCode.ID, description); err != nil {
+				internalServerError(w, r, err)
+				return
 			}
-			store.CreateXPTransaction(db, xpTransaction)
-
-			user.XP += secretCode.XPReward
-			store.UpdateUser(db, user)
 		}
 
 		// Award badge if specified
 		if secretCode.BadgeID != nil {
-			userBadge := &store.UserBadge{
-				UserID:  userIDInt,
-				BadgeID: int(*secretCode.BadgeID),
-			}
-			store.CreateUserBadge(db, userBadge)
+			awardBadge(db, user, int(*secretCode.BadgeID))
 		}
 
 		response := map[string]interface{}{
@@ -686,10 +694,38 @@ func submitWeeklyVibeHandler(db *gorm.DB) http.HandlerFunc {
 			ProofURL    string `json:"proof_url" validate:"required,url"`
 			ProofType   string `json:"proof_type" validate:"required"`
 		}
+		var uploaded, thumbnail *store.UploadedFile
 
-		if err := readJSON(w, r, &req); err != nil {
-			badRequestResponse(w, r, err)
-			return
+		// Entries are either uploaded directly or linked by URL
+		if isMultipart(r) {
+			r.Body = http.MaxBytesReader(w, r.Body, maxSubmissionMediaSize+(1<<20))
+			if err := r.ParseMultipartForm(32 << 20); err != nil {
+				badRequestResponse(w, r, err)
+				return
+			}
+			var err error
+			uploaded, thumbnail, err = saveMediaUpload(db, r, user, "proof", "feed", maxSubmissionMediaSize, submissionMediaTypes)
+			if err != nil {
+				if isUploadError(err) {
+					badRequestResponse(w, r, errors.New("only JPEG/PNG/GIF images, MP4/WebM/MOV videos and PDFs up to 50 MB are allowed"))
+				} else {
+					internalServerError(w, r, err)
+				}
+				return
+			}
+			req.Title = r.FormValue("title")
+			req.Description = r.FormValue("description")
+			req.ProofURL = fileURL(uploaded.StorageKey)
+			req.ProofType = proofTypeForContent(uploaded.ContentType)
+		} else {
+			if err := readJSON(w, r, &req); err != nil {
+				badRequestResponse(w, r, err)
+				return
+			}
+			if err := Validate.Struct(req); err != nil {
+				badRequestResponse(w, r, err)
+				return
+			}
 		}
 
 		// Create task for weekly challenge if it doesn't exist
@@ -730,6 +766,22 @@ func submitWeeklyVibeHandler(db *gorm.DB) http.HandlerFunc {
 		}
 		store.CreateSubmission(db, submission)
 
+		if uploaded != nil {
+			subID := int(submission.ID)
+			size := int(uploaded.Size)
+			media := &store.SubmissionMedia{
+				SubmissionID: &subID,
+				MediaType:    mediaTypeForContent(uploaded.ContentType),
+				MediaURL:     fileURL(uploaded.StorageKey),
+				FileName:     uploaded.OriginalName,
+				FileSize:     &size,
+			}
+			if thumbnail != nil {
+				media.ThumbnailURL = stringPtr(fileURL(thumbnail.StorageKey))
+			}
+			store.CreateSubmissionMedia(db, media)
+		}
+
 		response := map[string]interface{}{
 			"message":       "Submission received! Voting starts after the submission deadline.",
 			"submission_id": submission.ID,
@@ -765,14 +817,26 @@ func getWeeklyChallengeSubmissionsHandler(db *gorm.DB) http.HandlerFunc {
 			Order("submitted_at DESC").
 			Find(&submissions)
 
+		// Feed cards show thumbnails instead of the full-size proof
+		submissionIDs := make([]uint, 0, len(submissions))
+		for _, sub := range submissions {
+			submissionIDs = append(submissionIDs, sub.ID)
+		}
+		thumbnails, err := store.GetSubmissionThumbnails(db, submissionIDs)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
 		// Format response
 		var response []map[string]interface{}
 		for _, sub := range submissions {
 			response = append(response, map[string]interface{}{
-				"id":           sub.ID,
-				"proof_url":    sub.ProofURL,
-				"description":  sub.ProofText,
-				"submitted_at": sub.SubmittedAt,
+				"id":            sub.ID,
+				"proof_url":     sub.ProofURL,
+				"thumbnail_url": thumbnails[sub.ID],
+				"description":   sub.ProofText,
+				"submitted_at":  sub.SubmittedAt,
 				"user": map[string]interface{}{
 					"id":         sub.User.ID,
 					"first_name": sub.User.FirstName,
@@ -923,6 +987,62 @@ func getActiveBattlesHandler(db *gorm.DB) http.HandlerFunc {
 	}
 }
 
+// Get Content Battle Submissions
+func getBattleSubmissionsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		battleIDStr := chi.URLParam(r, "id")
+		battleID, err := strconv.ParseUint(battleIDStr, 10, 32)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("invalid battle ID"))
+			return
+		}
+
+		var battle store.ContentBattle
+		result := db.First(&battle, battleID)
+		if result.Error != nil {
+			notFoundResponse(w, r, errors.New("battle not found"))
+			return
+		}
+
+		var submissions []store.BattleSubmission
+		// Settled battles list in final standings
+		db.Where("battle_id = ?", battle.ID).
+			Preload("User").
+			Order("rank ASC NULLS LAST, submitted_at ASC").
+			Find(&submissions)
+
+		// Feed cards carry the thumbnail, if any; the full media is fetched on demand
+		response := make([]map[string]interface{}, 0, len(submissions))
+		for _, sub := range submissions {
+			entry := map[string]interface{}{
+				"id":            sub.ID,
+				"title":         sub.Title,
+				"description":   sub.Description,
+				"media_url":     sub.MediaURL,
+				"thumbnail_url": sub.ThumbnailURL, // null for entries without an image thumbnail
+				"vote_count":    sub.VoteCount,
+				"rank":          sub.Rank,
+				"is_winner":     sub.IsWinner,
+				"submitted_at":  sub.SubmittedAt,
+			}
+			if sub.User != nil {
+				entry["user"] = map[string]interface{}{
+					"id":         sub.User.ID,
+					"first_name": sub.User.FirstName,
+					"last_name":  sub.User.LastName,
+					"avatar_url": sub.User.AvatarURL,
+					"college_id": sub.User.CollegeID,
+				}
+			}
+			response = append(response, entry)
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
 // Submit to Content Battle
 func submitBattleHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -974,38 +1094,67 @@ func submitBattleHandler(db *gorm.DB) http.HandlerFunc {
 			Description string `json:"description"`
 			MediaURL    string `json:"media_url" validate:"required,url"`
 		}
+		var thumbnailURL *string
 
-		if err := readJSON(w, r, &req); err != nil {
-			badRequestResponse(w, r, err)
-			return
+		// Entries are either uploaded directly or linked by URL
+		if isMultipart(r) {
+			r.Body = http.MaxBytesReader(w, r.Body, maxSubmissionMediaSize+(1<<20))
+			if err := r.ParseMultipartForm(32 << 20); err != nil {
+				badRequestResponse(w, r, err)
+				return
+			}
+			uploaded, thumbnail, err := saveMediaUpload(db, r, user, "media", "feed", maxSubmissionMediaSize, submissionMediaTypes)
+			if err != nil {
+				if isUploadError(err) {
+					badRequestResponse(w, r, errors.New("only JPEG/PNG/GIF images, MP4/WebM/MOV videos and PDFs up to 50 MB are allowed"))
+				} else {
+					internalServerError(w, r, err)
+				}
+				return
+			}
+			req.Title = r.FormValue("title")
+			req.Description = r.FormValue("description")
+			req.MediaURL = fileURL(uploaded.StorageKey)
+			if thumbnail != nil {
+				thumbnailURL = stringPtr(fileURL(thumbnail.StorageKey))
+			}
+		} else {
+			if err := readJSON(w, r, &req); err != nil {
+				badRequestResponse(w, r, err)
+				return
+			}
+			if err := Validate.Struct(req); err != nil {
+				badRequestResponse(w, r, err)
+				return
+			}
 		}
 
 		// Create submission
 		battleIDInt := int(battle.ID)
 		userIDInt := int(user.ID)
 		submission := &store.BattleSubmission{
-			BattleID:    &battleIDInt,
-			UserID:      &userIDInt,
-			Title:       stringPtr(req.Title),
-			Description: stringPtr(req.Description),
-			MediaURL:    req.MediaURL,
-			SubmittedAt: now,
-		}
-		store.CreateBattleSubmission(db, submission)
-
-		// Award participation XP
-		xpTransaction := &store.XPTransaction{
-			UserID:          &userIDInt,
-			TransactionType: "battle_participation",
-			Amount:          100,
-			SourceType:      stringPtr("content_battle"),
-			SourceID:        &battleIDInt,
-			Description:     stringPtr("Content battle participation"),
+			BattleID:     &battleIDInt,
+			UserID:       &userIDInt,
+			Title:        stringPtr(req.Title),
+			Description:  stringPtr(req.Description),
+			MediaURL:     req.MediaURL,
+			ThumbnailURL: thumbnailURL,
+			SubmittedAt:  now,
+		}
+		// The entry and its participation XP stand or fall together
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := store.CreateBattleSubmission(tx, submission); err != nil {
+				return err
+			}
+			_, err := store.AwardXP(tx, user.ID, 100, "bonus", "content_battle", battle.ID, "Content battle participation")
+			return err
+		})
+		if err != nil {
+			internalServerError(w, r, err)
+			return
 		}
-		store.CreateXPTransaction(db, xpTransaction)
 
-		user.XP += 100
-		store.UpdateUser(db, user)
+		recordActivity(db, user.ID, store.QuestEvent{Type: store.QuestStepBattleEntered})
 
 		response := map[string]interface{}{
 			"message":       "Submission received! Good luck!",
@@ -1081,25 +1230,22 @@ func voteBattleHandler(db *gorm.DB) http.HandlerFunc {
 			VoterID:      &userIDInt,
 			VotedAt:      now,
 		}
-		store.CreateBattleVote(db, &vote)
-
-		// Update submission vote count
-		submission.VoteCount++
-		db.Save(&submission)
-
-		// Award XP for voting
-		xpTransaction := &store.XPTransaction{
-			UserID:          &userIDInt,
-			TransactionType: "battle_vote",
-			Amount:          10,
-			SourceType:      stringPtr("content_battle"),
-			SourceID:        &battleIDInt,
-			Description:     stringPtr("Content battle voting"),
+		// The vote, the count and the voting XP stand or fall together
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := store.CreateBattleVote(tx, &vote); err != nil {
+				return err
+			}
+			if err := tx.Model(&submission).UpdateColumn("vote_count", gorm.Expr("vote_count + 1")).Error; err != nil {
+				return err
+			}
+			_, err := store.AwardXP(tx, user.ID, 10, "bonus", "content_battle", battle.ID, "Content battle voting")
+			return err
+		})
+		if err != nil {
+			internalServerError(w, r, err)
+			return
 		}
-		store.CreateXPTransaction(db, xpTransaction)
-
-		user.XP += 10
-		store.UpdateUser(db, user)
+		submission.VoteCount++
 
 		response := map[string]interface{}{
 			"message":     "Vote recorded successfully",
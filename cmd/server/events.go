@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rohit21755/gg_server.git/internal/scheduler"
+	"github.com/rohit21755/gg_server.git/internal/services"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"github.com/rohit21755/gg_server.git/ws"
+	"gorm.io/gorm"
+)
+
+// registerEventHooks wires the server's reactions to event lifecycle edges.
+func registerEventHooks(s *scheduler.Scheduler) {
+	// Every edge is announced to connected clients as "event.<edge>"
+	s.Listen(func(event scheduler.Event) {
+		services.NotifyAll(ws.Encode("event."+event.Edge, event))
+	})
+
+	s.On(scheduler.KindCampaign, scheduler.EdgeCompleted, completeCampaignHook)
+	s.On(scheduler.KindContentBattle, scheduler.EdgeVotingStarted, battleVotingStartedHook)
+	s.On(scheduler.KindContentBattle, scheduler.EdgeCompleted, completeBattleHook)
+	s.On(scheduler.KindCampusWar, scheduler.EdgeCompleted, completeWarHook)
+}
+
+// completeCampaignHook settles a campaign that reached its end date.
+func completeCampaignHook(tx *gorm.DB, event scheduler.Event) error {
+	campaign, err := store.GetCampaignByID(tx, event.ID)
+	if err != nil {
+		return err
+	}
+	_, certificates, err := settleCompletedCampaign(tx, campaign)
+	if err != nil {
+		return err
+	}
+	for i := range certificates {
+		notifyCertificateIssued(tx, &certificates[i])
+	}
+	return nil
+}
+
+// battleVotingStartedHook tells everyone who entered a battle that voting is
+// open.
+func battleVotingStartedHook(tx *gorm.DB, event scheduler.Event) error {
+	var battle store.ContentBattle
+	if err := tx.First(&battle, event.ID).Error; err != nil {
+		return err
+	}
+
+	var userIDs []int
+	if err := tx.Model(&store.BattleSubmission{}).Where("battle_id = ?", battle.ID).Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"battle_id":  battle.ID,
+		"voting_end": battle.VotingEnd,
+	})
+	for i := range userIDs {
+		notification := &store.Notification{
+			UserID:           &userIDs[i],
+			NotificationType: "system",
+			Title:            "Voting is open",
+			Message:          fmt.Sprintf("Voting has started in \"%s\". Share your entry to collect votes!", battle.Title),
+			ActionURL:        stringPtr(fmt.Sprintf("/battles/%d", battle.ID)),
+			Data:             stringPtr(string(dataJSON)),
+		}
+		if err := store.CreateNotification(tx, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeBattleHook settles a battle whose voting has closed.
+func completeBattleHook(tx *gorm.DB, event scheduler.Event) error {
+	battle, err := store.GetContentBattleByID(tx, event.ID)
+	if err != nil {
+		return err
+	}
+	if err := settleContentBattle(tx, battle); err != nil && !errors.Is(err, store.ErrBattleAlreadySettled) {
+		return err
+	}
+	return nil
+}
+
+// completeWarHook settles a campus war that reached its end date.
+func completeWarHook(tx *gorm.DB, event scheduler.Event) error {
+	war, err := store.GetCampusWarByID(tx, event.ID)
+	if err != nil {
+		return err
+	}
+	if err := settleCampusWar(tx, war); err != nil && !errors.Is(err, store.ErrWarAlreadySettled) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/imaging"
+	"github.com/rohit21755/gg_server.git/internal/storage"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+const (
+	maxAvatarSize          = 5 << 20
+	maxResumeSize          = 10 << 20
+	maxSubmissionMediaSize = 50 << 20
+
+	avatarSize        = 256
+	thumbnailSize     = 320
+	maxImageDimension = 2048
+)
+
+// Allowed upload types, keyed by sniffed MIME type, mapped to the stored extension
+var (
+	avatarTypes = map[string]string{
+		"image/jpeg": ".jpg",
+		"image/png":  ".png",
+		"image/gif":  ".gif",
+	}
+	resumeTypes = map[string]string{
+		"application/pdf":    ".pdf",
+		"application/msword": ".doc",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": ".docx",
+	}
+	submissionMediaTypes = map[string]string{
+		"image/jpeg":      ".jpg",
+		"image/png":       ".png",
+		"image/gif":       ".gif",
+		"video/mp4":       ".mp4",
+		"video/webm":      ".webm",
+		"video/quicktime": ".mov",
+		"application/pdf": ".pdf",
+	}
+)
+
+// fileURL is the authenticated download URL for a stored object.
+func fileURL(key string) string {
+	return "/api/v1/files/" + key
+}
+
+// readUpload reads the multipart file in field, rejecting it when it is larger
+// than maxSize.
+func readUpload(r *http.Request, field string, maxSize int64) ([]byte, string, error) {
+	file, handler, err := r.FormFile(field)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s %w", field, storage.ErrMissingFile)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", storage.ErrTooLarge
+	}
+	return data, handler.Filename, nil
+}
+
+// storeUpload writes data under a content-addressed key inside folder and
+// records the upload for the owner.
+func storeUpload(db *gorm.DB, r *http.Request, owner *store.User, folder, category string, data []byte, name string, allowed map[string]string) (*store.UploadedFile, error) {
+	object, err := storage.Save(r.Context(), folder, bytes.NewReader(data), int64(len(data)), allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded := &store.UploadedFile{
+		StorageKey:   object.Key,
+		OwnerID:      int(owner.ID),
+		Category:     category,
+		ContentType:  object.ContentType,
+		Size:         object.Size,
+		OriginalName: stringPtr(name),
+	}
+	if err := store.RecordUploadedFile(db, uploaded); err != nil {
+		return nil, err
+	}
+	return uploaded, nil
+}
+
+// saveUpload stores the multipart file in field as-is. The returned error is
+// safe to show to the client when isUploadError reports true.
+func saveUpload(db *gorm.DB, r *http.Request, owner *store.User, field, category string, maxSize int64, allowed map[string]string) (*store.UploadedFile, error) {
+	data, name, err := readUpload(r, field, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return storeUpload(db, r, owner, category, category, data, name, allowed)
+}
+
+// saveAvatarUpload stores the uploaded image as a square, metadata-free avatar.
+func saveAvatarUpload(db *gorm.DB, r *http.Request, owner *store.User) (*store.UploadedFile, error) {
+	data, name, err := readUpload(r, "avatar", maxAvatarSize)
+	if err != nil {
+		return nil, err
+	}
+
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		return nil, uploadImageError(err)
+	}
+	avatar, err := imaging.Encode(imaging.Thumbnail(img, avatarSize, avatarSize), format)
+	if err != nil {
+		return nil, err
+	}
+	return storeUpload(db, r, owner, "avatar", "avatar", avatar, name, avatarTypes)
+}
+
+// saveMediaUpload stores the multipart file in field. Images are re-encoded
+// without their metadata, capped at maxImageDimension and given a fixed-size
+// thumbnail; other allowed files are stored unchanged with no thumbnail.
+func saveMediaUpload(db *gorm.DB, r *http.Request, owner *store.User, field, category string, maxSize int64, allowed map[string]string) (*store.UploadedFile, *store.UploadedFile, error) {
+	data, name, err := readUpload(r, field, maxSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	img, format, err := imaging.Decode(data)
+	if errors.Is(err, imaging.ErrUnsupportedFormat) {
+		uploaded, err := storeUpload(db, r, owner, category, category, data, name, allowed)
+		return uploaded, nil, err
+	}
+	if err != nil {
+		return nil, nil, uploadImageError(err)
+	}
+
+	full, err := imaging.Encode(imaging.Fit(img, maxImageDimension, maxImageDimension), format)
+	if err != nil {
+		return nil, nil, err
+	}
+	uploaded, err := storeUpload(db, r, owner, category, category, full, name, allowed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	thumb, err := imaging.Encode(imaging.Thumbnail(img, thumbnailSize, thumbnailSize), format)
+	if err != nil {
+		return nil, nil, err
+	}
+	thumbnail, err := storeUpload(db, r, owner, category+"/thumbs", category, thumb, name, allowed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return uploaded, thumbnail, nil
+}
+
+// uploadImageError maps image decoding failures onto upload errors.
+func uploadImageError(err error) error {
+	if errors.Is(err, imaging.ErrImageTooLarge) {
+		return storage.ErrTooLarge
+	}
+	return fmt.Errorf("%w: %v", storage.ErrUnsupportedType, err)
+}
+
+// isMultipart reports whether the request carries a multipart form.
+func isMultipart(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
+
+// mediaTypeForContent maps a sniffed MIME type onto a SubmissionMedia media type.
+func mediaTypeForContent(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return "image"
+	case strings.HasPrefix(contentType, "video/"):
+		return "video"
+	default:
+		return "document"
+	}
+}
+
+// proofTypeForContent maps a sniffed MIME type onto a submission proof type.
+func proofTypeForContent(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return "screenshot"
+	case strings.HasPrefix(contentType, "video/"):
+		return "video"
+	default:
+		return "pdf"
+	}
+}
+
+// isUploadError reports whether err was caused by the uploaded content itself.
+func isUploadError(err error) bool {
+	return errors.Is(err, storage.ErrTooLarge) || errors.Is(err, storage.ErrUnsupportedType) ||
+		errors.Is(err, storage.ErrMissingFile)
+}
+
+// canDownloadFile decides whether the user may read a stored object. Avatars and
+// feed entries are visible to every signed-in user; everything else only to its
+// uploaders and staff.
+func canDownloadFile(user *store.User, files []store.UploadedFile) bool {
+	if user.Role == "admin" || user.Role == "moderator" || user.Role == "state_lead" {
+		return true
+	}
+	for _, f := range files {
+		if f.Category == "avatar" || f.Category == "feed" || uint(f.OwnerID) == user.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Download File
+func downloadFileHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		key := chi.URLParam(r, "*")
+		if !storage.ValidKey(key) {
+			notFoundResponse(w, r, errors.New("file not found"))
+			return
+		}
+
+		files, err := store.GetUploadedFilesByKey(db, key)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+		if len(files) == 0 {
+			notFoundResponse(w, r, errors.New("file not found"))
+			return
+		}
+		if !canDownloadFile(user, files) {
+			writeJSONError(w, http.StatusForbidden, "not authorized to access this file")
+			return
+		}
+
+		body, err := storage.Files.Get(r.Context(), key)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				notFoundResponse(w, r, errors.New("file not found"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+		defer body.Close()
+
+		disposition := "attachment"
+		if strings.HasPrefix(files[0].ContentType, "image/") || strings.HasPrefix(files[0].ContentType, "video/") {
+			disposition = "inline"
+		}
+
+		w.Header().Set("Content-Type", files[0].ContentType)
+		w.Header().Set("Content-Disposition", disposition)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "private, max-age=86400, immutable")
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, body)
+	}
+}
@@ -106,31 +106,21 @@ func awardXPHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Create XP transaction
+		// Award XP, recording who awarded it
 		userIDInt := int(req.UserID)
-		sourceIDInt := int(req.SourceID)
 		metadataJSON, _ := json.Marshal(map[string]interface{}{
 			"awarded_by":      user.ID,
 			"awarded_by_name": user.FirstName + " " + user.LastName,
 		})
-		xpTransaction := &store.XPTransaction{
-			UserID:          &userIDInt,
-			TransactionType: "bonus",
-			Amount:          req.Amount,
-			SourceType:      stringPtr(req.SourceType),
-			SourceID:        &sourceIDInt,
-			Description:     stringPtr(req.Reason),
-			Metadata:        stringPtr(string(metadataJSON)),
-		}
-
-		if err := store.CreateXPTransaction(db, xpTransaction); err != nil {
-			internalServerError(w, r, err)
-			return
-		}
-
-		// Update user XP
-		targetUser.XP += req.Amount
-		if err := store.UpdateUser(db, targetUser); err != nil {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			xpTransaction, err := store.AwardXP(tx, targetUser.ID, req.Amount, "bonus", req.SourceType, req.SourceID, req.Reason)
+			if err != nil {
+				return err
+			}
+			targetUser.XP = xpTransaction.BalanceAfter
+			return tx.Model(xpTransaction).Update("metadata", string(metadataJSON)).Error
+		})
+		if err != nil {
 			internalServerError(w, r, err)
 			return
 		}
@@ -240,7 +230,8 @@ func getBadgesHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		category := r.URL.Query().Get("category")
 
-		query := db.Model(&store.Badge{})
+		// Secret badges stay hidden until earned
+		query := db.Model(&store.Badge{}).Where("is_secret = ?", false)
 		if category != "" {
 			query = query.Where("category = ?", category)
 		}
@@ -279,6 +270,22 @@ func getBadgeHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
+		// Secret badges are only shown to users who hold them
+		if badge.IsSecret {
+			held := false
+			if user, ok := GetUserFromContext(r); ok {
+				held, err = store.HasUserBadge(db, user.ID, int(badge.ID))
+				if err != nil {
+					internalServerError(w, r, err)
+					return
+				}
+			}
+			if !held {
+				notFoundResponse(w, r, errors.New("badge not found"))
+				return
+			}
+		}
+
 		if err := jsonResponse(w, http.StatusOK, badge); err != nil {
 			internalServerError(w, r, err)
 		}
@@ -300,16 +307,18 @@ func getUserBadgesHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Get all badges to show locked ones
-		var allBadges []store.Badge
-		db.Where("is_secret = ?", false).Order("created_at DESC").Find(&allBadges)
-
 		// Create map of earned badges
 		earnedMap := make(map[int]store.UserBadge)
+		earnedIDs := []int{0}
 		for _, badge := range badges {
 			earnedMap[badge.BadgeID] = badge
+			earnedIDs = append(earnedIDs, badge.BadgeID)
 		}
 
+		// Get all badges to show locked ones; secret badges only once earned
+		var allBadges []store.Badge
+		db.Where("is_secret = ? OR id IN ?", false, earnedIDs).Order("created_at DESC").Find(&allBadges)
+
 		// Build response with earned status
 		var response []map[string]interface{}
 		for _, badge := range allBadges {
@@ -556,6 +565,7 @@ func logStreakHandler(db *gorm.DB) http.HandlerFunc {
 			internalServerError(w, r, err)
 			return
 		}
+		checkAchievements(db, user.ID)
 
 		// Create streak log
 		userIDInt := int(user.ID)
@@ -716,19 +726,12 @@ func spinWheelHandler(db *gorm.DB) http.HandlerFunc {
 		switch selectedItem.ItemType {
 		case "xp":
 			// Award XP
-			spinIDInt := int(userSpin.ID)
-			xpTransaction := &store.XPTransaction{
-				UserID:          &userIDInt,
-				TransactionType: "spin_wheel",
-				Amount:          selectedItem.ItemValue,
-				SourceType:      stringPtr("spin_wheel"),
-				SourceID:        &spinIDInt,
-				Description:     stringPtr("Spin wheel reward: " + selectedItem.ItemLabel),
+			xpTransaction, err := store.AwardXP(db, user.ID, selectedItem.ItemValue, "spin_wheel", "spin_wheel", userSpin.ID, "Spin wheel reward: "+selectedItem.ItemLabel)
+			if err != nil {
+				internalServerError(w, r, err)
+				return
 			}
-			store.CreateXPTransaction(db, xpTransaction)
-
-			user.XP += selectedItem.ItemValue
-			store.UpdateUser(db, user)
+			user.XP = xpTransaction.BalanceAfter
 
 			rewardDetails = map[string]interface{}{
 				"type":  "xp",
@@ -737,11 +740,7 @@ func spinWheelHandler(db *gorm.DB) http.HandlerFunc {
 
 		case "badge":
 			// Award badge
-			userBadge := &store.UserBadge{
-				UserID:  userIDInt,
-				BadgeID: selectedItem.ItemValue,
-			}
-			store.CreateUserBadge(db, userBadge)
+			awardBadge(db, user, selectedItem.ItemValue)
 
 			rewardDetails = map[string]interface{}{
 				"type":  "badge",
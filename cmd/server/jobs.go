@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rohit21755/gg_server.git/internal/jobs"
+	"github.com/rohit21755/gg_server.git/internal/services"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// registerJobHandlers wires every job type the server knows how to run.
+func registerJobHandlers(runner *jobs.Runner) {
+	runner.Register(store.JobTypePushNotification, pushNotificationJob)
+	runner.Every(store.JobTypeExpireRevisions, 10*time.Minute, expireRevisionsJob)
+	runner.Every(store.JobTypeExpireQuests, 10*time.Minute, expireQuestsJob)
+	runner.Every(store.JobTypeCampusWarScoring, 5*time.Minute, campusWarScoringJob)
+	runner.Every(store.JobTypeLeaderboardSnapshots, time.Hour, leaderboardSnapshotsJob)
+}
+
+// pushNotificationJob delivers a notification that was scheduled for later.
+func pushNotificationJob(ctx context.Context, db *gorm.DB, job *store.ScheduledJob) (interface{}, error) {
+	var data struct {
+		NotificationID uint `json:"notification_id"`
+	}
+	if err := jobs.Decode(job, &data); err != nil {
+		return nil, err
+	}
+
+	notification, err := store.GetNotificationByID(db, data.NotificationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Deleted before it was due
+		return map[string]string{"skipped": "notification deleted"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	services.PushNotification(db, notification)
+	return map[string]uint{"notification_id": notification.ID}, nil
+}
+
+// campusWarScoringJob rescores the active campus wars. Wars are settled
+// separately when they end.
+func campusWarScoringJob(ctx context.Context, db *gorm.DB, job *store.ScheduledJob) (interface{}, error) {
+	scored, err := services.ScoreActiveWars(db, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"scored": scored}, nil
+}
+
+// leaderboardSnapshotsJob closes ended leaderboard periods and rebuilds the
+// day's snapshots, so served standings trail live ones by at most an hour.
+func leaderboardSnapshotsJob(ctx context.Context, db *gorm.DB, job *store.ScheduledJob) (interface{}, error) {
+	snapshotted, err := services.SnapshotLeaderboards(db, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"snapshotted": snapshotted}, nil
+}
+
+// jobPayload is the admin API shape of a scheduled job.
+func jobPayload(job *store.ScheduledJob) map[string]interface{} {
+	payload := map[string]interface{}{
+		"id":            job.ID,
+		"job_type":      job.JobType,
+		"scheduled_for": job.ScheduledFor,
+		"status":        job.Status,
+		"attempts":      job.Attempts,
+		"max_attempts":  job.MaxAttempts,
+		"is_recurring":  job.IsRecurring,
+		"error_message": job.ErrorMessage,
+		"started_at":    job.StartedAt,
+		"completed_at":  job.CompletedAt,
+		"created_at":    job.CreatedAt,
+	}
+	if job.JobData != nil {
+		payload["job_data"] = json.RawMessage(*job.JobData)
+	}
+	if job.Result != nil {
+		payload["result"] = json.RawMessage(*job.Result)
+	}
+	return payload
+}
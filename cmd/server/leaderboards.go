@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// parseAsOf reads the optional as_of date of a leaderboard request. Without
+// one, the latest snapshot is served.
+func parseAsOf(r *http.Request) (time.Time, error) {
+	asOfStr := r.URL.Query().Get("as_of")
+	if asOfStr == "" {
+		return time.Now(), nil
+	}
+	asOf, err := time.Parse(store.SnapshotDateFormat, asOfStr)
+	if err != nil {
+		return time.Time{}, errors.New("as_of must be a date in YYYY-MM-DD format")
+	}
+	return asOf, nil
+}
+
+// leaderboardSnapshotPayload serves a snapshotted leaderboard: the entries of
+// its latest snapshot on or before asOf, with each user's rank, previous rank
+// and trend. A leaderboard without snapshots yet is empty.
+func leaderboardSnapshotPayload(db *gorm.DB, board, name string, asOf time.Time, limit int) (map[string]interface{}, error) {
+	rows := []map[string]interface{}{}
+	payload := map[string]interface{}{
+		"leaderboard": rows,
+		"as_of":       nil,
+	}
+
+	leaderboard, err := store.FindLeaderboard(db, board, name, asOf)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return payload, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if leaderboard.PeriodStart != nil {
+		payload["period_start"] = leaderboard.PeriodStart.Format(store.SnapshotDateFormat)
+		payload["period_end"] = leaderboard.PeriodEnd.Format(store.SnapshotDateFormat)
+	}
+
+	entries, snapshotDate, err := store.GetLeaderboardSnapshot(db, leaderboard.ID, asOf, limit)
+	if err != nil {
+		return nil, err
+	}
+	if snapshotDate != nil {
+		payload["as_of"] = snapshotDate.Format(store.SnapshotDateFormat)
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		rows = append(rows, map[string]interface{}{
+			"rank":          entry.Rank,
+			"previous_rank": entry.PreviousRank,
+			"trend":         entry.Trend,
+			"xp":            entry.XP,
+			"submissions":   entry.SubmissionsCount,
+			"user":          entry.User,
+		})
+	}
+	payload["leaderboard"] = rows
+	return payload, nil
+}
@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/rohit21755/gg_server.git/internal/db"
 	"github.com/rohit21755/gg_server.git/internal/env"
+	"github.com/rohit21755/gg_server.git/internal/jobs"
+	"github.com/rohit21755/gg_server.git/internal/scheduler"
+	"github.com/rohit21755/gg_server.git/internal/services"
+	"github.com/rohit21755/gg_server.git/internal/storage"
 	"github.com/rohit21755/gg_server.git/ws"
 
 	"github.com/go-chi/chi/middleware"
@@ -24,6 +33,9 @@ func main() {
 	}
 	log.Println("Database connected successfully")
 
+	services.InitMailer()
+	storage.Init()
+
 	router := chi.NewRouter()
 	log.Println("Router created")
 	router.Use(cors.Handler(cors.Options{
@@ -41,15 +53,59 @@ func main() {
 	// WebSockets
 	hub := ws.NewHub()
 	go hub.Run()
+	services.InitNotifier(hub)
+	services.InitLeaderboardStream(database, hub, 2*time.Second)
+	watchAchievements(database)
+	services.InitLiveTrivia(database, hub)
 
 	// REST API
 	setupREST(router, database)
 
+	// Background workers
+	services.LiveTrivia.Start(5 * time.Second)
+
+	events := scheduler.New(database)
+	registerEventHooks(events)
+	go events.Run(30 * time.Second)
+
+	runner := jobs.New(database, jobs.Options{})
+	registerJobHandlers(runner)
+	runner.Start()
+
 	// WebSocket endpoint
 	router.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		serveWS(hub, w, r)
+		serveWS(hub, database, w, r)
 	})
 
-	log.Println("Server running on :" + os.Getenv("SERVER_PORT"))
-	http.ListenAndServe(":"+os.Getenv("SERVER_PORT"), router)
+	server := &http.Server{
+		Addr:    ":" + os.Getenv("SERVER_PORT"),
+		Handler: router,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Println("Server running on :" + os.Getenv("SERVER_PORT"))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down...")
+
+	// Give in-flight requests and jobs time to finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP shutdown error: %v", err)
+	}
+	if err := runner.Shutdown(shutdownCtx); err != nil {
+		log.Printf("job runner shutdown error: %v", err)
+	}
+	if err := services.LiveTrivia.Shutdown(shutdownCtx); err != nil {
+		log.Printf("live trivia shutdown error: %v", err)
+	}
 }
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/rohit21755/gg_server.git/internal/env"
 	"github.com/rohit21755/gg_server.git/internal/store"
 	"gorm.io/gorm"
 )
@@ -40,28 +42,9 @@ func RequireAuth(db *gorm.DB) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Get session from database
-			session, err := store.GetSessionByToken(db, token)
+			user, err := authenticateToken(db, token)
 			if err != nil {
-				writeJSONError(w, http.StatusUnauthorized, "invalid or expired token")
-				return
-			}
-
-			// Check if session is expired
-			if time.Now().After(session.ExpiresAt) {
-				writeJSONError(w, http.StatusUnauthorized, "token has expired")
-				return
-			}
-
-			// Get user from session
-			if session.UserID == nil {
-				writeJSONError(w, http.StatusUnauthorized, "invalid session")
-				return
-			}
-
-			user, err := store.GetUserByID(db, uint(*session.UserID))
-			if err != nil {
-				writeJSONError(w, http.StatusUnauthorized, "user not found")
+				writeJSONError(w, http.StatusUnauthorized, err.Error())
 				return
 			}
 
@@ -72,6 +55,33 @@ func RequireAuth(db *gorm.DB) func(http.Handler) http.Handler {
 	}
 }
 
+// authenticateToken resolves a session token to its user. The returned error
+// message is safe to send to the client.
+func authenticateToken(db *gorm.DB, token string) (*store.User, error) {
+	// Get session from database
+	session, err := store.GetSessionByToken(db, token)
+	if err != nil {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	// Check if session is expired
+	if time.Now().After(session.ExpiresAt) {
+		return nil, errors.New("token has expired")
+	}
+
+	// Get user from session
+	if session.UserID == nil {
+		return nil, errors.New("invalid session")
+	}
+
+	user, err := store.GetUserByID(db, uint(*session.UserID))
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	return user, nil
+}
+
 // GetUserFromContext retrieves the authenticated user from the request context
 func GetUserFromContext(r *http.Request) (*store.User, bool) {
 	user, ok := r.Context().Value(userContextKey).(*store.User)
@@ -97,3 +107,30 @@ func RequireAdmin(db *gorm.DB) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// RequireVerifiedEmail is a middleware that blocks users who have not verified
+// their email. It is only enforced when REQUIRE_EMAIL_VERIFICATION is "true".
+func RequireVerifiedEmail() func(http.Handler) http.Handler {
+	enforced := env.Get("REQUIRE_EMAIL_VERIFICATION", "false") == "true"
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !enforced {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, ok := GetUserFromContext(r)
+			if !ok {
+				writeJSONError(w, http.StatusUnauthorized, "authentication required")
+				return
+			}
+
+			if user.EmailVerifiedAt == nil {
+				writeJSONError(w, http.StatusForbidden, "email verification required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
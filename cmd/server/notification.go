@@ -1,13 +1,13 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/services"
 	"github.com/rohit21755/gg_server.git/internal/store"
 	"gorm.io/gorm"
 )
@@ -64,29 +64,8 @@ func getNotificationsHandler(db *gorm.DB) http.HandlerFunc {
 
 		// Format response
 		var responseNotifications []map[string]interface{}
-		for _, notification := range notifications {
-			notificationData := map[string]interface{}{
-				"id":                notification.ID,
-				"notification_type": notification.NotificationType,
-				"title":             notification.Title,
-				"message":           notification.Message,
-				"is_read":           notification.IsRead,
-				"is_actionable":     notification.IsActionable,
-				"action_url":        notification.ActionURL,
-				"sent_at":           notification.SentAt,
-				"read_at":           notification.ReadAt,
-				"created_at":        notification.CreatedAt,
-			}
-
-			// Parse data JSON
-			if notification.Data != nil && *notification.Data != "" {
-				var data interface{}
-				if err := json.Unmarshal([]byte(*notification.Data), &data); err == nil {
-					notificationData["data"] = data
-				}
-			}
-
-			responseNotifications = append(responseNotifications, notificationData)
+		for i := range notifications {
+			responseNotifications = append(responseNotifications, services.NotificationPayload(&notifications[i]))
 		}
 
 		// Get unread count
@@ -179,6 +158,7 @@ func markNotificationReadHandler(db *gorm.DB) http.HandlerFunc {
 				internalServerError(w, r, err)
 				return
 			}
+			services.PushUnreadCount(db, user.ID)
 		}
 
 		response := map[string]interface{}{
@@ -224,6 +204,7 @@ func markAllNotificationsReadHandler(db *gorm.DB) http.HandlerFunc {
 			internalServerError(w, r, result.Error)
 			return
 		}
+		services.PushUnreadCount(db, user.ID)
 
 		response := map[string]interface{}{
 			"message":               "All notifications marked as read",
@@ -274,6 +255,9 @@ func deleteNotificationHandler(db *gorm.DB) http.HandlerFunc {
 			internalServerError(w, r, err)
 			return
 		}
+		if !notification.IsRead {
+			services.PushUnreadCount(db, user.ID)
+		}
 
 		response := map[string]interface{}{
 			"message": "Notification deleted successfully",
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// questPayload describes a quest and, when the user has accepted it, their
+// progress through its steps.
+func questPayload(quest *store.Quest, userQuest *store.UserQuest) map[string]interface{} {
+	steps, _ := quest.ParseSteps()
+	rewards, _ := quest.ParseRewards()
+
+	payload := map[string]interface{}{
+		"id":              quest.ID,
+		"title":           quest.Title,
+		"description":     quest.Description,
+		"quest_type":      quest.QuestType,
+		"rewards":         rewards,
+		"time_limit_days": quest.TimeLimitDays,
+	}
+
+	var progress store.QuestProgress
+	if userQuest != nil {
+		progress = userQuest.Progress(len(steps))
+	}
+	stepPayloads := make([]map[string]interface{}, len(steps))
+	for i, step := range steps {
+		entry := map[string]interface{}{
+			"step":        i + 1,
+			"type":        step.Type,
+			"target":      step.Count,
+			"campaign_id": step.CampaignID,
+			"task_id":     step.TaskID,
+			"description": step.Description,
+		}
+		if userQuest != nil {
+			entry["count"] = progress.Counts[i]
+			entry["completed"] = i+1 < userQuest.CurrentStep || userQuest.Status == "completed"
+		}
+		stepPayloads[i] = entry
+	}
+	payload["steps"] = stepPayloads
+
+	if userQuest != nil {
+		payload["progress"] = map[string]interface{}{
+			"status":       userQuest.Status,
+			"current_step": userQuest.CurrentStep,
+			"started_at":   userQuest.StartedAt,
+			"expires_at":   userQuest.ExpiresAt,
+			"completed_at": userQuest.CompletedAt,
+		}
+	}
+	return payload
+}
+
+// getQuestFromURL loads the active quest referenced by the {id} URL
+// parameter, writing the error response itself when it cannot.
+func getQuestFromURL(db *gorm.DB, w http.ResponseWriter, r *http.Request) (*store.Quest, bool) {
+	questIDStr := chi.URLParam(r, "id")
+	questID, err := strconv.ParseUint(questIDStr, 10, 32)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid quest ID"))
+		return nil, false
+	}
+
+	quest, err := store.GetQuestByID(db, uint(questID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			notFoundResponse(w, r, errors.New("quest not found"))
+		} else {
+			internalServerError(w, r, err)
+		}
+		return nil, false
+	}
+	return quest, true
+}
+
+// Get Quests
+func getQuestsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		quests, err := store.GetActiveQuests(db)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		userQuests, err := store.GetUserQuests(db, user.ID, "")
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+		byQuest := make(map[int]*store.UserQuest, len(userQuests))
+		for i := range userQuests {
+			if userQuests[i].QuestID != nil {
+				byQuest[*userQuests[i].QuestID] = &userQuests[i]
+			}
+		}
+
+		response := make([]map[string]interface{}, 0, len(quests))
+		for i := range quests {
+			response = append(response, questPayload(&quests[i], byQuest[int(quests[i].ID)]))
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Get My Quests
+func getMyQuestsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		status := r.URL.Query().Get("status")
+		if err := Validate.Var(status, "omitempty,oneof=in_progress completed failed abandoned"); err != nil {
+			badRequestResponse(w, r, errors.New("invalid quest status"))
+			return
+		}
+
+		userQuests, err := store.GetUserQuests(db, user.ID, status)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		response := make([]map[string]interface{}, 0, len(userQuests))
+		for i := range userQuests {
+			if userQuests[i].Quest == nil {
+				continue
+			}
+			response = append(response, questPayload(userQuests[i].Quest, &userQuests[i]))
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Accept Quest
+func acceptQuestHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		quest, ok := getQuestFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		if !quest.IsActive {
+			conflictResponse(w, r, errors.New("quest is no longer available"))
+			return
+		}
+		if _, err := quest.ParseSteps(); err != nil {
+			internalServerError(w, r, fmt.Errorf("quest %d has invalid steps: %w", quest.ID, err))
+			return
+		}
+
+		userQuest, err := store.AcceptQuest(db, user.ID, quest)
+		if err != nil {
+			if errors.Is(err, store.ErrQuestInProgress) || errors.Is(err, store.ErrQuestCompleted) {
+				conflictResponse(w, r, err)
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		if err := jsonResponse(w, http.StatusCreated, questPayload(quest, userQuest)); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Get Quest Progress
+func getQuestProgressHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		quest, ok := getQuestFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		userQuest, err := store.GetUserQuest(db, user.ID, quest.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("you have not accepted this quest"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		if err := jsonResponse(w, http.StatusOK, questPayload(quest, userQuest)); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// recordActivity feeds a domain event to the user's quests and bingo cards
+// and re-checks their achievements. It runs after the event itself has been
+// committed, so failures are logged rather than undoing it.
+func recordActivity(db *gorm.DB, userID uint, event store.QuestEvent) {
+	advanceQuests(db, userID, event)
+	recordBingoEvent(db, userID, store.BingoEvent{Activity: &event})
+	checkAchievements(db, userID)
+}
+
+// advanceQuests advances the user's quests with a domain event and pays the
+// rewards of any it completes.
+func advanceQuests(db *gorm.DB, userID uint, event store.QuestEvent) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		completed, err := store.AdvanceQuests(tx, userID, event, time.Now())
+		if err != nil {
+			return err
+		}
+		for i := range completed {
+			if err := payQuestReward(tx, &completed[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("quests: failed to record %s for user %d: %v", event.Type, userID, err)
+	}
+}
+
+// payQuestReward credits a completed quest's reward and tells the user.
+func payQuestReward(tx *gorm.DB, userQuest *store.UserQuest) error {
+	quest := userQuest.Quest
+	reward, err := quest.ParseRewards()
+	if err != nil {
+		return fmt.Errorf("invalid rewards for quest %d: %w", quest.ID, err)
+	}
+
+	user, err := store.GetUserByID(tx, uint(*userQuest.UserID))
+	if err != nil {
+		return err
+	}
+	description := fmt.Sprintf("Completed quest \"%s\"", quest.Title)
+
+	if reward.XP > 0 {
+		if _, err := store.AwardXP(tx, user.ID, reward.XP, "bonus", "quest", quest.ID, description); err != nil {
+			return err
+		}
+	}
+	if reward.Coins > 0 {
+		if _, err := store.CreditCoins(tx, user.ID, reward.Coins, description, "quest", quest.ID); err != nil {
+			return err
+		}
+	}
+	if reward.BadgeID != nil {
+		if err := awardRewardBadge(tx, user, *reward.BadgeID); err != nil {
+			return err
+		}
+	}
+
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"quest_id": quest.ID,
+		"xp":       reward.XP,
+		"coins":    reward.Coins,
+		"badge_id": reward.BadgeID,
+	})
+	notification := &store.Notification{
+		UserID:           userQuest.UserID,
+		NotificationType: "reward_unlocked",
+		Title:            "Quest complete!",
+		Message:          fmt.Sprintf("You completed \"%s\" and collected your reward.", quest.Title),
+		ActionURL:        stringPtr(fmt.Sprintf("/quests/%d", quest.ID)),
+		Data:             stringPtr(string(dataJSON)),
+	}
+	return store.CreateNotification(tx, notification)
+}
+
+// expireQuestsJob fails quests whose time limit passed before they were
+// completed and tells their users. It runs every few minutes as a recurring
+// job.
+func expireQuestsJob(ctx context.Context, db *gorm.DB, job *store.ScheduledJob) (interface{}, error) {
+	expired, err := store.ExpireQuests(db, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range expired {
+		if expired[i].Quest == nil {
+			continue
+		}
+		notification := &store.Notification{
+			UserID:           expired[i].UserID,
+			NotificationType: "system",
+			Title:            "Quest expired",
+			Message:          fmt.Sprintf("Time ran out on \"%s\". You can accept it again to retry.", expired[i].Quest.Title),
+			ActionURL:        stringPtr(fmt.Sprintf("/quests/%d", expired[i].Quest.ID)),
+		}
+		if err := store.CreateNotification(db, notification); err != nil {
+			log.Printf("quests: failed to notify user about expired quest %d: %v", expired[i].ID, err)
+		}
+	}
+	return map[string]int{"expired": len(expired)}, nil
+}
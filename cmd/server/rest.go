@@ -38,6 +38,7 @@ func setupREST(r chi.Router, db *gorm.DB) {
 		r.Post("/colleges", createOrGetCollegeHandler(db))
 		r.Get("/states", getStatesHandler(db))
 		r.Get("/leaderboards/global", getGlobalLeaderboardHandler(db))
+		r.Get("/certificates/verify/{code}", verifyCertificateHandler(db))
 
 		// Protected routes
 		r.Group(func(r chi.Router) {
@@ -57,6 +58,9 @@ func setupREST(r chi.Router, db *gorm.DB) {
 				r.Get("/{id}/stats", getUserStatsHandler(db))
 			})
 
+			// File downloads
+			r.Get("/files/*", downloadFileHandler(db))
+
 			// Task routes
 			r.Route("/tasks", func(r chi.Router) {
 				r.Get("/", getTasksHandler(db))
@@ -68,15 +72,13 @@ func setupREST(r chi.Router, db *gorm.DB) {
 			// Submission routes
 			r.Route("/submissions", func(r chi.Router) {
 				r.Get("/", getSubmissionsHandler(db))
-				r.Post("/", createSubmissionHandler(db))
+				r.With(RequireVerifiedEmail()).Post("/", createSubmissionHandler(db))
 				r.Get("/{id}", getSubmissionHandler(db))
 				r.Put("/{id}", updateSubmissionHandler(db))
 				r.Delete("/{id}", deleteSubmissionHandler(db))
 				r.Get("/{id}/proof", getSubmissionProofHandler(db))
-				r.Post("/{id}/appeal", func(w http.ResponseWriter, r *http.Request) {
-					// TODO: Implement appeal handler
-					writeJSONError(w, http.StatusNotImplemented, "appeal not yet implemented")
-				})
+				r.Post("/{id}/appeal", appealSubmissionHandler(db))
+				r.Post("/{id}/media", uploadSubmissionMediaHandler(db))
 			})
 
 			// Campaign routes
@@ -109,6 +111,13 @@ func setupREST(r chi.Router, db *gorm.DB) {
 				r.Get("/me", getUserBadgesHandler(db))
 			})
 
+			r.Route("/skins", func(r chi.Router) {
+				r.Get("/", getProfileSkinsHandler(db))
+				r.Post("/unequip", unequipProfileSkinHandler(db))
+				r.Post("/{id}/unlock", unlockProfileSkinHandler(db))
+				r.Post("/{id}/equip", equipProfileSkinHandler(db))
+			})
+
 			r.Route("/streaks", func(r chi.Router) {
 				r.Get("/", getStreakHandler(db))
 				r.Post("/log", logStreakHandler(db))
@@ -150,6 +159,7 @@ func setupREST(r chi.Router, db *gorm.DB) {
 
 			r.Route("/battles", func(r chi.Router) {
 				r.Get("/active", getActiveBattlesHandler(db))
+				r.Get("/{id}/submissions", getBattleSubmissionsHandler(db))
 				r.Post("/{id}/submit", submitBattleHandler(db))
 				r.Post("/{id}/vote/{submissionId}", voteBattleHandler(db))
 			})
@@ -158,7 +168,7 @@ func setupREST(r chi.Router, db *gorm.DB) {
 			r.Route("/rewards", func(r chi.Router) {
 				r.Get("/", getRewardsHandler(db))
 				r.Get("/{id}", getRewardHandler(db))
-				r.Post("/{id}/redeem", redeemRewardHandler(db))
+				r.With(RequireVerifiedEmail()).Post("/{id}/redeem", redeemRewardHandler(db))
 				r.Get("/redemptions", getRewardRedemptionsHandler(db))
 			})
 
@@ -181,6 +191,20 @@ func setupREST(r chi.Router, db *gorm.DB) {
 				r.Get("/{id}/leaderboard", getStateLeaderboardHandler(db))
 			})
 
+			// Quest routes
+			r.Route("/quests", func(r chi.Router) {
+				r.Get("/", getQuestsHandler(db))
+				r.Get("/me", getMyQuestsHandler(db))
+				r.Post("/{id}/accept", acceptQuestHandler(db))
+				r.Get("/{id}/progress", getQuestProgressHandler(db))
+			})
+
+			// Badge bingo routes
+			r.Route("/bingo", func(r chi.Router) {
+				r.Get("/active", getActiveBingosHandler(db))
+				r.Get("/{id}", getBingoHandler(db))
+			})
+
 			// Campus Wars routes
 			r.Route("/wars", func(r chi.Router) {
 				r.Get("/active", getActiveWarsHandler(db))
@@ -238,6 +262,9 @@ func setupREST(r chi.Router, db *gorm.DB) {
 				r.Get("/quick-stats", getUserDashboardStatsHandler(db))
 			})
 
+			// WebSocket tickets for browsers
+			r.Post("/ws/ticket", createWSTicketHandler(db))
+
 			// Email preferences
 			r.Route("/email", func(r chi.Router) {
 				r.Get("/preferences", getEmailPreferencesHandler(db))
@@ -264,28 +291,17 @@ func setupREST(r chi.Router, db *gorm.DB) {
 
 			// Task management
 			r.Route("/tasks", func(r chi.Router) {
-				r.Post("/", func(w http.ResponseWriter, r *http.Request) {
-					writeJSONError(w, http.StatusNotImplemented, "create task not yet implemented")
-				})
-				r.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
-					writeJSONError(w, http.StatusNotImplemented, "update task not yet implemented")
-				})
-				r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
-					writeJSONError(w, http.StatusNotImplemented, "delete task not yet implemented")
-				})
+				r.Post("/", adminCreateTaskHandler(db))
+				r.Put("/{id}", adminUpdateTaskHandler(db))
+				r.Delete("/{id}", adminDeleteTaskHandler(db))
 			})
 
 			// Campaign management
 			r.Route("/campaigns", func(r chi.Router) {
-				r.Post("/", func(w http.ResponseWriter, r *http.Request) {
-					writeJSONError(w, http.StatusNotImplemented, "create campaign not yet implemented")
-				})
-				r.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
-					writeJSONError(w, http.StatusNotImplemented, "update campaign not yet implemented")
-				})
-				r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
-					writeJSONError(w, http.StatusNotImplemented, "delete campaign not yet implemented")
-				})
+				r.Post("/", adminCreateCampaignHandler(db))
+				r.Put("/{id}", adminUpdateCampaignHandler(db))
+				r.Post("/{id}/status", adminTransitionCampaignHandler(db))
+				r.Delete("/{id}", adminDeleteCampaignHandler(db))
 			})
 
 			// Submission review
@@ -293,6 +309,8 @@ func setupREST(r chi.Router, db *gorm.DB) {
 				r.Get("/pending", adminGetPendingSubmissionsHandler(db))
 				r.Get("/stats", adminGetSubmissionStatsHandler(db))
 				r.Post("/{id}/review", adminReviewSubmissionHandler(db))
+				r.Get("/appeals", adminGetSubmissionAppealsHandler(db))
+				r.Post("/appeals/{id}/resolve", adminResolveSubmissionAppealHandler(db))
 			})
 
 			// Gamification management
@@ -305,6 +323,33 @@ func setupREST(r chi.Router, db *gorm.DB) {
 				r.Post("/award", adminAwardBadgeHandler(db))
 			})
 
+			r.Route("/trivia", func(r chi.Router) {
+				r.Get("/{id}/question-stats", adminGetTriviaQuestionStatsHandler(db))
+			})
+
+			// Campus war management
+			r.Route("/wars", func(r chi.Router) {
+				r.Post("/", adminCreateWarHandler(db))
+				r.Put("/{id}", adminUpdateWarHandler(db))
+				r.Post("/{id}/participants", adminEnrollWarParticipantsHandler(db))
+				r.Post("/{id}/cancel", adminCancelWarHandler(db))
+				r.Post("/{id}/extend", adminExtendWarHandler(db))
+			})
+
+			// Badge bingo management
+			r.Route("/bingo", func(r chi.Router) {
+				r.Get("/", adminGetBingosHandler(db))
+				r.Post("/", adminCreateBingoHandler(db))
+				r.Put("/{id}", adminUpdateBingoHandler(db))
+			})
+
+			// Background jobs
+			r.Route("/jobs", func(r chi.Router) {
+				r.Get("/", adminGetJobsHandler(db))
+				r.Post("/{id}/cancel", adminCancelJobHandler(db))
+				r.Post("/{id}/rerun", adminRerunJobHandler(db))
+			})
+
 			// Dashboard & Analytics
 			r.Route("/dashboard", func(r chi.Router) {
 				r.Get("/", adminDashboardHandler(db))
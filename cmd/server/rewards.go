@@ -188,6 +188,33 @@ func redeemRewardHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
+		// Profile skin rewards grant their skin, which can only be owned once
+		var skin *store.ProfileSkin
+		if reward.RewardType == "profile_skin" {
+			if reward.ProfileSkinID == nil {
+				badRequestResponse(w, r, errors.New("reward is not available"))
+				return
+			}
+			skin, err = store.GetProfileSkinByID(db, uint(*reward.ProfileSkinID))
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					badRequestResponse(w, r, errors.New("reward is not available"))
+				} else {
+					internalServerError(w, r, err)
+				}
+				return
+			}
+			owned, err := store.HasProfileSkin(db, dbUser.ID, skin.ID)
+			if err != nil {
+				internalServerError(w, r, err)
+				return
+			}
+			if owned {
+				conflictResponse(w, r, store.ErrSkinAlreadyOwned)
+				return
+			}
+		}
+
 		// Check if user has already redeemed this reward (if it's limited per user)
 		// For now, we'll allow multiple redemptions unless specified in metadata
 		var existingRedemptions int64
@@ -262,18 +289,29 @@ func redeemRewardHandler(db *gorm.DB) http.HandlerFunc {
 			redemption.ShippingAddress = stringPtr(string(shippingAddrJSON))
 		}
 
+		// Skins are delivered as soon as they are granted
+		if skin != nil {
+			now := time.Now()
+			redemption.Status = "delivered"
+			redemption.DeliveredAt = &now
+		}
+
 		if err := tx.Create(redemption).Error; err != nil {
 			tx.Rollback()
 			internalServerError(w, r, err)
 			return
 		}
 
-		// Deduct XP from user
-		dbUser.XP = dbUser.XP - reward.XPCost
-		if err := tx.Save(dbUser).Error; err != nil {
-			tx.Rollback()
-			internalServerError(w, r, err)
-			return
+		if skin != nil {
+			if err := grantProfileSkin(tx, dbUser, skin, "reward"); err != nil {
+				tx.Rollback()
+				if errors.Is(err, store.ErrSkinAlreadyOwned) {
+					conflictResponse(w, r, err)
+				} else {
+					internalServerError(w, r, err)
+				}
+				return
+			}
 		}
 
 		// Update reward quantity
@@ -284,7 +322,7 @@ func redeemRewardHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Create XP transaction
+		// Deduct XP from user
 		metadataJSON, err := json.Marshal(map[string]interface{}{
 			"reward_id":     reward.ID,
 			"reward_name":   reward.Name,
@@ -295,21 +333,18 @@ func redeemRewardHandler(db *gorm.DB) http.HandlerFunc {
 			internalServerError(w, r, err)
 			return
 		}
-		xpTransaction := &store.XPTransaction{
-			UserID:          intPtr(int(dbUser.ID)),
-			TransactionType: "redemption",
-			Amount:          -reward.XPCost,
-			SourceType:      stringPtr("reward"),
-			SourceID:        intPtr(int(redemption.ID)),
-			Description:     stringPtr("Reward redemption: " + reward.Name),
-			Metadata:        stringPtr(string(metadataJSON)),
+		xpTransaction, err := store.AwardXP(tx, dbUser.ID, -reward.XPCost, "redemption", "reward", redemption.ID, "Reward redemption: "+reward.Name)
+		if err != nil {
+			tx.Rollback()
+			internalServerError(w, r, err)
+			return
 		}
-
-		if err := tx.Create(xpTransaction).Error; err != nil {
+		if err := tx.Model(xpTransaction).Update("metadata", string(metadataJSON)).Error; err != nil {
 			tx.Rollback()
 			internalServerError(w, r, err)
 			return
 		}
+		dbUser.XP = xpTransaction.BalanceAfter
 
 		// Commit transaction
 		if err := tx.Commit().Error; err != nil {
@@ -365,6 +400,11 @@ func redeemRewardHandler(db *gorm.DB) http.HandlerFunc {
 			responseData["message"] = "Reward redeemed successfully. Your redemption code: " + *redemption.RedemptionCode
 		}
 
+		// Add the granted skin for profile skin rewards
+		if skin != nil {
+			responseData["redemption"].(map[string]interface{})["profile_skin"] = skin
+		}
+
 		// Add shipping info for physical rewards
 		if reward.RewardType == "physical" {
 			responseData["redemption"].(map[string]interface{})["shipping_info"] = map[string]interface{}{
@@ -636,29 +676,14 @@ func cancelRedemptionHandler(db *gorm.DB) http.HandlerFunc {
 		}
 
 		// Refund XP to user
-		dbUser, _ := store.GetUserByID(tx, user.ID)
-		dbUser.XP += redemption.XPPaid
-		if err := tx.Save(dbUser).Error; err != nil {
-			tx.Rollback()
-			internalServerError(w, r, err)
-			return
-		}
-
-		// Create XP transaction for refund
-		xpTransaction := &store.XPTransaction{
-			UserID:          intPtr(int(dbUser.ID)),
-			TransactionType: "redemption_refund",
-			Amount:          redemption.XPPaid,
-			SourceType:      stringPtr("reward"),
-			SourceID:        intPtr(int(redemption.ID)),
-			Description:     stringPtr("Redemption cancelled - XP refunded"),
-		}
-
-		if err := tx.Create(xpTransaction).Error; err != nil {
+		xpTransaction, err := store.AwardXP(tx, user.ID, redemption.XPPaid, "redemption", "reward", redemption.ID, "Redemption cancelled - XP refunded")
+		if err != nil {
 			tx.Rollback()
 			internalServerError(w, r, err)
 			return
 		}
+		dbUser := user
+		dbUser.XP = xpTransaction.BalanceAfter
 
 		// Update reward quantity
 		var reward store.RewardStore
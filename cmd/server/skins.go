@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// skinUnlockError explains why the user cannot unlock a skin themselves, or
+// returns nil if they can. Purchase and special skins only come from the
+// reward store or admins.
+func skinUnlockError(db *gorm.DB, user *store.User, skin *store.ProfileSkin) error {
+	switch skin.UnlockMethod {
+	case store.SkinUnlockXP:
+		if user.XP < skin.XPCost {
+			return fmt.Errorf("%w: requires %d XP", store.ErrSkinLocked, skin.XPCost)
+		}
+	case store.SkinUnlockLevel:
+		if skin.RequiredLevelID == nil {
+			return fmt.Errorf("%w: no level requirement is set", store.ErrSkinLocked)
+		}
+		reached, err := store.HasReachedLevel(db, user, uint(*skin.RequiredLevelID))
+		if err != nil {
+			return err
+		}
+		if !reached {
+			name := "a higher level"
+			if level, err := store.GetLevelByID(db, uint(*skin.RequiredLevelID)); err == nil {
+				name = level.Name
+			}
+			return fmt.Errorf("%w: requires reaching %s", store.ErrSkinLocked, name)
+		}
+	case store.SkinUnlockCampaign:
+		if skin.CampaignID == nil {
+			return fmt.Errorf("%w: no campaign requirement is set", store.ErrSkinLocked)
+		}
+		completed, err := store.HasCompletedCampaign(db, user.ID, uint(*skin.CampaignID))
+		if err != nil {
+			return err
+		}
+		if !completed {
+			return fmt.Errorf("%w: requires completing campaign %d", store.ErrSkinLocked, *skin.CampaignID)
+		}
+	default:
+		return fmt.Errorf("%w: available from the reward store or events only", store.ErrSkinLocked)
+	}
+	return nil
+}
+
+// grantProfileSkin unlocks a skin for the user and tells them. Direct unlocks
+// and reward store redemptions both go through it.
+func grantProfileSkin(tx *gorm.DB, user *store.User, skin *store.ProfileSkin, via string) error {
+	granted, err := store.GrantProfileSkin(tx, user.ID, skin.ID, via)
+	if err != nil {
+		return err
+	}
+	if !granted {
+		return store.ErrSkinAlreadyOwned
+	}
+
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"skin_id":      skin.ID,
+		"unlocked_via": via,
+	})
+	userID := int(user.ID)
+	notification := &store.Notification{
+		UserID:           &userID,
+		NotificationType: "reward_unlocked",
+		Title:            "New profile skin!",
+		Message:          fmt.Sprintf("You unlocked the \"%s\" profile skin. Equip it from your profile.", skin.Name),
+		ActionURL:        stringPtr("/skins"),
+		Data:             stringPtr(string(dataJSON)),
+	}
+	return store.CreateNotification(tx, notification)
+}
+
+// getSkinFromURL loads the active skin referenced by the {id} URL parameter,
+// writing the error response itself when it cannot.
+func getSkinFromURL(db *gorm.DB, w http.ResponseWriter, r *http.Request) (*store.ProfileSkin, bool) {
+	skinIDStr := chi.URLParam(r, "id")
+	skinID, err := strconv.ParseUint(skinIDStr, 10, 32)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid skin ID"))
+		return nil, false
+	}
+
+	skin, err := store.GetProfileSkinByID(db, uint(skinID))
+	if err != nil || !skin.IsActive {
+		if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+			notFoundResponse(w, r, errors.New("profile skin not found"))
+		} else {
+			internalServerError(w, r, err)
+		}
+		return nil, false
+	}
+	return skin, true
+}
+
+// Get Profile Skins
+func getProfileSkinsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		dbUser, err := store.GetUserByID(db, user.ID)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		skins, err := store.GetActiveProfileSkins(db)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		owned, err := store.GetUserProfileSkins(db, user.ID)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+		ownedBySkin := make(map[uint]*store.UserProfileSkin, len(owned))
+		for i := range owned {
+			ownedBySkin[owned[i].SkinID] = &owned[i]
+		}
+
+		response := make([]map[string]interface{}, 0, len(skins))
+		for i := range skins {
+			skin := &skins[i]
+			entry := map[string]interface{}{
+				"skin":     skin,
+				"unlocked": false,
+				"equipped": dbUser.ProfileSkinID != nil && uint(*dbUser.ProfileSkinID) == skin.ID,
+			}
+			if unlocked, ok := ownedBySkin[skin.ID]; ok {
+				entry["unlocked"] = true
+				entry["unlocked_via"] = unlocked.UnlockedVia
+				entry["unlocked_at"] = unlocked.UnlockedAt
+			} else {
+				lockErr := skinUnlockError(db, dbUser, skin)
+				if lockErr != nil && !errors.Is(lockErr, store.ErrSkinLocked) {
+					internalServerError(w, r, lockErr)
+					return
+				}
+				entry["can_unlock"] = lockErr == nil
+				if lockErr != nil {
+					entry["locked_reason"] = lockErr.Error()
+				}
+			}
+			response = append(response, entry)
+		}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Unlock Profile Skin
+func unlockProfileSkinHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		skin, ok := getSkinFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		var remainingXP int
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Lock the user so XP cannot be spent twice
+			var dbUser store.User
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dbUser, user.ID).Error; err != nil {
+				return err
+			}
+			owned, err := store.HasProfileSkin(tx, dbUser.ID, skin.ID)
+			if err != nil {
+				return err
+			}
+			if owned {
+				return store.ErrSkinAlreadyOwned
+			}
+			if err := skinUnlockError(tx, &dbUser, skin); err != nil {
+				return err
+			}
+
+			remainingXP = dbUser.XP
+			if skin.UnlockMethod == store.SkinUnlockXP && skin.XPCost > 0 {
+				transaction, err := store.AwardXP(tx, dbUser.ID, -skin.XPCost, "redemption", "profile_skin", skin.ID, fmt.Sprintf("Unlocked the \"%s\" profile skin", skin.Name))
+				if err != nil {
+					return err
+				}
+				remainingXP = transaction.BalanceAfter
+			}
+			return grantProfileSkin(tx, &dbUser, skin, skin.UnlockMethod)
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrSkinAlreadyOwned):
+				conflictResponse(w, r, err)
+			case errors.Is(err, store.ErrSkinLocked):
+				badRequestResponse(w, r, err)
+			default:
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		response := map[string]interface{}{
+			"skin":         skin,
+			"unlocked_via": skin.UnlockMethod,
+			"remaining_xp": remainingXP,
+		}
+		if err := jsonResponse(w, http.StatusCreated, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Equip Profile Skin
+func equipProfileSkinHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		skin, ok := getSkinFromURL(db, w, r)
+		if !ok {
+			return
+		}
+
+		if err := store.EquipProfileSkin(db, user.ID, &skin.ID); err != nil {
+			if errors.Is(err, store.ErrSkinNotOwned) {
+				badRequestResponse(w, r, err)
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		if err := jsonResponse(w, http.StatusOK, map[string]interface{}{"profile_skin": skin}); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Unequip Profile Skin
+func unequipProfileSkinHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		if err := store.EquipProfileSkin(db, user.ID, nil); err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		if err := jsonResponse(w, http.StatusOK, map[string]interface{}{"profile_skin": nil}); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
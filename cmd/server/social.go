@@ -80,6 +80,7 @@ func createPostHandler(db *gorm.DB) http.HandlerFunc {
 			writeJSONError(w, http.StatusInternalServerError, "failed to create post")
 			return
 		}
+		checkAchievements(db, user.ID)
 
 		writeJSON(w, http.StatusCreated, post)
 	}
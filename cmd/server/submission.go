@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
@@ -11,6 +12,10 @@ import (
 	"gorm.io/gorm"
 )
 
+// defaultRevisionWindow is how long a user has to resubmit when a reviewer
+// requests a revision without giving a deadline
+const defaultRevisionWindow = 48 * time.Hour
+
 // Submission Response
 type SubmissionResponse struct {
 	ID             uint       `json:"id"`
@@ -164,6 +169,23 @@ func createSubmissionHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
+		// Submissions are only accepted while the campaign is running
+		if task.CampaignID != nil {
+			campaign, err := store.GetCampaignByID(db, uint(*task.CampaignID))
+			if err != nil {
+				internalServerError(w, r, err)
+				return
+			}
+			if campaign.Status == "paused" {
+				badRequestResponse(w, r, errors.New("campaign is paused"))
+				return
+			}
+			if campaign.Status != "active" {
+				badRequestResponse(w, r, errors.New("campaign is not active"))
+				return
+			}
+		}
+
 		// Check if user has already submitted
 		existingSubmissions, err := store.GetSubmissionsByUserAndTask(db, user.ID, req.TaskID)
 		if err == nil && len(existingSubmissions) > 0 {
@@ -251,7 +273,8 @@ func getSubmissionHandler(db *gorm.DB) http.HandlerFunc {
 		}
 
 		// Check if user owns the submission or is admin
-		if submission.UserID != intPtr(int(user.ID)) && user.Role != "admin" && user.Role != "state_lead" {
+		isOwner := submission.UserID != nil && uint(*submission.UserID) == user.ID
+		if !isOwner && user.Role != "admin" && user.Role != "state_lead" {
 			unauthorizedResponse(w, r, errors.New("not authorized to view this submission"))
 			return
 		}
@@ -262,7 +285,103 @@ func getSubmissionHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		if err := jsonResponse(w, http.StatusOK, submission); err != nil {
+		// Earlier proofs replaced by resubmissions
+		revisions, err := store.GetSubmissionRevisions(db, submission.ID)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		media, err := store.GetSubmissionMediaBySubmission(db, submission.ID)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		// Keep the submission's own fields at the top level for existing clients
+		response := struct {
+			*store.Submission
+			Revisions []store.SubmissionRevision `json:"revisions"`
+			Media     []store.SubmissionMedia    `json:"media"`
+		}{submission, revisions, media}
+
+		if err := jsonResponse(w, http.StatusOK, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// Upload Submission Media
+func uploadSubmissionMediaHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		submissionIDStr := chi.URLParam(r, "id")
+		submissionID, err := strconv.ParseUint(submissionIDStr, 10, 32)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("invalid submission ID"))
+			return
+		}
+
+		submission, err := store.GetSubmissionByID(db, uint(submissionID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				notFoundResponse(w, r, errors.New("submission not found"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		// Check if user owns the submission
+		if submission.UserID == nil || uint(*submission.UserID) != user.ID {
+			writeJSONError(w, http.StatusForbidden, "not authorized to update this submission")
+			return
+		}
+
+		if submission.Status != "draft" && submission.Status != "pending" && submission.Status != "needs_revision" {
+			badRequestResponse(w, r, errors.New("media can only be added to draft, pending or needs_revision submissions"))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxSubmissionMediaSize+(1<<20))
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+
+		uploaded, thumbnail, err := saveMediaUpload(db, r, user, "file", "submission", maxSubmissionMediaSize, submissionMediaTypes)
+		if err != nil {
+			if isUploadError(err) {
+				badRequestResponse(w, r, errors.New("only JPEG/PNG/GIF images, MP4/WebM/MOV videos and PDFs up to 50 MB are allowed"))
+			} else {
+				internalServerError(w, r, err)
+			}
+			return
+		}
+
+		subID := int(submission.ID)
+		size := int(uploaded.Size)
+		media := &store.SubmissionMedia{
+			SubmissionID: &subID,
+			MediaType:    mediaTypeForContent(uploaded.ContentType),
+			MediaURL:     fileURL(uploaded.StorageKey),
+			FileName:     uploaded.OriginalName,
+			FileSize:     &size,
+		}
+		if thumbnail != nil {
+			media.ThumbnailURL = stringPtr(fileURL(thumbnail.StorageKey))
+		}
+		if err := store.CreateSubmissionMedia(db, media); err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		if err := jsonResponse(w, http.StatusCreated, media); err != nil {
 			internalServerError(w, r, err)
 		}
 	}
@@ -295,7 +414,7 @@ func updateSubmissionHandler(db *gorm.DB) http.HandlerFunc {
 		}
 
 		// Check if user owns the submission
-		if submission.UserID != intPtr(int(user.ID)) {
+		if submission.UserID == nil || uint(*submission.UserID) != user.ID {
 			unauthorizedResponse(w, r, errors.New("not authorized to update this submission"))
 			return
 		}
@@ -316,22 +435,42 @@ func updateSubmissionHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Update fields
+		proofURL := submission.ProofURL
 		if req.ProofURL != "" {
-			submission.ProofURL = req.ProofURL
+			proofURL = req.ProofURL
 		}
+		proofText := submission.ProofText
 		if req.ProofText != "" {
-			submission.ProofText = &req.ProofText
+			proofText = &req.ProofText
 		}
 
-		submission.Status = "pending"
-		submission.SubmissionStage = "resubmission"
-		submission.SubmittedAt = time.Now()
-		submission.UpdatedAt = time.Now()
+		if submission.Status == "needs_revision" {
+			// Check if revision deadline has passed
+			if submission.RevisionDeadline != nil && time.Now().After(*submission.RevisionDeadline) {
+				badRequestResponse(w, r, errors.New("revision deadline has passed"))
+				return
+			}
 
-		if err := store.UpdateSubmission(db, submission); err != nil {
-			internalServerError(w, r, err)
-			return
+			// The previous proof is kept as a revision
+			if err := store.ResubmitSubmission(db, submission, proofURL, proofText); err != nil {
+				if errors.Is(err, store.ErrSubmissionNotRevisable) {
+					badRequestResponse(w, r, err)
+				} else {
+					internalServerError(w, r, err)
+				}
+				return
+			}
+		} else {
+			submission.ProofURL = proofURL
+			submission.ProofText = proofText
+			submission.Status = "pending"
+			submission.SubmittedAt = time.Now()
+			submission.UpdatedAt = time.Now()
+
+			if err := store.UpdateSubmission(db, submission); err != nil {
+				internalServerError(w, r, err)
+				return
+			}
 		}
 
 		response := map[string]interface{}{
@@ -435,3 +574,16 @@ func getSubmissionProofHandler(db *gorm.DB) http.HandlerFunc {
 		}
 	}
 }
+
+// expireRevisionsJob rejects submissions whose revision deadline passed
+// without a resubmission. It runs every few minutes as a recurring job.
+func expireRevisionsJob(ctx context.Context, db *gorm.DB, job *store.ScheduledJob) (interface{}, error) {
+	expired, err := store.ExpireSubmissionRevisions(db, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range expired {
+		notifySubmissionReviewed(db, &expired[i])
+	}
+	return map[string]int{"rejected": len(expired)}, nil
+}
@@ -292,21 +292,6 @@ func submitSurveyHandler(db *gorm.DB) http.HandlerFunc {
 
 		// Award XP to user if reward > 0
 		if survey.XPReward > 0 {
-			dbUser, err := store.GetUserByID(tx, user.ID)
-			if err != nil {
-				tx.Rollback()
-				internalServerError(w, r, err)
-				return
-			}
-
-			dbUser.XP += survey.XPReward
-			if err := tx.Save(dbUser).Error; err != nil {
-				tx.Rollback()
-				internalServerError(w, r, err)
-				return
-			}
-
-			// Create XP transaction
 			metadataJSON, err := json.Marshal(map[string]interface{}{
 				"survey_id":          survey.ID,
 				"survey_title":       survey.Title,
@@ -318,17 +303,14 @@ func submitSurveyHandler(db *gorm.DB) http.HandlerFunc {
 				return
 			}
 
-			xpTransaction := &store.XPTransaction{
-				UserID:          intPtr(int(user.ID)),
-				TransactionType: "quiz", // Using quiz type for survey XP
-				Amount:          survey.XPReward,
-				SourceType:      stringPtr("survey"),
-				SourceID:        intPtr(int(surveyResponse.ID)),
-				Description:     stringPtr("Survey completion: " + survey.Title),
-				Metadata:        stringPtr(string(metadataJSON)),
+			// Using quiz type for survey XP
+			xpTransaction, err := store.AwardXP(tx, user.ID, survey.XPReward, "quiz", "survey", surveyResponse.ID, "Survey completion: "+survey.Title)
+			if err != nil {
+				tx.Rollback()
+				internalServerError(w, r, err)
+				return
 			}
-
-			if err := store.CreateXPTransaction(tx, xpTransaction); err != nil {
+			if err := tx.Model(xpTransaction).Update("metadata", string(metadataJSON)).Error; err != nil {
 				tx.Rollback()
 				internalServerError(w, r, err)
 				return
@@ -213,7 +213,7 @@ func getAssignedTasksHandler(db *gorm.DB) http.HandlerFunc {
 		}
 
 		// Get tasks assigned to user
-		assignments, err := store.GetTaskAssignmentsByUser(db, user.ID)
+		assignments, err := store.GetTaskAssignmentsByUser(db, user)
 		if err != nil {
 			internalServerError(w, r, err)
 			return
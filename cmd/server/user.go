@@ -54,18 +54,19 @@ func getCurrentUserProfileHandler(db *gorm.DB) http.HandlerFunc {
 
 		response := map[string]interface{}{
 			"user": map[string]interface{}{
-				"id":         fullUser.ID,
-				"email":      fullUser.Email,
-				"first_name": fullUser.FirstName,
-				"last_name":  fullUser.LastName,
-				"phone":      fullUser.Phone,
-				"role":       fullUser.Role,
-				"xp":         fullUser.XP,
-				"college_id": fullUser.CollegeID,
-				"state_id":   fullUser.StateID,
-				"avatar_url": fullUser.AvatarURL,
-				"resume_url": fullUser.ResumeURL,
-				"created_at": fullUser.CreatedAt,
+				"id":           fullUser.ID,
+				"email":        fullUser.Email,
+				"first_name":   fullUser.FirstName,
+				"last_name":    fullUser.LastName,
+				"phone":        fullUser.Phone,
+				"role":         fullUser.Role,
+				"xp":           fullUser.XP,
+				"college_id":   fullUser.CollegeID,
+				"state_id":     fullUser.StateID,
+				"avatar_url":   fullUser.AvatarURL,
+				"resume_url":   fullUser.ResumeURL,
+				"profile_skin": fullUser.ProfileSkin,
+				"created_at":   fullUser.CreatedAt,
 			},
 			"stats": map[string]interface{}{
 				"level":                level.Name,
@@ -144,40 +145,23 @@ func updateAvatarHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		// Parse multipart form
-		err := r.ParseMultipartForm(10 << 20) // 10 MB max
-		if err != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, maxAvatarSize+(1<<20))
+		if err := r.ParseMultipartForm(maxAvatarSize); err != nil {
 			badRequestResponse(w, r, err)
 			return
 		}
 
-		file, handler, err := r.FormFile("avatar")
+		uploaded, err := saveAvatarUpload(db, r, user)
 		if err != nil {
-			badRequestResponse(w, r, errors.New("avatar file is required"))
-			return
-		}
-		defer file.Close()
-
-		// Validate file type
-		allowedTypes := map[string]bool{
-			"image/jpeg": true,
-			"image/png":  true,
-			"image/gif":  true,
-		}
-		if !allowedTypes[handler.Header.Get("Content-Type")] {
-			badRequestResponse(w, r, errors.New("only JPEG, PNG, and GIF images are allowed"))
+			if isUploadError(err) {
+				badRequestResponse(w, r, errors.New("only JPEG, PNG, and GIF images up to 5 MB are allowed"))
+			} else {
+				internalServerError(w, r, err)
+			}
 			return
 		}
 
-		// Upload to storage (S3, local, etc.)
-		// For now, we'll just store the filename
-		avatarURL := "/uploads/avatars/" + handler.Filename
-
-		// In production, you would:
-		// 1. Upload to S3/cloud storage
-		// 2. Generate unique filename
-		// 3. Create thumbnails
-
+		avatarURL := fileURL(uploaded.StorageKey)
 		user.AvatarURL = &avatarURL
 		if err := store.UpdateUser(db, user); err != nil {
 			internalServerError(w, r, err)
@@ -204,33 +188,23 @@ func uploadResumeHandler(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
-		err := r.ParseMultipartForm(10 << 20) // 10 MB max
-		if err != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, maxResumeSize+(1<<20))
+		if err := r.ParseMultipartForm(maxResumeSize); err != nil {
 			badRequestResponse(w, r, err)
 			return
 		}
 
-		file, handler, err := r.FormFile("resume")
+		uploaded, err := saveUpload(db, r, user, "resume", "resume", maxResumeSize, resumeTypes)
 		if err != nil {
-			badRequestResponse(w, r, errors.New("resume file is required"))
-			return
-		}
-		defer file.Close()
-
-		// Validate file type
-		allowedTypes := map[string]bool{
-			"application/pdf":    true,
-			"application/msword": true,
-			"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
-		}
-		if !allowedTypes[handler.Header.Get("Content-Type")] {
-			badRequestResponse(w, r, errors.New("only PDF and DOC/DOCX files are allowed"))
+			if isUploadError(err) {
+				badRequestResponse(w, r, errors.New("only PDF and DOC/DOCX files up to 10 MB are allowed"))
+			} else {
+				internalServerError(w, r, err)
+			}
 			return
 		}
 
-		// Upload to storage
-		resumeURL := "/uploads/resumes/" + handler.Filename
-
+		resumeURL := fileURL(uploaded.StorageKey)
 		user.ResumeURL = &resumeURL
 		if err := store.UpdateUser(db, user); err != nil {
 			internalServerError(w, r, err)
@@ -296,13 +270,20 @@ func downloadCertificateHandler(db *gorm.DB) http.HandlerFunc {
 		}
 
 		// Check if certificate belongs to user
-		if certificate.UserID != intPtr(int(user.ID)) {
-			unauthorizedResponse(w, r, errors.New("not authorized to download this certificate"))
+		if certificate.UserID == nil || uint(*certificate.UserID) != user.ID {
+			writeJSONError(w, http.StatusForbidden, "not authorized to download this certificate")
+			return
+		}
+
+		pdfBytes, err := renderCertificate(db, certificate)
+		if err != nil {
+			internalServerError(w, r, err)
 			return
 		}
 
-		// Serve file
-		// In production, you would serve from S3 or storage
-		http.ServeFile(w, r, certificate.CertificateURL)
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `attachment; filename="certificate-`+certificate.VerificationCode+`.pdf"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write(pdfBytes)
 	}
 }
@@ -126,18 +126,32 @@ func getGlobalLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 			}
 		}
 
-		var users []store.User
-		if err := db.Where("is_active = ?", true).
-			Order("xp DESC").
-			Limit(limit).
-			Find(&users).Error; err != nil {
+		// all ranks total XP; weekly and monthly rank XP earned in the period
+		board := store.BoardGlobal
+		switch r.URL.Query().Get("period") {
+		case "", "all":
+		case "weekly":
+			board = store.BoardWeekly
+		case "monthly":
+			board = store.BoardMonthly
+		default:
+			writeJSONError(w, http.StatusBadRequest, "period must be all, weekly or monthly")
+			return
+		}
+
+		asOf, err := parseAsOf(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		payload, err := leaderboardSnapshotPayload(db, board, store.LeaderboardName(board, 0), asOf, limit)
+		if err != nil {
 			writeJSONError(w, http.StatusInternalServerError, "failed to fetch leaderboard")
 			return
 		}
 
-		writeJSON(w, http.StatusOK, map[string]interface{}{
-			"leaderboard": users,
-		})
+		writeJSON(w, http.StatusOK, payload)
 	}
 }
 
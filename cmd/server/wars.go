@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -229,7 +230,7 @@ func getWarParticipantsHandler(db *gorm.DB) http.HandlerFunc {
 		// Get participants
 		var participants []store.WarParticipant
 		result := query.
-			Order("rank ASC NULLS LAST, total_xp DESC, total_submissions DESC").
+			Order("rank ASC NULLS LAST, score DESC, id ASC").
 			Offset(offset).
 			Limit(limit).
 			Find(&participants)
@@ -249,6 +250,7 @@ func getWarParticipantsHandler(db *gorm.DB) http.HandlerFunc {
 				"total_xp":          participant.TotalXP,
 				"total_submissions": participant.TotalSubmissions,
 				"total_referrals":   participant.TotalReferrals,
+				"score":             participant.Score,
 				"rank":              participant.Rank,
 				"created_at":        participant.CreatedAt,
 			}
@@ -344,7 +346,7 @@ func getWarLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 		// Get participants ordered by rank and metrics
 		var participants []store.WarParticipant
 		result := query.
-			Order("rank ASC NULLS LAST, total_xp DESC, total_submissions DESC, total_referrals DESC").
+			Order("rank ASC NULLS LAST, score DESC, id ASC").
 			Limit(limit).
 			Find(&participants)
 
@@ -364,7 +366,7 @@ func getWarLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 				"total_xp":          participant.TotalXP,
 				"total_submissions": participant.TotalSubmissions,
 				"total_referrals":   participant.TotalReferrals,
-				"total_score":       participant.TotalXP + (participant.TotalSubmissions * 10) + (participant.TotalReferrals * 50), // Simple scoring
+				"total_score":       participant.Score,
 			}
 
 			// Get entity details
@@ -392,10 +394,10 @@ func getWarLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 			leaderboard = append(leaderboard, leaderboardData)
 		}
 
-		// Parse metrics to determine scoring
-		var metrics map[string]interface{}
-		if war.Metrics != nil {
-			json.Unmarshal([]byte(*war.Metrics), &metrics)
+		// Effective weights used for total_score
+		metrics, err := war.ParseMetrics()
+		if err != nil {
+			metrics = store.DefaultWarMetrics
 		}
 
 		response := map[string]interface{}{
@@ -416,3 +418,86 @@ func getWarLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 		}
 	}
 }
+
+// settleCampusWar scores a war that has ended for the last time and pays the
+// configured place rewards to every member of each placed college or state
+// who earned XP during the war. Participants that scored nothing are not
+// placed.
+func settleCampusWar(tx *gorm.DB, war *store.CampusWar) error {
+	rewards, err := war.ParseRewards()
+	if err != nil {
+		return fmt.Errorf("invalid rewards for war %d: %w", war.ID, err)
+	}
+
+	participants, err := store.SettleCampusWar(tx, war)
+	if err != nil {
+		return err
+	}
+
+	for i := range participants {
+		participant := &participants[i]
+		rank := *participant.Rank
+		if participant.Score == 0 || rank > len(rewards.Places) {
+			break
+		}
+
+		userIDs, err := store.GetWarContributors(tx, war, participant)
+		if err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			if err := payWarReward(tx, war, participant, userID, rewards.Places[rank-1]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// payWarReward credits one contributor of a placed participant and tells
+// them.
+func payWarReward(tx *gorm.DB, war *store.CampusWar, participant *store.WarParticipant, userID uint, reward store.Reward) error {
+	user, err := store.GetUserByID(tx, userID)
+	if err != nil {
+		return err
+	}
+	rank := *participant.Rank
+	description := fmt.Sprintf("Your %s placed #%d in \"%s\"", participant.EntityType, rank, war.Name)
+
+	if reward.XP > 0 {
+		if _, err := store.AwardXP(tx, user.ID, reward.XP, "bonus", "campus_war", war.ID, description); err != nil {
+			return err
+		}
+	}
+	if reward.Coins > 0 {
+		if _, err := store.CreditCoins(tx, user.ID, reward.Coins, description, "campus_war", war.ID); err != nil {
+			return err
+		}
+	}
+	if reward.BadgeID != nil {
+		if err := awardRewardBadge(tx, user, *reward.BadgeID); err != nil {
+			return err
+		}
+	}
+
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"war_id":      war.ID,
+		"entity_type": participant.EntityType,
+		"entity_id":   participant.EntityID,
+		"rank":        rank,
+		"score":       participant.Score,
+		"xp":          reward.XP,
+		"coins":       reward.Coins,
+		"badge_id":    reward.BadgeID,
+	})
+	userIDInt := int(user.ID)
+	notification := &store.Notification{
+		UserID:           &userIDInt,
+		NotificationType: "winner_announcement",
+		Title:            fmt.Sprintf("%s results", war.Name),
+		Message:          description + ". Thanks for contributing!",
+		ActionURL:        stringPtr(fmt.Sprintf("/wars/%d", war.ID)),
+		Data:             stringPtr(string(dataJSON)),
+	}
+	return store.CreateNotification(tx, notification)
+}
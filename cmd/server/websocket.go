@@ -1,11 +1,18 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/rohit21755/gg_server.git/internal/services"
+	"github.com/rohit21755/gg_server.git/internal/store"
 	"github.com/rohit21755/gg_server.git/ws"
+	"gorm.io/gorm"
 
 	"github.com/gorilla/websocket"
 )
@@ -20,8 +27,250 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-// serveWS upgrades the HTTP connection to a WebSocket connection
-func serveWS(hub *ws.Hub, w http.ResponseWriter, r *http.Request) {
+// resumeBatchSize caps how many missed notifications one resume replays.
+const resumeBatchSize = 100
+
+// wsHandler handles one type of frame sent by a client.
+type wsHandler func(hub *ws.Hub, db *gorm.DB, client *ws.Client, data json.RawMessage)
+
+// wsHandlers maps inbound frame types to their handlers. Frames of any other
+// type are rejected rather than relayed.
+var wsHandlers = map[string]wsHandler{
+	"ping": func(hub *ws.Hub, db *gorm.DB, client *ws.Client, data json.RawMessage) {
+		hub.SendToClient(client, ws.Encode("pong", nil))
+	},
+	"notifications.resume": func(hub *ws.Hub, db *gorm.DB, client *ws.Client, data json.RawMessage) {
+		var req struct {
+			LastID uint `json:"last_id"`
+		}
+		if err := json.Unmarshal(data, &req); err != nil {
+			hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "invalid resume request"}))
+			return
+		}
+		resumeNotifications(hub, db, client, req.LastID)
+	},
+	"leaderboard.subscribe": func(hub *ws.Hub, db *gorm.DB, client *ws.Client, data json.RawMessage) {
+		ref, ok := parseBoardRef(hub, client, data)
+		if !ok {
+			return
+		}
+		rows, err := services.Leaderboards.Watch(ref)
+		if err != nil {
+			log.Printf("WS leaderboard error for %s: %v", ref.Room(), err)
+			hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "failed to load leaderboard"}))
+			return
+		}
+		hub.JoinRoom(client, ref.Room())
+		hub.SendToClient(client, ws.Encode(services.MessageLeaderboardSnapshot, map[string]interface{}{
+			"board":   ref.Board,
+			"id":      ref.ID,
+			"entries": rows,
+		}))
+	},
+	"leaderboard.unsubscribe": func(hub *ws.Hub, db *gorm.DB, client *ws.Client, data json.RawMessage) {
+		if ref, ok := parseBoardRef(hub, client, data); ok {
+			hub.LeaveRoom(client, ref.Room())
+		}
+	},
+	"trivia.join": func(hub *ws.Hub, db *gorm.DB, client *ws.Client, data json.RawMessage) {
+		var req struct {
+			TriviaID uint `json:"trivia_id"`
+		}
+		if err := json.Unmarshal(data, &req); err != nil || req.TriviaID == 0 {
+			hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "invalid trivia join request"}))
+			return
+		}
+
+		user, err := store.GetUserByID(db, client.UserID)
+		if err != nil {
+			hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "user not found"}))
+			return
+		}
+
+		state, err := services.LiveTrivia.Join(user, req.TriviaID)
+		if err != nil {
+			message := err.Error()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				message = "trivia not found"
+			} else if !isLiveTriviaError(err) {
+				log.Printf("WS trivia join error for user %d: %v", client.UserID, err)
+				message = "failed to join trivia"
+			}
+			hub.SendToClient(client, ws.Encode("error", map[string]string{"message": message}))
+			return
+		}
+
+		hub.JoinRoom(client, services.TriviaRoom(req.TriviaID))
+		hub.SendToClient(client, ws.Encode("trivia.joined", state))
+	},
+	"trivia.leave": func(hub *ws.Hub, db *gorm.DB, client *ws.Client, data json.RawMessage) {
+		var req struct {
+			TriviaID uint `json:"trivia_id"`
+		}
+		if err := json.Unmarshal(data, &req); err == nil {
+			hub.LeaveRoom(client, services.TriviaRoom(req.TriviaID))
+		}
+	},
+	"trivia.answer": func(hub *ws.Hub, db *gorm.DB, client *ws.Client, data json.RawMessage) {
+		var req struct {
+			TriviaID   uint   `json:"trivia_id"`
+			QuestionID int    `json:"question_id"`
+			Answer     string `json:"answer"`
+		}
+		if err := json.Unmarshal(data, &req); err != nil {
+			hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "invalid trivia answer"}))
+			return
+		}
+
+		if err := services.LiveTrivia.Answer(req.TriviaID, client.UserID, req.QuestionID, req.Answer); err != nil {
+			hub.SendToClient(client, ws.Encode("error", map[string]string{"message": err.Error()}))
+			return
+		}
+		hub.SendToClient(client, ws.Encode(services.MessageTriviaAnswerReceived, map[string]interface{}{
+			"trivia_id":   req.TriviaID,
+			"question_id": req.QuestionID,
+		}))
+	},
+}
+
+// isLiveTriviaError reports whether err is a live trivia rule violation whose
+// message can be shown to the player.
+func isLiveTriviaError(err error) bool {
+	for _, target := range []error{
+		services.ErrTriviaNotLive,
+		services.ErrTriviaNotJoinable,
+		services.ErrTriviaFull,
+		services.ErrTriviaInsufficient,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBoardRef reads a {board, id} leaderboard reference, replying with an
+// error frame when it is invalid.
+func parseBoardRef(hub *ws.Hub, client *ws.Client, data json.RawMessage) (services.BoardRef, bool) {
+	var ref services.BoardRef
+	if err := json.Unmarshal(data, &ref); err != nil {
+		hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "invalid leaderboard request"}))
+		return ref, false
+	}
+
+	switch ref.Board {
+	case store.BoardGlobal:
+		ref.ID = 0
+	case store.BoardCollege, store.BoardState, store.BoardCampaign, store.BoardWar:
+		if ref.ID == 0 {
+			hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "leaderboard id is required"}))
+			return ref, false
+		}
+	default:
+		hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "unknown leaderboard: " + ref.Board}))
+		return ref, false
+	}
+	return ref, true
+}
+
+// resumeNotifications replays notifications created after lastID, followed by
+// the unread count. has_more tells the client to resume again from the last
+// ID it received.
+func resumeNotifications(hub *ws.Hub, db *gorm.DB, client *ws.Client, lastID uint) {
+	notifications, err := store.GetNotificationsAfter(db, client.UserID, lastID, resumeBatchSize+1)
+	if err != nil {
+		log.Printf("WS resume error for user %d: %v", client.UserID, err)
+		hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "failed to load notifications"}))
+		return
+	}
+
+	hasMore := len(notifications) > resumeBatchSize
+	if hasMore {
+		notifications = notifications[:resumeBatchSize]
+	}
+	for i := range notifications {
+		hub.SendToClient(client, ws.Encode(services.MessageNotification, services.NotificationPayload(&notifications[i])))
+	}
+	hub.SendToClient(client, ws.Encode("notification.resumed", map[string]interface{}{
+		"count":    len(notifications),
+		"has_more": hasMore,
+	}))
+
+	count, err := store.CountUnreadNotifications(db, client.UserID)
+	if err == nil {
+		hub.SendToClient(client, ws.Encode(services.MessageUnreadCount, map[string]int64{"unread_count": count}))
+	}
+}
+
+// wsTicketTTL is how long a WebSocket ticket can be redeemed
+const wsTicketTTL = 30 * time.Second
+
+// Create WebSocket Ticket
+func createWSTicketHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r)
+		if !ok {
+			unauthorizedResponse(w, r, errors.New("user not found in context"))
+			return
+		}
+
+		// Only the ticket's hash is stored
+		ticket, err := generateRandomToken(32)
+		if err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+		if err := store.CreateWSTicket(db, user.ID, ticket, wsTicketTTL); err != nil {
+			internalServerError(w, r, err)
+			return
+		}
+
+		response := map[string]interface{}{
+			"ticket":     ticket,
+			"expires_in": int(wsTicketTTL.Seconds()),
+		}
+		if err := jsonResponse(w, http.StatusCreated, response); err != nil {
+			internalServerError(w, r, err)
+		}
+	}
+}
+
+// wsUser authenticates a WebSocket upgrade request by the session token in
+// the Authorization header or, for browsers that cannot set headers on the
+// upgrade request, a single-use ticket in the ticket query parameter. Session
+// tokens are never read from the URL, which is written to the access log.
+func wsUser(db *gorm.DB, r *http.Request) (*store.User, error) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return authenticateToken(db, token)
+	}
+
+	ticket := r.URL.Query().Get("ticket")
+	if ticket == "" {
+		return nil, errors.New("token or ticket is required")
+	}
+	wsTicket, err := store.ConsumeWSTicket(db, ticket)
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidWSTicket) {
+			return nil, err
+		}
+		log.Printf("WS ticket error: %v", err)
+		return nil, errors.New("failed to check ticket")
+	}
+	user, err := store.GetUserByID(db, uint(wsTicket.UserID))
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// serveWS authenticates the request and upgrades it to a WebSocket connection
+func serveWS(hub *ws.Hub, db *gorm.DB, w http.ResponseWriter, r *http.Request) {
+	user, err := wsUser(db, r)
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println("WS upgrade error:", err)
@@ -29,19 +278,27 @@ func serveWS(hub *ws.Hub, w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := &ws.Client{
-		Conn: conn,
-		Send: make(chan []byte, 256),
+		Conn:      conn,
+		Send:      make(chan []byte, 256),
+		UserID:    user.ID,
+		CollegeID: user.CollegeID,
+		StateID:   user.StateID,
 	}
 
 	hub.Register <- client
 
 	// Start goroutines
 	go clientWriter(hub, client)
-	go clientReader(hub, client)
+	go clientReader(hub, db, client)
+
+	// Catch up on notifications missed while disconnected
+	if lastID, err := strconv.ParseUint(r.URL.Query().Get("last_notification_id"), 10, 64); err == nil {
+		resumeNotifications(hub, db, client, uint(lastID))
+	}
 }
 
 // Reads messages FROM the client
-func clientReader(hub *ws.Hub, client *ws.Client) {
+func clientReader(hub *ws.Hub, db *gorm.DB, client *ws.Client) {
 	defer func() {
 		hub.Unregister <- client
 		client.Conn.Close()
@@ -68,8 +325,21 @@ func clientReader(hub *ws.Hub, client *ws.Client) {
 			break
 		}
 
-		// Echo or broadcast incoming messages
-		hub.Broadcast <- message
+		var frame struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(message, &frame); err != nil {
+			hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "invalid message"}))
+			continue
+		}
+
+		handler, ok := wsHandlers[frame.Type]
+		if !ok {
+			hub.SendToClient(client, ws.Encode("error", map[string]string{"message": "unsupported message type: " + frame.Type}))
+			continue
+		}
+		handler(hub, db, client, frame.Data)
 	}
 }
 
@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"github.com/rohit21755/gg_server.git/internal/store"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"log"
@@ -28,6 +29,7 @@ func Connect() *gorm.DB {
 	if err != nil {
 		log.Fatal("failed connecting to database:", err)
 	}
+	store.TrackCommits(db)
 
 	DB = db
 	return db
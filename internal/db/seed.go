@@ -41,6 +41,10 @@ func Seed(database *gorm.DB) error {
 		return fmt.Errorf("failed to seed tasks: %w", err)
 	}
 
+	if err := seedProfileSkins(database); err != nil {
+		return fmt.Errorf("failed to seed profile skins: %w", err)
+	}
+
 	if err := seedRewards(database); err != nil {
 		return fmt.Errorf("failed to seed rewards: %w", err)
 	}
@@ -627,6 +631,16 @@ func seedRewards(db *gorm.DB) error {
 		},
 	}
 
+	// The premium skin reward grants the premium skin
+	var premiumSkin store.ProfileSkin
+	if err := db.Where("name = ?", "Premium").First(&premiumSkin).Error; err == nil {
+		for i := range rewards {
+			if rewards[i].RewardType == "profile_skin" {
+				rewards[i].ProfileSkinID = intPtr(int(premiumSkin.ID))
+			}
+		}
+	}
+
 	for _, reward := range rewards {
 		var existing store.RewardStore
 		if err := db.Where("name = ?", reward.Name).First(&existing).Error; err != nil {
@@ -643,6 +657,50 @@ func seedRewards(db *gorm.DB) error {
 	return nil
 }
 
+// seedProfileSkins seeds profile skins
+func seedProfileSkins(db *gorm.DB) error {
+	skins := []store.ProfileSkin{
+		{
+			Name:         "Neon",
+			Description:  stringPtr("Glowing neon frame"),
+			PreviewURL:   "https://example.com/skins/neon.png",
+			UnlockMethod: "xp",
+			XPCost:       500,
+			IsActive:     true,
+		},
+		{
+			Name:            "Gold",
+			Description:     stringPtr("Gold frame for experienced ambassadors"),
+			PreviewURL:      "https://example.com/skins/gold.png",
+			UnlockMethod:    "level",
+			RequiredLevelID: intPtr(3),
+			IsActive:        true,
+		},
+		{
+			Name:         "Premium",
+			Description:  stringPtr("Exclusive skin from the reward store"),
+			PreviewURL:   "https://example.com/skins/premium.png",
+			UnlockMethod: "purchase",
+			IsActive:     true,
+		},
+	}
+
+	for _, skin := range skins {
+		var existing store.ProfileSkin
+		if err := db.Where("name = ?", skin.Name).First(&existing).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				if err := db.Create(&skin).Error; err != nil {
+					return fmt.Errorf("failed to create profile skin %s: %w", skin.Name, err)
+				}
+				log.Printf("Created profile skin: %s", skin.Name)
+			} else {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // seedSpinWheel seeds spin wheel
 func seedSpinWheel(db *gorm.DB) error {
 	now := time.Now()
@@ -0,0 +1,152 @@
+// Package imaging decodes uploaded images and produces resized, metadata-free
+// copies of them using only the standard library.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// maxPixels bounds the decoded size of an image so a small file cannot expand
+// into an enormous bitmap.
+const maxPixels = 40_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrImageTooLarge     = errors.New("image dimensions are too large")
+)
+
+// Decode decodes a JPEG, PNG or GIF (first frame) image and applies the EXIF
+// orientation of JPEGs so the pixels are upright once the metadata is dropped.
+func Decode(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if format != "jpeg" && format != "png" && format != "gif" {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	var img image.Image
+	switch format {
+	case "jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case "png":
+		img, err = png.Decode(bytes.NewReader(data))
+	case "gif":
+		img, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if format == "jpeg" {
+		img = orient(toRGBA(img), jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+// Encode re-encodes an image. JPEG sources stay JPEG; PNG and GIF sources are
+// written as PNG to keep transparency. Only pixel data is written, so any EXIF
+// or other metadata from the original file is gone.
+func Encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Fit scales img down so it fits within maxWidth x maxHeight, keeping its
+// aspect ratio. Images that already fit are returned unchanged.
+func Fit(img image.Image, maxWidth, maxHeight int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= maxWidth && b.Dy() <= maxHeight {
+		return img
+	}
+
+	width, height := maxWidth, b.Dy()*maxWidth/b.Dx()
+	if height > maxHeight {
+		width, height = b.Dx()*maxHeight/b.Dy(), maxHeight
+	}
+	return resize(toRGBA(img), max(width, 1), max(height, 1))
+}
+
+// Thumbnail crops the centre of img to the target aspect ratio and scales it to
+// exactly width x height.
+func Thumbnail(img image.Image, width, height int) image.Image {
+	src := toRGBA(img)
+	b := src.Bounds()
+
+	cropW, cropH := b.Dx(), b.Dx()*height/width
+	if cropH > b.Dy() {
+		cropW, cropH = b.Dy()*width/height, b.Dy()
+	}
+	x0 := b.Min.X + (b.Dx()-cropW)/2
+	y0 := b.Min.Y + (b.Dy()-cropH)/2
+	cropped := src.SubImage(image.Rect(x0, y0, x0+max(cropW, 1), y0+max(cropH, 1))).(*image.RGBA)
+
+	return resize(cropped, width, height)
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// resize scales src to width x height by averaging every source pixel that
+// falls inside each destination pixel. Averaging premultiplied RGBA keeps
+// transparent edges clean.
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	srcW, srcH := b.Dx(), b.Dy()
+
+	for dy := 0; dy < height; dy++ {
+		sy0 := dy * srcH / height
+		sy1 := max((dy+1)*srcH/height, sy0+1)
+		for dx := 0; dx < width; dx++ {
+			sx0 := dx * srcW / width
+			sx1 := max((dx+1)*srcW/width, sx0+1)
+
+			var r, g, bl, a, n uint32
+			for sy := sy0; sy < sy1; sy++ {
+				off := src.PixOffset(b.Min.X+sx0, b.Min.Y+sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint32(src.Pix[off])
+					g += uint32(src.Pix[off+1])
+					bl += uint32(src.Pix[off+2])
+					a += uint32(src.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+
+			i := dst.PixOffset(dx, dy)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,264 @@
+// Package jobs executes store.ScheduledJob rows in the background. Workers
+// claim due jobs with FOR UPDATE SKIP LOCKED, so several server processes can
+// share one queue.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// Handler runs one job. The returned result, if any, is stored as the job's
+// JSON result. Handlers should honour ctx, which is cancelled on timeout or
+// when shutdown runs out of time.
+type Handler func(ctx context.Context, db *gorm.DB, job *store.ScheduledJob) (interface{}, error)
+
+// Options configures a Runner. Zero values fall back to the defaults.
+type Options struct {
+	// Workers is how many jobs run concurrently
+	Workers int
+	// PollInterval is how long an idle worker waits before looking again
+	PollInterval time.Duration
+	// Timeout bounds a single run of a job
+	Timeout time.Duration
+	// RetryBase is the delay before the first retry; it doubles per attempt
+	RetryBase time.Duration
+	// RetryMax caps the retry delay
+	RetryMax time.Duration
+}
+
+func (o *Options) setDefaults() {
+	if o.Workers <= 0 {
+		o.Workers = 2
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = 5 * time.Second
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Minute
+	}
+	if o.RetryBase <= 0 {
+		o.RetryBase = 30 * time.Second
+	}
+	if o.RetryMax <= 0 {
+		o.RetryMax = time.Hour
+	}
+}
+
+// Runner dispatches due jobs to the handler registered for their JobType.
+type Runner struct {
+	db   *gorm.DB
+	opts Options
+
+	handlers  map[string]Handler
+	types     []string
+	intervals map[string]time.Duration
+
+	stop   context.CancelFunc
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(db *gorm.DB, opts Options) *Runner {
+	opts.setDefaults()
+	return &Runner{
+		db:        db,
+		opts:      opts,
+		handlers:  make(map[string]Handler),
+		intervals: make(map[string]time.Duration),
+	}
+}
+
+// Register sets the handler for a job type. It must be called before Start.
+func (r *Runner) Register(jobType string, handler Handler) {
+	if _, ok := r.handlers[jobType]; !ok {
+		r.types = append(r.types, jobType)
+	}
+	r.handlers[jobType] = handler
+}
+
+// Every registers a handler that runs once per interval, counted from the end
+// of the previous run. Start creates the single recurring job of the type if
+// it does not exist yet, so however many processes run, one of them runs it.
+// It must be called before Start.
+func (r *Runner) Every(jobType string, interval time.Duration, handler Handler) {
+	r.Register(jobType, handler)
+	r.intervals[jobType] = interval
+}
+
+// Start requeues jobs left running by a previous process, makes sure every
+// recurring job exists and starts the workers. Only registered job types are
+// claimed.
+func (r *Runner) Start() {
+	if len(r.types) == 0 {
+		return
+	}
+
+	// A job still running after its timeout was abandoned by a dead worker
+	if n, err := store.RequeueStaleJobs(r.db, time.Now().Add(-2*r.opts.Timeout)); err != nil {
+		log.Printf("jobs: failed to requeue stale jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("jobs: requeued %d stale jobs", n)
+	}
+
+	for jobType := range r.intervals {
+		if err := store.EnsureRecurringJob(r.db, jobType, time.Now()); err != nil {
+			log.Printf("jobs: failed to schedule recurring %s job: %v", jobType, err)
+		}
+	}
+
+	pollCtx, stop := context.WithCancel(context.Background())
+	runCtx, cancel := context.WithCancel(context.Background())
+	r.stop = stop
+	r.cancel = cancel
+
+	for i := 0; i < r.opts.Workers; i++ {
+		r.wg.Add(1)
+		go r.work(pollCtx, runCtx)
+	}
+}
+
+// Shutdown stops claiming new jobs and waits for running ones to finish. If
+// ctx expires first, running jobs are cancelled and returned to the queue.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	if r.stop == nil {
+		return nil
+	}
+	r.stop()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		r.cancel()
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (r *Runner) work(pollCtx, runCtx context.Context) {
+	defer r.wg.Done()
+
+	for pollCtx.Err() == nil {
+		job, err := store.ClaimDueJob(r.db, r.types, time.Now())
+		if err == nil {
+			r.run(runCtx, job)
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("jobs: failed to claim job: %v", err)
+		}
+
+		select {
+		case <-pollCtx.Done():
+			return
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+// run executes a claimed job and records the outcome.
+func (r *Runner) run(runCtx context.Context, job *store.ScheduledJob) {
+	ctx, cancel := context.WithTimeout(runCtx, r.opts.Timeout)
+	defer cancel()
+
+	result, err := r.call(ctx, job)
+
+	// Interrupted by shutdown: put it back without using up an attempt
+	if err != nil && runCtx.Err() != nil {
+		if err := store.ReleaseJob(r.db, job); err != nil {
+			log.Printf("jobs: failed to release job %d: %v", job.ID, err)
+		}
+		return
+	}
+
+	if err != nil {
+		var retryAt *time.Time
+		if job.Attempts < job.MaxAttempts {
+			at := time.Now().Add(r.backoff(job.Attempts))
+			retryAt = &at
+		}
+		log.Printf("jobs: %s job %d failed (attempt %d/%d): %v", job.JobType, job.ID, job.Attempts, job.MaxAttempts, err)
+		if retryAt == nil && r.recurring(job) {
+			// Out of retries: give up on this run, not on the next ones
+			message := err.Error()
+			if err := store.RescheduleJob(r.db, job, nil, &message, time.Now().Add(r.intervals[job.JobType])); err != nil {
+				log.Printf("jobs: failed to reschedule job %d: %v", job.ID, err)
+			}
+			return
+		}
+		if err := store.FailJob(r.db, job, err.Error(), retryAt); err != nil {
+			log.Printf("jobs: failed to record failure of job %d: %v", job.ID, err)
+		}
+		return
+	}
+
+	var resultJSON *string
+	if result != nil {
+		if payload, err := json.Marshal(result); err == nil {
+			s := string(payload)
+			resultJSON = &s
+		}
+	}
+	if r.recurring(job) {
+		if err := store.RescheduleJob(r.db, job, resultJSON, nil, time.Now().Add(r.intervals[job.JobType])); err != nil {
+			log.Printf("jobs: failed to reschedule job %d: %v", job.ID, err)
+		}
+		return
+	}
+	if err := store.CompleteJob(r.db, job, resultJSON); err != nil {
+		log.Printf("jobs: failed to record completion of job %d: %v", job.ID, err)
+	}
+}
+
+// recurring reports whether job is the recurring job of a type registered
+// with Every.
+func (r *Runner) recurring(job *store.ScheduledJob) bool {
+	_, ok := r.intervals[job.JobType]
+	return ok && job.IsRecurring
+}
+
+// call runs the handler, turning a panic into an error.
+func (r *Runner) call(ctx context.Context, job *store.ScheduledJob) (result interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return r.handlers[job.JobType](ctx, r.db, job)
+}
+
+// backoff is the delay before retrying after the given attempt.
+func (r *Runner) backoff(attempt int) time.Duration {
+	delay := r.opts.RetryBase
+	for i := 1; i < attempt && delay < r.opts.RetryMax; i++ {
+		delay *= 2
+	}
+	if delay > r.opts.RetryMax {
+		delay = r.opts.RetryMax
+	}
+	return delay
+}
+
+// Decode unmarshals a job's JSON payload into v.
+func Decode(job *store.ScheduledJob, v interface{}) error {
+	if job.JobData == nil {
+		return errors.New("job has no data")
+	}
+	return json.Unmarshal([]byte(*job.JobData), v)
+}
@@ -0,0 +1,176 @@
+// Package pdf writes simple single-page PDF documents made of text, lines and
+// rectangles using the standard Helvetica fonts, so no font files are needed.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// Page sizes in points.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+func (f Font) resource() string {
+	if f == HelveticaBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+// Color is an RGB color with components between 0 and 1.
+type Color struct {
+	R, G, B float64
+}
+
+// ParseHexColor parses "#rrggbb", falling back to black on malformed input.
+func ParseHexColor(hex string) Color {
+	var r, g, b int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(hex, "#"), "%02x%02x%02x", &r, &g, &b); err != nil {
+		return Color{}
+	}
+	return Color{float64(r) / 255, float64(g) / 255, float64(b) / 255}
+}
+
+// Document is a single page PDF under construction. Coordinates are in points
+// with the origin in the bottom-left corner.
+type Document struct {
+	Width   float64
+	Height  float64
+	Title   string
+	content bytes.Buffer
+}
+
+func New(width, height float64) *Document {
+	return &Document{Width: width, Height: height}
+}
+
+func (d *Document) SetFillColor(c Color) {
+	fmt.Fprintf(&d.content, "%.3f %.3f %.3f rg\n", c.R, c.G, c.B)
+}
+
+func (d *Document) SetStrokeColor(c Color) {
+	fmt.Fprintf(&d.content, "%.3f %.3f %.3f RG\n", c.R, c.G, c.B)
+}
+
+// Rect strokes a rectangle outline.
+func (d *Document) Rect(x, y, w, h, lineWidth float64) {
+	fmt.Fprintf(&d.content, "%.2f w %.2f %.2f %.2f %.2f re S\n", lineWidth, x, y, w, h)
+}
+
+// FillRect fills a rectangle with the current fill color.
+func (d *Document) FillRect(x, y, w, h float64) {
+	fmt.Fprintf(&d.content, "%.2f %.2f %.2f %.2f re f\n", x, y, w, h)
+}
+
+func (d *Document) Line(x1, y1, x2, y2, lineWidth float64) {
+	fmt.Fprintf(&d.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n", lineWidth, x1, y1, x2, y2)
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (d *Document) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&d.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font.resource(), size, x, y, escape(encode(s)))
+}
+
+// CenteredText draws s horizontally centred on the page.
+func (d *Document) CenteredText(y float64, font Font, size float64, s string) {
+	d.Text((d.Width-TextWidth(font, size, s))/2, y, font, size, s)
+}
+
+// WrappedCenteredText word-wraps s to maxWidth, draws each line centred and
+// returns the baseline below the last line.
+func (d *Document) WrappedCenteredText(y, maxWidth float64, font Font, size, leading float64, s string) float64 {
+	for _, line := range wrap(font, size, maxWidth, s) {
+		d.CenteredText(y, font, size, line)
+		y -= leading
+	}
+	return y
+}
+
+// Bytes serialises the document.
+func (d *Document) Bytes() ([]byte, error) {
+	var stream bytes.Buffer
+	zw := zlib.NewWriter(&stream)
+	if _, err := zw.Write(d.content.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Contents 4 0 R /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>", d.Width, d.Height),
+		fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /Producer (gg_server) >>", escape(encode(d.Title))),
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+
+	return out.Bytes(), nil
+}
+
+// encode converts s to WinAnsi bytes; characters outside Latin-1 become '?'.
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < 0x20 || (r > 0x7e && r < 0xa0) || r > 0xff {
+			b.WriteByte('?')
+			continue
+		}
+		b.WriteByte(byte(r))
+	}
+	return b.String()
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
+
+func wrap(font Font, size, maxWidth float64, s string) []string {
+	var lines []string
+	var current string
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && TextWidth(font, size, candidate) > maxWidth {
+			lines = append(lines, current)
+			current = word
+		} else {
+			current = candidate
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
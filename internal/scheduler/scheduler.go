@@ -0,0 +1,234 @@
+// Package scheduler drives time-boxed events (flash challenges, content
+// battles, campus wars, trivia, campaigns and badge bingos) through their
+// lifecycle as their timestamps pass, firing hooks at each edge.
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// Event kinds
+const (
+	KindFlashChallenge = "flash_challenge"
+	KindContentBattle  = "content_battle"
+	KindCampusWar      = "campus_war"
+	KindTrivia         = "trivia"
+	KindCampaign       = "campaign"
+	KindBadgeBingo     = "badge_bingo"
+)
+
+// Lifecycle edges
+const (
+	EdgeStarted           = "started"
+	EdgeSubmissionsClosed = "submissions_closed"
+	EdgeVotingStarted     = "voting_started"
+	EdgeCompleted         = "completed"
+)
+
+// Event is one edge crossed by one event.
+type Event struct {
+	Kind string    `json:"kind"`
+	ID   uint      `json:"id"`
+	Edge string    `json:"edge"`
+	At   time.Time `json:"at"`
+}
+
+// Hook reacts to an edge. Hooks run inside the transaction that records the
+// edge; an error rolls the edge back and it is retried on the next tick.
+type Hook func(tx *gorm.DB, event Event) error
+
+// Rule fires Edge for every row of Table whose TimeColumn has passed and that
+// matches From and Where. Set is applied to the row when the edge fires.
+type Rule struct {
+	Kind       string
+	Edge       string
+	Table      string
+	TimeColumn string
+	From       []string
+	Where      string
+	Set        map[string]interface{}
+}
+
+// Rules is the lifecycle of every scheduled event kind, in the order edges
+// are evaluated, so an event whose whole window has passed still fires each
+// edge in turn.
+var Rules = []Rule{
+	{Kind: KindFlashChallenge, Edge: EdgeStarted, Table: "flash_challenges", TimeColumn: "start_time",
+		From: []string{"scheduled"}, Set: map[string]interface{}{"status": "active"}},
+	{Kind: KindFlashChallenge, Edge: EdgeCompleted, Table: "flash_challenges", TimeColumn: "end_time",
+		From: []string{"active"}, Set: map[string]interface{}{"status": "completed"}},
+
+	// Battles take submissions from creation until the deadline, then vote
+	{Kind: KindContentBattle, Edge: EdgeStarted, Table: "content_battles", TimeColumn: "created_at",
+		From: []string{"upcoming"}, Set: map[string]interface{}{"status": "submissions"}},
+	{Kind: KindContentBattle, Edge: EdgeSubmissionsClosed, Table: "content_battles", TimeColumn: "submission_deadline",
+		From: []string{"submissions"}},
+	{Kind: KindContentBattle, Edge: EdgeVotingStarted, Table: "content_battles", TimeColumn: "voting_start",
+		From: []string{"submissions"}, Set: map[string]interface{}{"status": "voting"}},
+	{Kind: KindContentBattle, Edge: EdgeCompleted, Table: "content_battles", TimeColumn: "voting_end",
+		From: []string{"voting"}, Set: map[string]interface{}{"status": "completed"}},
+
+	{Kind: KindCampusWar, Edge: EdgeStarted, Table: "campus_wars", TimeColumn: "start_date",
+		From: []string{"upcoming"}, Set: map[string]interface{}{"status": "active"}},
+	{Kind: KindCampusWar, Edge: EdgeCompleted, Table: "campus_wars", TimeColumn: "end_date",
+		From: []string{"active"}, Set: map[string]interface{}{"status": "completed"}},
+
+	// Live trivia is started and finished by its game loop
+	{Kind: KindTrivia, Edge: EdgeStarted, Table: "trivia_tournaments", TimeColumn: "start_date",
+		From: []string{"upcoming"}, Where: "mode = 'async'", Set: map[string]interface{}{"status": "active"}},
+	{Kind: KindTrivia, Edge: EdgeCompleted, Table: "trivia_tournaments", TimeColumn: "end_date",
+		From: []string{"active"}, Where: "mode = 'async'", Set: map[string]interface{}{"status": "completed"}},
+
+	// Campaigns are published by an admin; only their end is automatic
+	{Kind: KindCampaign, Edge: EdgeCompleted, Table: "campaigns", TimeColumn: "end_date",
+		From: []string{"active"}, Set: map[string]interface{}{"status": "completed"}},
+
+	{Kind: KindBadgeBingo, Edge: EdgeStarted, Table: "badge_bingo", TimeColumn: "start_date",
+		Where: "is_active = true"},
+	{Kind: KindBadgeBingo, Edge: EdgeCompleted, Table: "badge_bingo", TimeColumn: "end_date",
+		Where: "is_active = true", Set: map[string]interface{}{"is_active": false}},
+}
+
+// batchSize caps how many events one rule fires per tick.
+const batchSize = 100
+
+// Scheduler evaluates Rules periodically.
+type Scheduler struct {
+	db        *gorm.DB
+	rules     []Rule
+	hooks     map[string][]Hook
+	listeners []func(Event)
+}
+
+func New(db *gorm.DB) *Scheduler {
+	return &Scheduler{
+		db:    db,
+		rules: Rules,
+		hooks: make(map[string][]Hook),
+	}
+}
+
+// On registers a hook for one edge of one event kind.
+func (s *Scheduler) On(kind, edge string, hook Hook) {
+	key := kind + ":" + edge
+	s.hooks[key] = append(s.hooks[key], hook)
+}
+
+// Listen registers a callback for every edge of every kind. Listeners run
+// after the edge is committed, so they suit side effects that cannot be
+// rolled back, such as broadcasts.
+func (s *Scheduler) Listen(listener func(Event)) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// Run evaluates the rules every interval.
+func (s *Scheduler) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		s.Tick(time.Now())
+	}
+}
+
+// Tick fires every edge that is due at now.
+func (s *Scheduler) Tick(now time.Time) {
+	for _, rule := range s.rules {
+		ids, err := s.due(rule, now)
+		if err != nil {
+			log.Printf("scheduler: failed to find due %s %s edges: %v", rule.Kind, rule.Edge, err)
+			continue
+		}
+		for _, id := range ids {
+			fired, err := s.fire(rule, id, now)
+			if err != nil {
+				log.Printf("scheduler: %s %d %s failed: %v", rule.Kind, id, rule.Edge, err)
+				continue
+			}
+			if fired {
+				event := Event{Kind: rule.Kind, ID: id, Edge: rule.Edge, At: now}
+				for _, listener := range s.listeners {
+					listener(event)
+				}
+			}
+		}
+	}
+}
+
+// due lists events that have reached the rule's edge but not fired it.
+func (s *Scheduler) due(rule Rule, now time.Time) ([]uint, error) {
+	query := s.db.Table(rule.Table).
+		Where(fmt.Sprintf("%s <= ?", rule.TimeColumn), now).
+		Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM event_edges e WHERE e.event_kind = ? AND e.event_id = %s.id AND e.edge = ?)", rule.Table), rule.Kind, rule.Edge)
+	if len(rule.From) > 0 {
+		query = query.Where("status IN ?", rule.From)
+	}
+	if rule.Where != "" {
+		query = query.Where(rule.Where)
+	}
+
+	var ids []uint
+	err := query.Order(rule.TimeColumn+" ASC").Limit(batchSize).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// errSkipped rolls back an edge that another process fired first or whose
+// event changed status since it was found due.
+var errSkipped = errors.New("edge skipped")
+
+// fire records the edge, applies the rule's changes and runs the hooks in one
+// transaction. The conditions and time are re-checked so a concurrent admin
+// change, such as an extension, wins over the schedule.
+func (s *Scheduler) fire(rule Rule, id uint, now time.Time) (bool, error) {
+	event := Event{Kind: rule.Kind, ID: id, Edge: rule.Edge, At: now}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		recorded, err := store.RecordEventEdge(tx, rule.Kind, id, rule.Edge, now)
+		if err != nil {
+			return err
+		}
+		if !recorded {
+			return errSkipped
+		}
+
+		update := tx.Table(rule.Table).
+			Where("id = ?", id).
+			Where(fmt.Sprintf("%s <= ?", rule.TimeColumn), now)
+		if len(rule.From) > 0 {
+			update = update.Where("status IN ?", rule.From)
+		}
+		if rule.Where != "" {
+			update = update.Where(rule.Where)
+		}
+		var matched int64
+		if len(rule.Set) > 0 {
+			result := update.Updates(rule.Set)
+			if result.Error != nil {
+				return result.Error
+			}
+			matched = result.RowsAffected
+		} else if err := update.Count(&matched).Error; err != nil {
+			return err
+		}
+		if matched == 0 {
+			return errSkipped
+		}
+
+		for _, hook := range s.hooks[rule.Kind+":"+rule.Edge] {
+			if err := hook(tx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errSkipped) {
+		return false, nil
+	}
+	return err == nil, err
+}
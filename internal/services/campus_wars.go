@@ -0,0 +1,59 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// ScoreActiveWars rescores the wars that are running at now and returns how
+// many were scored. A war that fails to score is logged and skipped so it
+// does not hold up the others, and the run reports an error.
+func ScoreActiveWars(db *gorm.DB, now time.Time) (int, error) {
+	var wars []store.CampusWar
+	if err := db.Where("status = 'active' AND settled_at IS NULL AND start_date <= ?", now).Find(&wars).Error; err != nil {
+		return 0, err
+	}
+
+	scored := 0
+	for i := range wars {
+		if err := scoreWar(db, &wars[i], now); err != nil {
+			log.Printf("campus wars: failed to score war %d: %v", wars[i].ID, err)
+			continue
+		}
+		scored++
+	}
+	if scored < len(wars) {
+		return scored, fmt.Errorf("%d of %d wars failed to score", len(wars)-scored, len(wars))
+	}
+	return scored, nil
+}
+
+func scoreWar(db *gorm.DB, war *store.CampusWar, now time.Time) error {
+	participants, err := store.ScoreCampusWar(db, war, now)
+	if err != nil {
+		return err
+	}
+
+	// One snapshot per hour of the war; the final one is taken at settlement
+	hour := now.Truncate(time.Hour)
+	if hour.Before(war.EndDate) {
+		taken, err := store.HasWarSnapshot(db, war.ID, hour)
+		if err != nil {
+			return err
+		}
+		if !taken {
+			if err := store.SnapshotCampusWar(db, war, participants, hour); err != nil {
+				return err
+			}
+		}
+	}
+
+	if Leaderboards != nil {
+		Leaderboards.Refresh(BoardRef{Board: store.BoardWar, ID: war.ID})
+	}
+	return nil
+}
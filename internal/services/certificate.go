@@ -0,0 +1,84 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rohit21755/gg_server.git/internal/env"
+	"github.com/rohit21755/gg_server.git/internal/pdf"
+	"github.com/rohit21755/gg_server.git/internal/store"
+)
+
+// fallbackTemplate is used for certificates issued before templates existed.
+var fallbackTemplate = store.CertificateTemplate{
+	Heading:      "Certificate",
+	BodyTemplate: "This is to certify that {{name}} was awarded {{title}} on {{date}}.",
+	AccentColor:  "#1F6F4A",
+}
+
+// RenderCertificate renders a certificate as an A4 landscape PDF, filling the
+// template with the details captured when the certificate was issued.
+func RenderCertificate(certificate *store.Certificate, template *store.CertificateTemplate) ([]byte, error) {
+	if template == nil {
+		template = &fallbackTemplate
+	}
+
+	details := certificate.Details()
+	date := certificate.IssueDate.Format("January 2, 2006")
+	rank := ""
+	if details.Rank > 0 {
+		rank = strconv.Itoa(details.Rank)
+	}
+	body := strings.NewReplacer(
+		"{{name}}", details.RecipientName,
+		"{{campaign}}", details.CampaignTitle,
+		"{{badge}}", details.BadgeName,
+		"{{rank}}", rank,
+		"{{title}}", certificate.Title,
+		"{{date}}", date,
+		"{{code}}", certificate.VerificationCode,
+	).Replace(template.BodyTemplate)
+
+	doc := pdf.New(pdf.A4Height, pdf.A4Width)
+	doc.Title = certificate.Title
+	accent := pdf.ParseHexColor(template.AccentColor)
+	muted := pdf.Color{R: 0.35, G: 0.35, B: 0.35}
+
+	// Border
+	doc.SetStrokeColor(accent)
+	doc.Rect(24, 24, doc.Width-48, doc.Height-48, 4)
+	doc.Rect(36, 36, doc.Width-72, doc.Height-72, 1)
+	doc.SetFillColor(accent)
+	doc.FillRect(36, doc.Height-110, doc.Width-72, 6)
+
+	// Heading and recipient
+	doc.CenteredText(doc.Height-160, pdf.HelveticaBold, 34, template.Heading)
+	doc.SetFillColor(muted)
+	doc.CenteredText(doc.Height-205, pdf.Helvetica, 14, "Presented to")
+	doc.SetFillColor(pdf.Color{})
+	doc.CenteredText(doc.Height-250, pdf.HelveticaBold, 30, details.RecipientName)
+	doc.SetStrokeColor(accent)
+	doc.Line(doc.Width/2-180, doc.Height-262, doc.Width/2+180, doc.Height-262, 1)
+
+	doc.WrappedCenteredText(doc.Height-305, doc.Width-220, pdf.Helvetica, 15, 22, body)
+
+	// Signatory
+	if template.SignatoryName != nil {
+		doc.Line(doc.Width/2-110, 150, doc.Width/2+110, 150, 0.8)
+		doc.CenteredText(132, pdf.HelveticaBold, 12, *template.SignatoryName)
+		if template.SignatoryTitle != nil {
+			doc.SetFillColor(muted)
+			doc.CenteredText(116, pdf.Helvetica, 10, *template.SignatoryTitle)
+		}
+	}
+
+	// Verification footer
+	doc.SetFillColor(muted)
+	doc.Text(60, 60, pdf.Helvetica, 9, "Issued by "+certificate.IssuingAuthority+" on "+date)
+	verifyLine := "Verify at " + env.Get("APP_URL", "http://localhost:3000") + "/certificates/verify/" + certificate.VerificationCode
+	doc.Text(doc.Width-60-pdf.TextWidth(pdf.Helvetica, 9, verifyLine), 60, pdf.Helvetica, 9, verifyLine)
+	doc.SetFillColor(accent)
+	doc.Text(60, 74, pdf.HelveticaBold, 10, "Code: "+certificate.VerificationCode)
+
+	return doc.Bytes()
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"gorm.io/gorm"
+)
+
+// SnapshotLeaderboards closes the weekly and monthly leaderboards whose period
+// has ended with a final snapshot, then snapshots every current leaderboard
+// as of now. Closing comes first so new periods compare against final ranks.
+// Each run rebuilds the day's snapshot. A leaderboard that fails is logged and
+// skipped so it does not hold up the others, and the run reports an error.
+func SnapshotLeaderboards(db *gorm.DB, now time.Time) (int, error) {
+	ended, err := store.GetEndedLeaderboards(db, now)
+	if err != nil {
+		return 0, err
+	}
+	failed := 0
+	for i := range ended {
+		if err := closeLeaderboard(db, &ended[i]); err != nil {
+			log.Printf("leaderboards: failed to close leaderboard %d: %v", ended[i].ID, err)
+			failed++
+		}
+	}
+
+	current, err := store.EnsureSnapshotLeaderboards(db, now)
+	if err != nil {
+		return 0, err
+	}
+	snapshotted := 0
+	for i := range current {
+		if err := snapshotLeaderboard(db, &current[i], now); err != nil {
+			log.Printf("leaderboards: failed to snapshot leaderboard %d: %v", current[i].ID, err)
+			failed++
+			continue
+		}
+		snapshotted++
+	}
+	if failed > 0 {
+		return snapshotted, fmt.Errorf("%d leaderboards failed to snapshot", failed)
+	}
+	return snapshotted, nil
+}
+
+func snapshotLeaderboard(db *gorm.DB, leaderboard *store.Leaderboard, day time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		standings, err := store.GetLeaderboardStandings(tx, leaderboard)
+		if err != nil {
+			return err
+		}
+		return store.SnapshotLeaderboard(tx, leaderboard, standings, day)
+	})
+}
+
+// closeLeaderboard takes the final snapshot of a period, dated its last day,
+// and deactivates the leaderboard.
+func closeLeaderboard(db *gorm.DB, leaderboard *store.Leaderboard) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := snapshotLeaderboard(tx, leaderboard, *leaderboard.PeriodEnd); err != nil {
+			return err
+		}
+		return tx.Model(leaderboard).Update("is_active", false).Error
+	})
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"github.com/rohit21755/gg_server.git/ws"
+	"gorm.io/gorm"
+)
+
+// Message types for live leaderboards
+const (
+	MessageLeaderboardSnapshot = "leaderboard.snapshot"
+	MessageLeaderboardDelta    = "leaderboard.delta"
+)
+
+// LiveLeaderboardSize is how many entries of each board are streamed.
+const LiveLeaderboardSize = 50
+
+// BoardRef identifies one live leaderboard.
+type BoardRef struct {
+	Board string `json:"board"`
+	ID    uint   `json:"id,omitempty"`
+}
+
+// Room is the hub room subscribers of the board join.
+func (b BoardRef) Room() string {
+	if b.Board == store.BoardGlobal {
+		return "leaderboard:global"
+	}
+	return fmt.Sprintf("leaderboard:%s:%d", b.Board, b.ID)
+}
+
+// RankChange describes an entry whose rank or score moved since the last push.
+// PreviousRank is nil for entries that just entered the board.
+type RankChange struct {
+	store.LeaderboardRow
+	PreviousRank *int `json:"previous_rank"`
+}
+
+// LeaderboardStream recomputes watched leaderboards after XP moves and pushes
+// rank-change deltas. XP events are coalesced: the first one after a flush
+// starts a timer, and everything that arrives before it fires is handled in a
+// single recompute per board.
+type LeaderboardStream struct {
+	db     *gorm.DB
+	hub    *ws.Hub
+	window time.Duration
+
+	mu        sync.Mutex
+	dirty     map[uint]bool
+	scheduled bool
+	boards    map[BoardRef][]store.LeaderboardRow
+}
+
+var Leaderboards *LeaderboardStream
+
+// InitLeaderboardStream starts streaming leaderboard deltas through the hub,
+// flushing at most once per window.
+func InitLeaderboardStream(db *gorm.DB, h *ws.Hub, window time.Duration) {
+	Leaderboards = &LeaderboardStream{
+		db:     db,
+		hub:    h,
+		window: window,
+		dirty:  make(map[uint]bool),
+		boards: make(map[BoardRef][]store.LeaderboardRow),
+	}
+	store.OnXPChange(Leaderboards.MarkDirty)
+}
+
+// Watch starts tracking a board and returns its current standings, which the
+// caller sends to the new subscriber as a snapshot.
+func (s *LeaderboardStream) Watch(ref BoardRef) ([]store.LeaderboardRow, error) {
+	rows, err := store.GetLiveLeaderboard(s.db, ref.Board, ref.ID, LiveLeaderboardSize)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.boards[ref] = rows
+	s.mu.Unlock()
+	return rows, nil
+}
+
+// MarkDirty records that a user's XP changed and schedules a flush.
+func (s *LeaderboardStream) MarkDirty(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dirty[userID] = true
+	if !s.scheduled {
+		s.scheduled = true
+		time.AfterFunc(s.window, s.flush)
+	}
+}
+
+func (s *LeaderboardStream) flush() {
+	s.mu.Lock()
+	dirty := s.dirty
+	s.dirty = make(map[uint]bool)
+	s.scheduled = false
+	refs := make([]BoardRef, 0, len(s.boards))
+	for ref := range s.boards {
+		refs = append(refs, ref)
+	}
+	s.mu.Unlock()
+
+	if len(dirty) == 0 || len(refs) == 0 {
+		return
+	}
+
+	colleges, states := s.affectedGroups(dirty)
+
+	for _, ref := range refs {
+		// Boards nobody is subscribed to any more are dropped
+		if s.hub.RoomSize(ref.Room()) == 0 {
+			s.mu.Lock()
+			delete(s.boards, ref)
+			s.mu.Unlock()
+			continue
+		}
+
+		switch ref.Board {
+		case store.BoardWar:
+			// War scores move when the war is rescored, not with each XP event
+			continue
+		case store.BoardCollege:
+			if !colleges[ref.ID] {
+				continue
+			}
+		case store.BoardState:
+			if !states[ref.ID] {
+				continue
+			}
+		}
+
+		s.refresh(ref)
+	}
+}
+
+// Refresh recomputes a board right away if anyone is watching it.
+func (s *LeaderboardStream) Refresh(ref BoardRef) {
+	s.mu.Lock()
+	_, watched := s.boards[ref]
+	s.mu.Unlock()
+
+	if !watched {
+		return
+	}
+	if s.hub.RoomSize(ref.Room()) == 0 {
+		s.mu.Lock()
+		delete(s.boards, ref)
+		s.mu.Unlock()
+		return
+	}
+	s.refresh(ref)
+}
+
+// affectedGroups returns the colleges and states of the users whose XP moved.
+func (s *LeaderboardStream) affectedGroups(dirty map[uint]bool) (map[uint]bool, map[uint]bool) {
+	ids := make([]uint, 0, len(dirty))
+	for id := range dirty {
+		ids = append(ids, id)
+	}
+
+	var users []store.User
+	if err := s.db.Select("id", "college_id", "state_id").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		log.Printf("leaderboard stream: failed to load users: %v", err)
+	}
+
+	colleges := make(map[uint]bool)
+	states := make(map[uint]bool)
+	for _, u := range users {
+		if u.CollegeID != nil {
+			colleges[uint(*u.CollegeID)] = true
+		}
+		if u.StateID != nil {
+			states[uint(*u.StateID)] = true
+		}
+	}
+	return colleges, states
+}
+
+// refresh recomputes one board and pushes the difference to its subscribers.
+func (s *LeaderboardStream) refresh(ref BoardRef) {
+	rows, err := store.GetLiveLeaderboard(s.db, ref.Board, ref.ID, LiveLeaderboardSize)
+	if err != nil {
+		log.Printf("leaderboard stream: failed to compute %s: %v", ref.Room(), err)
+		return
+	}
+
+	s.mu.Lock()
+	previous := s.boards[ref]
+	s.boards[ref] = rows
+	s.mu.Unlock()
+
+	changes, removed := diffLeaderboard(previous, rows)
+	if len(changes) == 0 && len(removed) == 0 {
+		return
+	}
+
+	s.hub.SendToRoom(ref.Room(), ws.Encode(MessageLeaderboardDelta, map[string]interface{}{
+		"board":   ref.Board,
+		"id":      ref.ID,
+		"changes": changes,
+		"removed": removed,
+	}))
+}
+
+// diffLeaderboard lists entries whose rank or score changed and the keys of
+// entries that dropped off the board.
+func diffLeaderboard(previous, current []store.LeaderboardRow) ([]RankChange, []store.LeaderboardRow) {
+	before := make(map[string]store.LeaderboardRow, len(previous))
+	for _, row := range previous {
+		before[row.Key()] = row
+	}
+
+	changes := []RankChange{}
+	for _, row := range current {
+		old, ok := before[row.Key()]
+		delete(before, row.Key())
+		if ok && old.Rank == row.Rank && old.Score == row.Score {
+			continue
+		}
+		change := RankChange{LeaderboardRow: row}
+		if ok {
+			rank := old.Rank
+			change.PreviousRank = &rank
+		}
+		changes = append(changes, change)
+	}
+
+	removed := []store.LeaderboardRow{}
+	for _, row := range previous {
+		if _, ok := before[row.Key()]; ok {
+			removed = append(removed, row)
+		}
+	}
+	return changes, removed
+}
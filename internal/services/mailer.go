@@ -0,0 +1,94 @@
+package services
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rohit21755/gg_server.git/internal/env"
+)
+
+// Mail is a single plain-text email.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MailSender delivers outgoing email.
+type MailSender interface {
+	Send(mail Mail) error
+}
+
+var Mailer MailSender
+
+// InitMailer picks a sender from MAIL_DRIVER. The default "outbox" driver
+// writes every email to a local directory instead of sending it.
+func InitMailer() {
+	from := env.Get("MAIL_FROM", "no-reply@gg.local")
+	switch env.Get("MAIL_DRIVER", "outbox") {
+	case "smtp":
+		Mailer = &SMTPSender{
+			Host:     env.Get("SMTP_HOST", "localhost"),
+			Port:     env.Get("SMTP_PORT", "587"),
+			Username: env.Get("SMTP_USER", ""),
+			Password: env.Get("SMTP_PASS", ""),
+			From:     from,
+		}
+	default:
+		Mailer = &OutboxSender{Dir: env.Get("MAIL_OUTBOX_DIR", "tmp/outbox"), From: from}
+	}
+}
+
+func SendMail(mail Mail) error {
+	if Mailer == nil {
+		return fmt.Errorf("mailer not initialized")
+	}
+	return Mailer.Send(mail)
+}
+
+// OutboxSender writes emails as .eml files for local development.
+type OutboxSender struct {
+	Dir  string
+	From string
+}
+
+func (o *OutboxSender) Send(mail Mail) error {
+	if err := os.MkdirAll(o.Dir, 0o755); err != nil {
+		return err
+	}
+	recipient := strings.NewReplacer("@", "_at_", "/", "_").Replace(mail.To)
+	name := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), recipient)
+	return os.WriteFile(filepath.Join(o.Dir, name), buildMessage(o.From, mail), 0o644)
+}
+
+// SMTPSender delivers emails through an SMTP relay.
+type SMTPSender struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s *SMTPSender) Send(mail Mail) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	return smtp.SendMail(s.Host+":"+s.Port, auth, s.From, []string{mail.To}, buildMessage(s.From, mail))
+}
+
+func buildMessage(from string, mail Mail) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + mail.To + "\r\n")
+	b.WriteString("Subject: " + mail.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(mail.Body)
+	return []byte(b.String())
+}
@@ -1,11 +1,27 @@
 package services
 
-import "github.com/rohit21755/gg_server.git/ws"
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"github.com/rohit21755/gg_server.git/ws"
+	"gorm.io/gorm"
+)
+
+// Message types pushed to clients
+const (
+	MessageNotification = "notification"
+	MessageUnreadCount  = "notification.unread_count"
+)
 
 var Hub *ws.Hub
 
+// InitNotifier wires the hub in and starts pushing every newly created
+// notification to its user's sockets.
 func InitNotifier(h *ws.Hub) {
 	Hub = h
+	store.NotificationListener = PushNotification
 }
 
 func NotifyAll(msg []byte) {
@@ -13,3 +29,53 @@ func NotifyAll(msg []byte) {
 		Hub.Broadcast <- msg
 	}
 }
+
+// NotificationPayload is the JSON shape of a notification, shared by the REST
+// API and the socket push.
+func NotificationPayload(notification *store.Notification) map[string]interface{} {
+	payload := map[string]interface{}{
+		"id":                notification.ID,
+		"notification_type": notification.NotificationType,
+		"title":             notification.Title,
+		"message":           notification.Message,
+		"is_read":           notification.IsRead,
+		"is_actionable":     notification.IsActionable,
+		"action_url":        notification.ActionURL,
+		"sent_at":           notification.SentAt,
+		"read_at":           notification.ReadAt,
+		"created_at":        notification.CreatedAt,
+	}
+
+	// Parse data JSON
+	if notification.Data != nil && *notification.Data != "" {
+		var data interface{}
+		if err := json.Unmarshal([]byte(*notification.Data), &data); err == nil {
+			payload["data"] = data
+		}
+	}
+
+	return payload
+}
+
+// PushNotification sends a notification and the new unread count to the user.
+func PushNotification(db *gorm.DB, notification *store.Notification) {
+	if Hub == nil || notification.UserID == nil {
+		return
+	}
+	userID := uint(*notification.UserID)
+	Hub.SendToUser(userID, ws.Encode(MessageNotification, NotificationPayload(notification)))
+	PushUnreadCount(db, userID)
+}
+
+// PushUnreadCount sends the user's current unread notification count.
+func PushUnreadCount(db *gorm.DB, userID uint) {
+	if Hub == nil {
+		return
+	}
+	count, err := store.CountUnreadNotifications(db, userID)
+	if err != nil {
+		log.Printf("notifier: failed to count unread notifications for user %d: %v", userID, err)
+		return
+	}
+	Hub.SendToUser(userID, ws.Encode(MessageUnreadCount, map[string]int64{"unread_count": count}))
+}
@@ -0,0 +1,560 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rohit21755/gg_server.git/internal/store"
+	"github.com/rohit21755/gg_server.git/ws"
+	"gorm.io/gorm"
+)
+
+// Message types for live trivia
+const (
+	MessageTriviaStarted        = "trivia.started"
+	MessageTriviaQuestion       = "trivia.question"
+	MessageTriviaAnswerReceived = "trivia.answer_received"
+	MessageTriviaAnswerResult   = "trivia.answer_result"
+	MessageTriviaQuestionResult = "trivia.question_result"
+	MessageTriviaFinished       = "trivia.finished"
+)
+
+const (
+	// triviaBasePoints is awarded for every correct answer
+	triviaBasePoints = 10
+	// triviaSpeedBonus is the extra awarded for answering instantly; it
+	// shrinks linearly to zero at the end of the question window
+	triviaSpeedBonus = 10
+	// triviaXPPerPoint converts the final score into XP, as for async trivia
+	triviaXPPerPoint = 5
+	// triviaLeadIn gives players time to get ready before the first question
+	triviaLeadIn = 5 * time.Second
+	// triviaIntermission is the pause between a question's result and the next one
+	triviaIntermission = 4 * time.Second
+	// triviaStandingsSize is how many rows of the standings are broadcast
+	triviaStandingsSize = 20
+)
+
+var (
+	ErrTriviaNotLive      = errors.New("trivia is not a live tournament")
+	ErrTriviaNotJoinable  = errors.New("trivia is not open for players")
+	ErrTriviaFull         = errors.New("trivia has reached its maximum participants")
+	ErrTriviaInsufficient = errors.New("insufficient XP for entry fee")
+	ErrTriviaNotRunning   = errors.New("trivia is not running")
+	ErrTriviaNotJoined    = errors.New("join the trivia before answering")
+	ErrQuestionClosed     = errors.New("question is not open for answers")
+	ErrAlreadyAnswered    = errors.New("question already answered")
+)
+
+// TriviaRoom is the hub room players of a live tournament join.
+func TriviaRoom(triviaID uint) string {
+	return fmt.Sprintf("trivia:%d", triviaID)
+}
+
+// LiveTriviaManager runs the game loop of every live tournament in progress.
+type LiveTriviaManager struct {
+	db  *gorm.DB
+	hub *ws.Hub
+
+	mu    sync.Mutex
+	games map[uint]*liveGame
+
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+type liveGame struct {
+	mu           sync.Mutex
+	trivia       store.TriviaTournament
+	questions    []map[string]interface{}
+	participants map[uint]uint // user ID -> participant ID
+	index        int
+	open         bool
+	openedAt     time.Time
+	deadline     time.Time
+	answers      map[uint]liveAnswer
+	allAnswered  chan struct{}
+}
+
+type liveAnswer struct {
+	answer string
+	at     time.Time
+}
+
+var LiveTrivia *LiveTriviaManager
+
+// InitLiveTrivia sets up the live trivia manager. Start must be called for
+// games to begin.
+func InitLiveTrivia(db *gorm.DB, h *ws.Hub) {
+	LiveTrivia = &LiveTriviaManager{
+		db:    db,
+		hub:   h,
+		games: make(map[uint]*liveGame),
+	}
+}
+
+// Start starts live tournaments once their start date passes, checking every
+// interval. Tournaments that were interrupted by a restart resume from their
+// next unplayed question.
+func (m *LiveTriviaManager) Start(interval time.Duration) {
+	ctx, stop := context.WithCancel(context.Background())
+	m.stop = stop
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			m.startDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops starting tournaments and interrupts the running ones, which
+// replay their open question after a restart. It waits for the game loops to
+// exit or ctx to expire.
+func (m *LiveTriviaManager) Shutdown(ctx context.Context) error {
+	if m.stop == nil {
+		return nil
+	}
+	m.stop()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *LiveTriviaManager) startDue(ctx context.Context) {
+	var due []store.TriviaTournament
+	err := m.db.Where("mode = ? AND status IN ('upcoming', 'active') AND start_date <= ?", store.TriviaModeLive, time.Now()).
+		Find(&due).Error
+	if err != nil {
+		log.Printf("live trivia: failed to load due tournaments: %v", err)
+		return
+	}
+
+	for _, trivia := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		m.mu.Lock()
+		_, running := m.games[trivia.ID]
+		m.mu.Unlock()
+		if running {
+			continue
+		}
+
+		if trivia.Status == "upcoming" {
+			result := m.db.Model(&store.TriviaTournament{}).
+				Where("id = ? AND status = 'upcoming'", trivia.ID).
+				Update("status", "active")
+			if result.Error != nil || result.RowsAffected == 0 {
+				continue
+			}
+			trivia.Status = "active"
+		}
+
+		// Register the game before loading its participants: a player who
+		// joins meanwhile is either added by Join or already in the table
+		game := &liveGame{
+			trivia:       trivia,
+			participants: make(map[uint]uint),
+			index:        -1,
+		}
+		m.mu.Lock()
+		m.games[trivia.ID] = game
+		m.mu.Unlock()
+
+		if err := m.loadGame(game); err != nil {
+			log.Printf("live trivia %d: failed to start: %v", trivia.ID, err)
+			m.mu.Lock()
+			delete(m.games, trivia.ID)
+			m.mu.Unlock()
+			continue
+		}
+
+		m.wg.Add(1)
+		go m.play(ctx, game)
+	}
+}
+
+// loadGame loads the questions and the players who joined before the game
+// was registered.
+func (m *LiveTriviaManager) loadGame(game *liveGame) error {
+	questions, err := game.trivia.ParseQuestions()
+	if err != nil {
+		return err
+	}
+	participants, err := store.GetTriviaParticipantIDs(m.db, game.trivia.ID)
+	if err != nil {
+		return err
+	}
+
+	game.mu.Lock()
+	defer game.mu.Unlock()
+	game.questions = questions
+	for userID, participantID := range participants {
+		game.participants[userID] = participantID
+	}
+	return nil
+}
+
+// play drives one tournament from its current question to the end, or until
+// ctx is cancelled.
+func (m *LiveTriviaManager) play(ctx context.Context, game *liveGame) {
+	trivia := game.trivia
+	room := TriviaRoom(trivia.ID)
+
+	defer func() {
+		m.mu.Lock()
+		delete(m.games, trivia.ID)
+		m.mu.Unlock()
+		m.wg.Done()
+	}()
+
+	m.hub.SendToRoom(room, ws.Encode(MessageTriviaStarted, map[string]interface{}{
+		"trivia_id":        trivia.ID,
+		"question_count":   len(game.questions),
+		"question_seconds": trivia.QuestionSeconds,
+		"current_question": trivia.CurrentQuestion,
+	}))
+	if trivia.CurrentQuestion == 0 && !sleepCtx(ctx, triviaLeadIn) {
+		return
+	}
+
+	window := time.Duration(trivia.QuestionSeconds) * time.Second
+	for i := trivia.CurrentQuestion; i < len(game.questions); i++ {
+		allAnswered := game.openQuestion(i, window)
+		m.hub.SendToRoom(room, ws.Encode(MessageTriviaQuestion, game.questionPayload()))
+
+		timer := time.NewTimer(window)
+		select {
+		case <-timer.C:
+		case <-allAnswered:
+			timer.Stop()
+		case <-ctx.Done():
+			// Left unscored, so the question is asked again on resume
+			timer.Stop()
+			return
+		}
+
+		if err := m.closeQuestion(game); err != nil {
+			log.Printf("live trivia %d: failed to score question %d: %v", trivia.ID, i, err)
+		}
+
+		if i < len(game.questions)-1 && !sleepCtx(ctx, triviaIntermission) {
+			return
+		}
+	}
+
+	if err := m.finish(game); err != nil {
+		log.Printf("live trivia %d: failed to finish: %v", trivia.ID, err)
+	}
+}
+
+// sleepCtx waits for d and reports whether ctx was still live afterwards.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// openQuestion starts accepting answers for question i and returns a channel
+// that is closed once every participant has answered.
+func (g *liveGame) openQuestion(i int, window time.Duration) <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.index = i
+	g.open = true
+	g.openedAt = time.Now()
+	g.deadline = g.openedAt.Add(window)
+	g.answers = make(map[uint]liveAnswer)
+	g.allAnswered = make(chan struct{})
+	return g.allAnswered
+}
+
+// questionPayload is the current question without its answer.
+func (g *liveGame) questionPayload() map[string]interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	question := make(map[string]interface{}, len(g.questions[g.index]))
+	for k, v := range g.questions[g.index] {
+		if k != "correct_answer" {
+			question[k] = v
+		}
+	}
+	return map[string]interface{}{
+		"trivia_id":      g.trivia.ID,
+		"question_id":    g.index,
+		"question_count": len(g.questions),
+		"question":       question,
+		"deadline":       g.deadline,
+		"seconds":        g.trivia.QuestionSeconds,
+	}
+}
+
+// closeQuestion stops accepting answers, scores them, persists the running
+// totals and broadcasts the result with the new standings.
+func (m *LiveTriviaManager) closeQuestion(game *liveGame) error {
+	game.mu.Lock()
+	game.open = false
+	index := game.index
+	openedAt := game.openedAt
+	answers := game.answers
+	participants := make(map[uint]uint, len(answers))
+	for userID := range answers {
+		participants[userID] = game.participants[userID]
+	}
+	game.mu.Unlock()
+
+	trivia := game.trivia
+	correctAnswer, _ := game.questions[index]["correct_answer"].(string)
+	window := time.Duration(trivia.QuestionSeconds) * time.Second
+
+	results := make(map[uint]map[string]interface{}, len(answers))
+	records := make([]store.TriviaAnswer, 0, len(answers))
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		for userID, answer := range answers {
+			elapsed := answer.at.Sub(openedAt)
+			correct := answer.answer == correctAnswer
+			points := 0
+			if correct {
+				remaining := math.Max(0, float64(window-elapsed)/float64(window))
+				points = triviaBasePoints + int(math.Round(triviaSpeedBonus*remaining))
+			}
+			seconds := int(math.Ceil(elapsed.Seconds()))
+			if err := store.AddTriviaScore(tx, trivia.ID, userID, points, correct, seconds); err != nil {
+				return err
+			}
+			records = append(records, store.TriviaAnswer{
+				ParticipantID: participants[userID],
+				TriviaID:      trivia.ID,
+				QuestionIndex: index,
+				Answer:        answer.answer,
+				IsCorrect:     correct,
+				AnsweredAt:    answer.at,
+			})
+			results[userID] = map[string]interface{}{
+				"trivia_id":      trivia.ID,
+				"question_id":    index,
+				"correct":        correct,
+				"points":         points,
+				"correct_answer": correctAnswer,
+			}
+		}
+		if err := store.CreateTriviaAnswers(tx, records); err != nil {
+			return err
+		}
+		if err := store.RankTriviaParticipants(tx, trivia.ID); err != nil {
+			return err
+		}
+		return tx.Model(&store.TriviaTournament{}).Where("id = ?", trivia.ID).
+			Update("current_question", index+1).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for userID, result := range results {
+		m.hub.SendToUser(userID, ws.Encode(MessageTriviaAnswerResult, result))
+	}
+
+	standings, err := store.GetTriviaStandings(m.db, trivia.ID, triviaStandingsSize)
+	if err != nil {
+		return err
+	}
+	m.hub.SendToRoom(TriviaRoom(trivia.ID), ws.Encode(MessageTriviaQuestionResult, map[string]interface{}{
+		"trivia_id":      trivia.ID,
+		"question_id":    index,
+		"correct_answer": correctAnswer,
+		"answered":       len(answers),
+		"standings":      standings,
+	}))
+	return nil
+}
+
+// finish completes the tournament, awards XP for the final scores and
+// broadcasts the final standings.
+func (m *LiveTriviaManager) finish(game *liveGame) error {
+	trivia := game.trivia
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&store.TriviaTournament{}).
+			Where("id = ? AND status = 'active'", trivia.ID).
+			Update("status", "completed")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var participants []store.TriviaParticipant
+		if err := tx.Where("trivia_id = ? AND score > 0", trivia.ID).Find(&participants).Error; err != nil {
+			return err
+		}
+		for _, p := range participants {
+			if p.UserID == nil {
+				continue
+			}
+			if _, err := store.AwardXP(tx, uint(*p.UserID), p.Score*triviaXPPerPoint, "quiz", "trivia", trivia.ID, "Live trivia reward"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	standings, err := store.GetTriviaStandings(m.db, trivia.ID, triviaStandingsSize)
+	if err != nil {
+		return err
+	}
+	m.hub.SendToRoom(TriviaRoom(trivia.ID), ws.Encode(MessageTriviaFinished, map[string]interface{}{
+		"trivia_id":    trivia.ID,
+		"xp_per_point": triviaXPPerPoint,
+		"standings":    standings,
+	}))
+	return nil
+}
+
+// Join makes the user a participant of a live tournament, charging the entry
+// fee on first join, and returns the state a (re)joining player needs.
+func (m *LiveTriviaManager) Join(user *store.User, triviaID uint) (map[string]interface{}, error) {
+	trivia, err := store.GetTriviaTournamentByID(m.db, triviaID)
+	if err != nil {
+		return nil, err
+	}
+	if trivia.Mode != store.TriviaModeLive {
+		return nil, ErrTriviaNotLive
+	}
+
+	participant, err := store.GetTriviaParticipant(m.db, trivia.ID, user.ID)
+	if err != nil {
+		if trivia.Status == "completed" || time.Now().After(trivia.EndDate) {
+			return nil, ErrTriviaNotJoinable
+		}
+		err = m.db.Transaction(func(tx *gorm.DB) error {
+			if trivia.MaxParticipants != nil {
+				count, err := store.CountTriviaParticipants(tx, trivia.ID)
+				if err != nil {
+					return err
+				}
+				if count >= int64(*trivia.MaxParticipants) {
+					return ErrTriviaFull
+				}
+			}
+
+			var joined bool
+			participant, joined, err = store.JoinTriviaTournament(tx, trivia.ID, user.ID)
+			if err != nil || !joined || trivia.EntryFeeXP <= 0 {
+				return err
+			}
+			var balance int
+			if err := tx.Model(&store.User{}).Where("id = ?", user.ID).Select("xp").Scan(&balance).Error; err != nil {
+				return err
+			}
+			if balance < trivia.EntryFeeXP {
+				return ErrTriviaInsufficient
+			}
+			_, err = store.AwardXP(tx, user.ID, -trivia.EntryFeeXP, "quiz", "trivia", trivia.ID, "Trivia entry fee")
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	state := map[string]interface{}{
+		"trivia_id":        trivia.ID,
+		"title":            trivia.Title,
+		"status":           trivia.Status,
+		"start_date":       trivia.StartDate,
+		"question_seconds": trivia.QuestionSeconds,
+		"score":            participant.Score,
+		"correct_answers":  participant.CorrectAnswers,
+		"rank":             participant.Rank,
+	}
+
+	m.mu.Lock()
+	game := m.games[trivia.ID]
+	m.mu.Unlock()
+	if game != nil {
+		game.mu.Lock()
+		game.participants[user.ID] = participant.ID
+		open := game.open
+		_, answered := game.answers[user.ID]
+		game.mu.Unlock()
+
+		state["status"] = "active"
+		if open {
+			question := game.questionPayload()
+			question["answered"] = answered
+			state["question"] = question
+		}
+	}
+	return state, nil
+}
+
+// Answer records a player's answer to the open question. Only the first
+// answer before the deadline counts.
+func (m *LiveTriviaManager) Answer(triviaID, userID uint, questionID int, answer string) error {
+	now := time.Now()
+
+	m.mu.Lock()
+	game := m.games[triviaID]
+	m.mu.Unlock()
+	if game == nil {
+		return ErrTriviaNotRunning
+	}
+
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if _, ok := game.participants[userID]; !ok {
+		return ErrTriviaNotJoined
+	}
+	if !game.open || game.index != questionID || now.After(game.deadline) {
+		return ErrQuestionClosed
+	}
+	if _, ok := game.answers[userID]; ok {
+		return ErrAlreadyAnswered
+	}
+
+	game.answers[userID] = liveAnswer{answer: answer, at: now}
+	if game.allAnswered != nil && len(game.answers) >= len(game.participants) {
+		close(game.allAnswered)
+		game.allAnswered = nil
+	}
+	return nil
+}
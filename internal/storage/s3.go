@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// S3Client is the subset of an S3-compatible API the storage layer needs.
+// Any SDK client (AWS, MinIO, R2, ...) can be adapted to it.
+type S3Client interface {
+	PutObject(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) error
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// S3Storage stores files in a bucket of an S3-compatible service.
+type S3Storage struct {
+	Client S3Client
+	Bucket string
+	Prefix string
+}
+
+func NewS3Storage(client S3Client, bucket, prefix string) *S3Storage {
+	return &S3Storage{Client: client, Bucket: bucket, Prefix: prefix}
+}
+
+func (s *S3Storage) objectKey(key string) string {
+	if s.Prefix == "" {
+		return key
+	}
+	return s.Prefix + "/" + key
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error {
+	if !ValidKey(key) {
+		return ErrNotFound
+	}
+	return s.Client.PutObject(ctx, s.Bucket, s.objectKey(key), data, size, contentType)
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !ValidKey(key) {
+		return nil, ErrNotFound
+	}
+	return s.Client.GetObject(ctx, s.Bucket, s.objectKey(key))
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if !ValidKey(key) {
+		return ErrNotFound
+	}
+	return s.Client.DeleteObject(ctx, s.Bucket, s.objectKey(key))
+}
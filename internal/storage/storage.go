@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/rohit21755/gg_server.git/internal/env"
+)
+
+var (
+	ErrNotFound        = errors.New("file not found")
+	ErrTooLarge        = errors.New("file is too large")
+	ErrUnsupportedType = errors.New("file type is not allowed")
+	ErrMissingFile     = errors.New("file is required")
+)
+
+// Object describes a stored file.
+type Object struct {
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// Storage is a blob store addressed by key.
+type Storage interface {
+	Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var Files Storage
+
+// Init configures the storage backend from STORAGE_DRIVER. Only the local
+// driver can be built from the environment; S3-compatible backends are wired
+// with NewS3Storage.
+func Init() {
+	switch env.Get("STORAGE_DRIVER", "local") {
+	default:
+		Files = NewLocalStorage(env.Get("STORAGE_LOCAL_DIR", "uploads"))
+	}
+}
+
+// Save reads an upload, sniffs its real MIME type from the content, checks it
+// against the allowed types and stores it under a content-addressed key inside
+// folder. Identical bytes always map to the same key.
+func Save(ctx context.Context, folder string, data io.Reader, maxSize int64, allowed map[string]string) (*Object, error) {
+	if Files == nil {
+		return nil, errors.New("storage not initialized")
+	}
+
+	content, err := io.ReadAll(io.LimitReader(data, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSize {
+		return nil, ErrTooLarge
+	}
+
+	contentType := mimetype.Detect(content).String()
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	ext, ok := allowed[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+	key := fmt.Sprintf("%s/%s/%s%s", folder, digest[:2], digest, ext)
+
+	if err := Files.Put(ctx, key, bytes.NewReader(content), int64(len(content)), contentType); err != nil {
+		return nil, err
+	}
+
+	return &Object{Key: key, ContentType: contentType, Size: int64(len(content))}, nil
+}
+
+// ValidKey reports whether a key is safe to hand to a backend.
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
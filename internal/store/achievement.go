@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Achievement represents an achievement definition
@@ -15,6 +16,7 @@ type Achievement struct {
 	Category    string    `gorm:"size:50" json:"category"` // submission, streak, xp, referral, etc.
 	XP          int       `gorm:"default:0" json:"xp"`
 	Coins       int       `gorm:"default:0" json:"coins"`
+	Threshold   int       `gorm:"not null;default:0" json:"threshold"` // value of the category's stat that earns it
 	IsActive    bool      `gorm:"default:true" json:"is_active"`
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
@@ -28,6 +30,9 @@ type UserAchievement struct {
 	UserID        uint      `gorm:"not null;index" json:"user_id"`
 	AchievementID uint      `gorm:"not null;index" json:"achievement_id"`
 	EarnedAt      time.Time `gorm:"autoCreateTime" json:"earned_at"`
+
+	// Relations
+	Achievement *Achievement `gorm:"foreignKey:AchievementID" json:"achievement,omitempty"`
 }
 
 func (UserAchievement) TableName() string { return "user_achievements" }
@@ -60,24 +65,15 @@ func GetUserAchievements(db *gorm.DB, userID uint) ([]UserAchievement, error) {
 	return userAchievements, nil
 }
 
-func AwardAchievement(db *gorm.DB, userID, achievementID uint) error {
-	// Check if already awarded
-	var existing UserAchievement
-	err := db.Where("user_id = ? AND achievement_id = ?", userID, achievementID).
-		First(&existing).Error
-	if err == nil {
-		return nil // Already awarded
-	}
-	if err != gorm.ErrRecordNotFound {
-		return err
-	}
-
-	// Award achievement
+// AwardAchievement records that a user earned an achievement. It reports
+// false if they already had it.
+func AwardAchievement(db *gorm.DB, userID, achievementID uint) (bool, error) {
 	userAchievement := UserAchievement{
 		UserID:        userID,
 		AchievementID: achievementID,
 	}
-	return db.Create(&userAchievement).Error
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&userAchievement)
+	return result.RowsAffected > 0, result.Error
 }
 
 func CreateAchievement(db *gorm.DB, achievement *Achievement) error {
@@ -92,3 +88,140 @@ func DeleteAchievement(db *gorm.DB, id uint) error {
 	return db.Delete(&Achievement{}, id).Error
 }
 
+// Badge criteria types checked automatically. "special" badges are only given
+// by hand or as event rewards.
+const (
+	CriteriaSubmissionCount = "submission_count"
+	CriteriaReferralCount   = "referral_count"
+	CriteriaCampaignCount   = "campaign_count"
+	CriteriaStreakDays      = "streak_days"
+	CriteriaXPTotal         = "xp_total"
+	CriteriaJoinDate        = "join_date"
+)
+
+// AutoCriteriaTypes lists the badge criteria types the engine evaluates.
+var AutoCriteriaTypes = []string{
+	CriteriaSubmissionCount, CriteriaReferralCount, CriteriaCampaignCount,
+	CriteriaStreakDays, CriteriaXPTotal, CriteriaJoinDate,
+}
+
+// AchievementStats are the counters badge criteria and achievements are
+// checked against.
+type AchievementStats struct {
+	XP                  int
+	ApprovedSubmissions int
+	Referrals           int
+	CampaignsCompleted  int
+	LongestStreak       int
+	Posts               int
+	JoinedAt            time.Time
+}
+
+// Value returns the stat measured by a badge criteria type or an achievement
+// category, and false if there is none.
+func (s *AchievementStats) Value(metric string) (int, bool) {
+	switch metric {
+	case CriteriaXPTotal, "xp":
+		return s.XP, true
+	case CriteriaSubmissionCount, "submission":
+		return s.ApprovedSubmissions, true
+	case CriteriaReferralCount, "referral":
+		return s.Referrals, true
+	case CriteriaCampaignCount:
+		return s.CampaignsCompleted, true
+	case CriteriaStreakDays, "streak":
+		return s.LongestStreak, true
+	case "social":
+		return s.Posts, true
+	}
+	return 0, false
+}
+
+// GetAchievementStats gathers a user's counters. Referrals count once the
+// referred user has joined, campaigns once they are completed with an
+// approved submission from the user, and posts exclude the automatic
+// achievement posts.
+func GetAchievementStats(db *gorm.DB, user *User) (*AchievementStats, error) {
+	stats := &AchievementStats{XP: user.XP, JoinedAt: user.CreatedAt}
+	var count int64
+
+	if err := db.Model(&Submission{}).Where("user_id = ? AND status = 'approved'", user.ID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	stats.ApprovedSubmissions = int(count)
+
+	if err := db.Model(&Referral{}).Where("referrer_id = ? AND referred_user_id IS NOT NULL", user.ID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	stats.Referrals = int(count)
+
+	if err := db.Model(&Submission{}).
+		Joins("JOIN campaigns ON campaigns.id = submissions.campaign_id").
+		Where("submissions.user_id = ? AND submissions.status = 'approved' AND campaigns.status = 'completed'", user.ID).
+		Distinct("submissions.campaign_id").
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	stats.CampaignsCompleted = int(count)
+
+	if err := db.Model(&UserStreak{}).Where("user_id = ?", user.ID).Select("COALESCE(MAX(longest_streak), 0)").Scan(&stats.LongestStreak).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Model(&SocialPost{}).
+		Where("user_id = ? AND deleted_at IS NULL AND post_type <> 'achievement'", user.ID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	stats.Posts = int(count)
+
+	return stats, nil
+}
+
+// Available reports whether the badge can still be earned at now. Limited
+// edition badges close at AvailableUntil.
+func (b *Badge) Available(now time.Time) bool {
+	return b.AvailableUntil == nil || now.Before(*b.AvailableUntil)
+}
+
+// Qualifies reports whether the stats meet the badge's criteria. join_date
+// badges go to users who joined before AvailableUntil, so they need one.
+func (b *Badge) Qualifies(stats *AchievementStats, now time.Time) bool {
+	if !b.Available(now) {
+		return false
+	}
+	if b.CriteriaType == CriteriaJoinDate {
+		return b.AvailableUntil != nil && stats.JoinedAt.Before(*b.AvailableUntil)
+	}
+	value, ok := stats.Value(b.CriteriaType)
+	return ok && b.CriteriaValue > 0 && value >= b.CriteriaValue
+}
+
+// Qualifies reports whether the stats meet the achievement's threshold.
+func (a *Achievement) Qualifies(stats *AchievementStats) bool {
+	value, ok := stats.Value(a.Category)
+	return ok && a.Threshold > 0 && value >= a.Threshold
+}
+
+// GetUnearnedBadges lists the automatically awarded badges the user does not
+// hold and can still earn at now.
+func GetUnearnedBadges(db *gorm.DB, userID uint, now time.Time) ([]Badge, error) {
+	var badges []Badge
+	err := db.Where("criteria_type IN ?", AutoCriteriaTypes).
+		Where("available_until IS NULL OR available_until > ?", now).
+		Where("id NOT IN (?)", db.Model(&UserBadge{}).Select("badge_id").Where("user_id = ? AND badge_id IS NOT NULL", userID)).
+		Order("id ASC").
+		Find(&badges).Error
+	return badges, err
+}
+
+// GetUnearnedAchievements lists the active achievements the user has not
+// earned.
+func GetUnearnedAchievements(db *gorm.DB, userID uint) ([]Achievement, error) {
+	var achievements []Achievement
+	err := db.Where("is_active = ?", true).
+		Where("id NOT IN (?)", db.Model(&UserAchievement{}).Select("achievement_id").Where("user_id = ?", userID)).
+		Order("id ASC").
+		Find(&achievements).Error
+	return achievements, err
+}
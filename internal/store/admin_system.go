@@ -52,6 +52,7 @@ type ScheduledJob struct {
 	Result       *string    `gorm:"type:jsonb"`
 	Attempts     int        `gorm:"default:0"`
 	MaxAttempts  int        `gorm:"default:3"`
+	IsRecurring  bool       `gorm:"not null;default:false"`
 	ErrorMessage *string    `gorm:"type:text"`
 	StartedAt    *time.Time `gorm:"type:timestamp"`
 	CompletedAt  *time.Time `gorm:"type:timestamp"`
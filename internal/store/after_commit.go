@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// commitPool wraps the connection pool so that every transaction begun on it,
+// whether through db.Transaction, db.Begin or gorm's implicit per-statement
+// transactions, can run callbacks once it has committed.
+type commitPool struct {
+	gorm.ConnPool
+	root *gorm.DB
+}
+
+// TrackCommits makes AfterCommit defer its callbacks until the surrounding
+// transaction commits. It is called once on the root connection at startup.
+func TrackCommits(db *gorm.DB) {
+	pool := &commitPool{ConnPool: db.ConnPool, root: db}
+	db.ConnPool = pool
+	db.Statement.ConnPool = pool
+}
+
+func (p *commitPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	beginner, ok := p.ConnPool.(gorm.TxBeginner)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+	tx, err := beginner.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &commitTx{Tx: tx, root: p.root}, nil
+}
+
+// GetDBConn lets gorm's DB() reach the wrapped *sql.DB.
+func (p *commitPool) GetDBConn() (*sql.DB, error) {
+	if sqlDB, ok := p.ConnPool.(*sql.DB); ok {
+		return sqlDB, nil
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// commitTx is a transaction that runs its queued callbacks after a successful
+// commit. A rollback drops them.
+type commitTx struct {
+	*sql.Tx
+	root *gorm.DB
+
+	mu        sync.Mutex
+	callbacks []func(db *gorm.DB)
+}
+
+func (t *commitTx) Commit() error {
+	if err := t.Tx.Commit(); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	callbacks := t.callbacks
+	t.callbacks = nil
+	t.mu.Unlock()
+
+	db := t.root.Session(&gorm.Session{NewDB: true})
+	for _, fn := range callbacks {
+		fn(db)
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the transaction db belongs to has committed, or
+// straight away outside a transaction. fn gets a handle on the connection
+// pool, since the transaction is finished by then. Callbacks registered
+// inside a nested transaction that rolls back to its savepoint still run if
+// the outer transaction commits.
+func AfterCommit(db *gorm.DB, fn func(db *gorm.DB)) {
+	if tx, ok := db.Statement.ConnPool.(*commitTx); ok {
+		tx.mu.Lock()
+		tx.callbacks = append(tx.callbacks, fn)
+		tx.mu.Unlock()
+		return
+	}
+	fn(db.Session(&gorm.Session{NewDB: true}))
+}
@@ -1,9 +1,14 @@
 package store
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BadgeBingo struct {
@@ -65,3 +70,379 @@ func GetUserBingoProgressByID(db *gorm.DB, id uint) (*UserBingoProgress, error)
 	}
 	return &progress, nil
 }
+
+// BingoCell is one square of a bingo card. It is marked by earning BadgeID,
+// by an activity event matching Activity (optionally limited to a campaign or
+// task), or is a free square marked from the start.
+type BingoCell struct {
+	Label      string `json:"label,omitempty"`
+	BadgeID    *int   `json:"badge_id,omitempty"`
+	Activity   string `json:"activity,omitempty"`
+	CampaignID *int   `json:"campaign_id,omitempty"`
+	TaskID     *int   `json:"task_id,omitempty"`
+	Free       bool   `json:"free,omitempty"`
+}
+
+// BingoCard is the shape of BadgeBingo.BingoCard: a square grid of cells in
+// row-major order, e.g.
+//
+//	{"size": 3, "cells": [{"badge_id": 4, "label": "First Post"},
+//	                      {"activity": "referral", "label": "Invite a friend"},
+//	                      {"free": true}, ...]}
+type BingoCard struct {
+	Size  int         `json:"size"`
+	Cells []BingoCell `json:"cells"`
+}
+
+// Bingo line kinds.
+const (
+	BingoLineRow      = "row"
+	BingoLineColumn   = "column"
+	BingoLineDiagonal = "diagonal"
+)
+
+// BingoLine is a row, column or diagonal of a card. Diagonal 0 runs from the
+// top left, diagonal 1 from the top right.
+type BingoLine struct {
+	Kind  string `json:"kind"`
+	Index int    `json:"index"`
+}
+
+// Key identifies the line in UserBingoProgress.RewardsClaimed.
+func (l BingoLine) Key() string {
+	return fmt.Sprintf("%s:%d", l.Kind, l.Index)
+}
+
+// Lines lists every row, column and diagonal of the card.
+func (c *BingoCard) Lines() []BingoLine {
+	lines := make([]BingoLine, 0, 2*c.Size+2)
+	for i := 0; i < c.Size; i++ {
+		lines = append(lines, BingoLine{Kind: BingoLineRow, Index: i})
+	}
+	for i := 0; i < c.Size; i++ {
+		lines = append(lines, BingoLine{Kind: BingoLineColumn, Index: i})
+	}
+	return append(lines, BingoLine{Kind: BingoLineDiagonal, Index: 0}, BingoLine{Kind: BingoLineDiagonal, Index: 1})
+}
+
+// LineCells returns the indexes of the cells on a line.
+func (c *BingoCard) LineCells(line BingoLine) []int {
+	cells := make([]int, c.Size)
+	for i := 0; i < c.Size; i++ {
+		switch line.Kind {
+		case BingoLineRow:
+			cells[i] = line.Index*c.Size + i
+		case BingoLineColumn:
+			cells[i] = i*c.Size + line.Index
+		case BingoLineDiagonal:
+			if line.Index == 0 {
+				cells[i] = i*c.Size + i
+			} else {
+				cells[i] = i*c.Size + c.Size - 1 - i
+			}
+		}
+	}
+	return cells
+}
+
+// BingoEvent is something a user did that may mark bingo cells: earning a
+// badge or an activity such as an approved submission.
+type BingoEvent struct {
+	BadgeID  *int
+	Activity *QuestEvent
+}
+
+// Matches reports whether the event marks the cell.
+func (c BingoCell) Matches(event BingoEvent) bool {
+	if c.BadgeID != nil {
+		return event.BadgeID != nil && *event.BadgeID == *c.BadgeID
+	}
+	if c.Activity != "" && event.Activity != nil {
+		step := QuestStep{Type: c.Activity, CampaignID: c.CampaignID, TaskID: c.TaskID}
+		return step.Matches(*event.Activity)
+	}
+	return false
+}
+
+// ParseCard decodes and checks the bingo's card. Cards are 3x3 to 5x5 and
+// every cell is exactly one of a badge, an activity or a free square.
+func (b *BadgeBingo) ParseCard() (*BingoCard, error) {
+	var card BingoCard
+	if err := json.Unmarshal([]byte(b.BingoCard), &card); err != nil {
+		return nil, err
+	}
+	if card.Size < 3 || card.Size > 5 {
+		return nil, errors.New("card size must be between 3 and 5")
+	}
+	if len(card.Cells) != card.Size*card.Size {
+		return nil, fmt.Errorf("a %dx%d card needs %d cells, got %d", card.Size, card.Size, card.Size*card.Size, len(card.Cells))
+	}
+	for i, cell := range card.Cells {
+		kinds := 0
+		if cell.BadgeID != nil {
+			kinds++
+		}
+		if cell.Activity != "" {
+			if !questStepTypes[cell.Activity] {
+				return nil, fmt.Errorf("cell %d has unknown activity %q", i+1, cell.Activity)
+			}
+			kinds++
+		}
+		if cell.Free {
+			kinds++
+		}
+		if kinds != 1 {
+			return nil, fmt.Errorf("cell %d must have exactly one of badge_id, activity or free", i+1)
+		}
+	}
+	return &card, nil
+}
+
+// BingoRewards is the shape of BadgeBingo.BundleRewards: the bundle paid for
+// each completed line of a kind, e.g.
+//
+//	{"row": {"xp": 100}, "column": {"xp": 100}, "diagonal": {"xp": 150, "badge_id": 12}}
+type BingoRewards struct {
+	Row      *Reward `json:"row,omitempty"`
+	Column   *Reward `json:"column,omitempty"`
+	Diagonal *Reward `json:"diagonal,omitempty"`
+}
+
+// For returns the bundle for a line kind, or nil if it pays nothing.
+func (r BingoRewards) For(kind string) *Reward {
+	switch kind {
+	case BingoLineRow:
+		return r.Row
+	case BingoLineColumn:
+		return r.Column
+	case BingoLineDiagonal:
+		return r.Diagonal
+	}
+	return nil
+}
+
+// ParseRewards decodes the bingo's bundle rewards.
+func (b *BadgeBingo) ParseRewards() (BingoRewards, error) {
+	var rewards BingoRewards
+	if b.BundleRewards == nil || *b.BundleRewards == "" {
+		return rewards, nil
+	}
+	err := json.Unmarshal([]byte(*b.BundleRewards), &rewards)
+	return rewards, err
+}
+
+// MarkedCells decodes the indexes of the cells the user has marked.
+func (p *UserBingoProgress) MarkedCells() []int {
+	var cells []int
+	if p.CompletedCells != nil {
+		json.Unmarshal([]byte(*p.CompletedCells), &cells)
+	}
+	return cells
+}
+
+// ClaimedLines decodes the keys of the lines whose bundle has been paid.
+func (p *UserBingoProgress) ClaimedLines() []string {
+	var lines []string
+	if p.RewardsClaimed != nil {
+		json.Unmarshal([]byte(*p.RewardsClaimed), &lines)
+	}
+	return lines
+}
+
+// ListBadgeBingos lists every bingo, newest first.
+func ListBadgeBingos(db *gorm.DB) ([]BadgeBingo, error) {
+	var bingos []BadgeBingo
+	err := db.Order("start_date DESC, id DESC").Find(&bingos).Error
+	return bingos, err
+}
+
+// GetActiveBadgeBingos lists the bingos running at now.
+func GetActiveBadgeBingos(db *gorm.DB, now time.Time) ([]BadgeBingo, error) {
+	var bingos []BadgeBingo
+	err := db.Where("is_active = ? AND start_date <= ? AND end_date > ?", true, now, now).
+		Order("end_date ASC, id ASC").
+		Find(&bingos).Error
+	return bingos, err
+}
+
+// GetUserBingoProgress returns a user's progress on a bingo.
+func GetUserBingoProgress(db *gorm.DB, userID, bingoID uint) (*UserBingoProgress, error) {
+	var progress UserBingoProgress
+	if err := db.Where("user_id = ? AND bingo_id = ?", userID, bingoID).First(&progress).Error; err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+var ErrBingoNotEditable = errors.New("bingo can only be edited before it starts")
+
+// UpdateBadgeBingo saves a bingo's definition while it has not started yet,
+// so no card a user has marked can change under them.
+func UpdateBadgeBingo(db *gorm.DB, bingo *BadgeBingo) error {
+	result := db.Model(&BadgeBingo{}).
+		Where("id = ? AND start_date > ?", bingo.ID, time.Now()).
+		Select("title", "description", "bingo_card", "bundle_rewards", "start_date", "end_date", "is_active").
+		Updates(bingo)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBingoNotEditable
+	}
+	return nil
+}
+
+// BingoClaim is a line a user completed whose bundle is now due.
+type BingoClaim struct {
+	Bingo *BadgeBingo
+	Line  BingoLine
+}
+
+// MarkBingoCells marks every cell the event matches on the user's running
+// bingo cards and recounts their completed rows, columns and diagonals. Each
+// newly completed line is recorded in RewardsClaimed and returned once, so
+// its bundle is paid exactly once. A user's card is started on their first
+// event while the bingo runs, with the badges they already hold marked. A
+// bingo whose card does not parse is logged and skipped.
+func MarkBingoCells(db *gorm.DB, userID uint, event BingoEvent, now time.Time) ([]BingoClaim, error) {
+	bingos, err := GetActiveBadgeBingos(db, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims []BingoClaim
+	for i := range bingos {
+		bingo := &bingos[i]
+		card, err := bingo.ParseCard()
+		if err != nil {
+			log.Printf("bingo: skipping bingo %d with an invalid card: %v", bingo.ID, err)
+			continue
+		}
+
+		var matched []int
+		for index, cell := range card.Cells {
+			if cell.Matches(event) {
+				matched = append(matched, index)
+			}
+		}
+
+		userIDInt := int(userID)
+		bingoID := int(bingo.ID)
+		started := db.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&UserBingoProgress{UserID: &userIDInt, BingoID: &bingoID})
+		if started.Error != nil {
+			return nil, started.Error
+		}
+		if len(matched) == 0 && started.RowsAffected == 0 {
+			continue
+		}
+
+		var progress UserBingoProgress
+		if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND bingo_id = ?", userID, bingo.ID).
+			First(&progress).Error; err != nil {
+			return nil, err
+		}
+
+		marked := make(map[int]bool)
+		for _, index := range progress.MarkedCells() {
+			marked[index] = true
+		}
+		changed := false
+		if started.RowsAffected > 0 {
+			// Badges are awarded once, so those held before the card started
+			// can only be marked now
+			held, err := heldBingoBadges(db, userID, card)
+			if err != nil {
+				return nil, err
+			}
+			for index, cell := range card.Cells {
+				if cell.BadgeID != nil && held[*cell.BadgeID] && !marked[index] {
+					marked[index] = true
+					changed = true
+				}
+			}
+		}
+		for _, index := range matched {
+			if !marked[index] {
+				marked[index] = true
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		for index, cell := range card.Cells {
+			if cell.Free {
+				marked[index] = true
+			}
+		}
+
+		claimed := make(map[string]bool)
+		claimedKeys := progress.ClaimedLines()
+		for _, key := range claimedKeys {
+			claimed[key] = true
+		}
+		counts := map[string]int{}
+		for _, line := range card.Lines() {
+			complete := true
+			for _, index := range card.LineCells(line) {
+				if !marked[index] {
+					complete = false
+					break
+				}
+			}
+			if !complete {
+				continue
+			}
+			counts[line.Kind]++
+			if !claimed[line.Key()] {
+				claimedKeys = append(claimedKeys, line.Key())
+				claims = append(claims, BingoClaim{Bingo: bingo, Line: line})
+			}
+		}
+
+		cells := make([]int, 0, len(marked))
+		for index := range card.Cells {
+			if marked[index] {
+				cells = append(cells, index)
+			}
+		}
+		cellsJSON, _ := json.Marshal(cells)
+		claimedJSON, _ := json.Marshal(claimedKeys)
+		if err := db.Model(&UserBingoProgress{}).Where("id = ?", progress.ID).Updates(map[string]interface{}{
+			"completed_cells":     string(cellsJSON),
+			"completed_rows":      counts[BingoLineRow],
+			"completed_columns":   counts[BingoLineColumn],
+			"completed_diagonals": counts[BingoLineDiagonal],
+			"rewards_claimed":     string(claimedJSON),
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// heldBingoBadges returns which of the card's badges the user holds.
+func heldBingoBadges(db *gorm.DB, userID uint, card *BingoCard) (map[int]bool, error) {
+	var badgeIDs []int
+	for _, cell := range card.Cells {
+		if cell.BadgeID != nil {
+			badgeIDs = append(badgeIDs, *cell.BadgeID)
+		}
+	}
+	held := make(map[int]bool)
+	if len(badgeIDs) == 0 {
+		return held, nil
+	}
+
+	var heldIDs []int
+	if err := db.Model(&UserBadge{}).Where("user_id = ? AND badge_id IN ?", userID, badgeIDs).
+		Pluck("badge_id", &heldIDs).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range heldIDs {
+		held[id] = true
+	}
+	return held, nil
+}
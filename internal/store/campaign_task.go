@@ -1,6 +1,8 @@
 package store
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -131,3 +133,130 @@ func GetSubmissionsByUserAndTask(db *gorm.DB, userID uint, taskID uint) ([]Submi
 	}
 	return submissions, nil
 }
+
+func UpdateTask(db *gorm.DB, task *Task) error {
+	return db.Save(task).Error
+}
+
+func DeleteTask(db *gorm.DB, id uint) error {
+	return db.Delete(&Task{}, id).Error
+}
+
+func CountSubmissionsByTask(db *gorm.DB, taskID uint) (int64, error) {
+	var count int64
+	if err := db.Model(&Submission{}).Where("task_id = ?", taskID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func GetTaskAssignmentsByTask(db *gorm.DB, taskID uint) ([]TaskAssignment, error) {
+	var assignments []TaskAssignment
+	if err := db.Where("task_id = ?", taskID).Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// ReplaceTaskAssignments makes the given set the task's assignees. Assignees
+// that stay keep their row, and with it their status; removed ones are
+// deleted and new ones inserted.
+func ReplaceTaskAssignments(db *gorm.DB, taskID uint, assignments []TaskAssignment) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing []TaskAssignment
+		if err := tx.Where("task_id = ?", taskID).Find(&existing).Error; err != nil {
+			return err
+		}
+
+		wanted := make(map[string]bool, len(assignments))
+		var added []TaskAssignment
+		for _, assignment := range assignments {
+			key := assignment.assigneeKey()
+			if wanted[key] {
+				continue
+			}
+			wanted[key] = true
+			added = append(added, assignment)
+		}
+
+		var removed []uint
+		kept := make(map[string]bool, len(existing))
+		for _, assignment := range existing {
+			key := assignment.assigneeKey()
+			if wanted[key] && !kept[key] {
+				kept[key] = true
+				continue
+			}
+			removed = append(removed, assignment.ID)
+		}
+		if len(removed) > 0 {
+			if err := tx.Delete(&TaskAssignment{}, removed).Error; err != nil {
+				return err
+			}
+		}
+
+		var inserts []TaskAssignment
+		for _, assignment := range added {
+			if !kept[assignment.assigneeKey()] {
+				inserts = append(inserts, assignment)
+			}
+		}
+		if len(inserts) == 0 {
+			return nil
+		}
+		return tx.Create(&inserts).Error
+	})
+}
+
+// assigneeKey identifies who an assignment targets, regardless of its status.
+func (a TaskAssignment) assigneeKey() string {
+	key := a.AssigneeType
+	if a.AssigneeID != nil {
+		key += fmt.Sprintf(":%d", *a.AssigneeID)
+	}
+	if a.AssigneeRole != nil {
+		key += ":" + *a.AssigneeRole
+	}
+	return key
+}
+
+func DeleteCampaign(db *gorm.DB, id uint) error {
+	return db.Delete(&Campaign{}, id).Error
+}
+
+var ErrInvalidCampaignTransition = errors.New("invalid campaign status transition")
+
+// campaignTransitions lists the statuses a campaign may move to from each status.
+var campaignTransitions = map[string][]string{
+	"draft":  {"active", "cancelled"},
+	"active": {"paused", "completed", "cancelled"},
+	"paused": {"active", "completed", "cancelled"},
+}
+
+// CanTransitionCampaign reports whether a campaign may move from one status to another.
+func CanTransitionCampaign(from, to string) bool {
+	for _, next := range campaignTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionCampaign moves a campaign to a new status, enforcing the allowed transitions.
+func TransitionCampaign(db *gorm.DB, campaign *Campaign, to string) error {
+	if !CanTransitionCampaign(campaign.Status, to) {
+		return ErrInvalidCampaignTransition
+	}
+	result := db.Model(&Campaign{}).
+		Where("id = ? AND status = ?", campaign.ID, campaign.Status).
+		Update("status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidCampaignTransition
+	}
+	campaign.Status = to
+	return nil
+}
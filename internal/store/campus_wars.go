@@ -1,9 +1,15 @@
 package store
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CampusWar struct {
@@ -13,16 +19,107 @@ type CampusWar struct {
 	WarType   *string    `gorm:"size:50;check:war_type IN ('campus_vs_campus', 'state_vs_state', 'region_vs_region')"`
 	StartDate time.Time  `gorm:"not null"`
 	EndDate   time.Time  `gorm:"not null"`
-	Status    string     `gorm:"size:20;default:'upcoming';check:status IN ('upcoming', 'active', 'completed')"`
+	Status    string     `gorm:"size:20;default:'upcoming';check:status IN ('upcoming', 'active', 'completed', 'cancelled')"`
 	Metrics   *string    `gorm:"type:jsonb;default:'{\"xp\": true, \"submissions\": true, \"referrals\": true}'"`
 	Rewards   *string    `gorm:"type:jsonb;default:'{}'"`
+	CreatedBy *int       `gorm:"index"`
 	CreatedAt time.Time  `gorm:"autoCreateTime"`
+	SettledAt *time.Time
 }
 
 func (CampusWar) TableName() string {
 	return "campus_wars"
 }
 
+// WarMetrics weights each metric in a war's score.
+type WarMetrics struct {
+	XP          float64 `json:"xp"`
+	Submissions float64 `json:"submissions"`
+	Referrals   float64 `json:"referrals"`
+}
+
+// DefaultWarMetrics is the weighting of a metric enabled with true.
+var DefaultWarMetrics = WarMetrics{XP: 1, Submissions: 10, Referrals: 50}
+
+// ParseMetrics decodes the war's metrics. Each metric is either a boolean,
+// which enables it at its default weight, or a number used as the weight, e.g.
+// {"xp": true, "submissions": 25, "referrals": false}. Missing metrics count
+// for nothing.
+func (w *CampusWar) ParseMetrics() (WarMetrics, error) {
+	if w.Metrics == nil || *w.Metrics == "" {
+		return DefaultWarMetrics, nil
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(*w.Metrics), &raw); err != nil {
+		return WarMetrics{}, err
+	}
+
+	weight := func(key string, fallback float64) (float64, error) {
+		switch v := raw[key].(type) {
+		case nil:
+			return 0, nil
+		case bool:
+			if v {
+				return fallback, nil
+			}
+			return 0, nil
+		case float64:
+			if v < 0 {
+				return 0, fmt.Errorf("metric %q has a negative weight", key)
+			}
+			return v, nil
+		default:
+			return 0, fmt.Errorf("metric %q must be a boolean or a number", key)
+		}
+	}
+
+	var metrics WarMetrics
+	var err error
+	if metrics.XP, err = weight("xp", DefaultWarMetrics.XP); err != nil {
+		return WarMetrics{}, err
+	}
+	if metrics.Submissions, err = weight("submissions", DefaultWarMetrics.Submissions); err != nil {
+		return WarMetrics{}, err
+	}
+	if metrics.Referrals, err = weight("referrals", DefaultWarMetrics.Referrals); err != nil {
+		return WarMetrics{}, err
+	}
+	return metrics, nil
+}
+
+// Score weights a participant's totals.
+func (m WarMetrics) Score(p *WarParticipant) int {
+	return int(math.Round(float64(p.TotalXP)*m.XP + float64(p.TotalSubmissions)*m.Submissions + float64(p.TotalReferrals)*m.Referrals))
+}
+
+// WarRewards is the shape of CampusWar.Rewards: places[0] is paid to every
+// contributor of the winning college or state, places[1] to the runner-up's
+// and so on, e.g.
+//
+//	{"places": [{"xp": 300, "coins": 50, "badge_id": 9}, {"xp": 150}]}
+type WarRewards struct {
+	Places []Reward `json:"places"`
+}
+
+// ParseRewards decodes the war's rewards. An unset or empty value pays
+// nothing.
+func (w *CampusWar) ParseRewards() (WarRewards, error) {
+	var rewards WarRewards
+	if w.Rewards == nil || *w.Rewards == "" {
+		return rewards, nil
+	}
+	err := json.Unmarshal([]byte(*w.Rewards), &rewards)
+	return rewards, err
+}
+
+// scoredUntil is the end of the part of the war window that has passed.
+func (w *CampusWar) scoredUntil(now time.Time) time.Time {
+	if now.After(w.EndDate) {
+		return w.EndDate
+	}
+	return now
+}
+
 type WarParticipant struct {
 	ID              uint      `gorm:"primaryKey"`
 	WarID           *int      `gorm:"index;constraint:OnDelete:CASCADE"`
@@ -31,6 +128,7 @@ type WarParticipant struct {
 	TotalXP         int       `gorm:"default:0"`
 	TotalSubmissions int      `gorm:"default:0"`
 	TotalReferrals  int       `gorm:"default:0"`
+	Score           int       `gorm:"default:0"`
 	Rank            *int      `gorm:"type:integer"`
 	CreatedAt       time.Time `gorm:"autoCreateTime"`
 
@@ -45,7 +143,7 @@ func (WarParticipant) TableName() string {
 type WarLeaderboardSnapshot struct {
 	ID              uint      `gorm:"primaryKey"`
 	WarID           *int      `gorm:"index;constraint:OnDelete:CASCADE"`
-	SnapshotDate    time.Time `gorm:"type:date;not null"`
+	SnapshotDate    time.Time `gorm:"not null"`
 	LeaderboardData string    `gorm:"type:jsonb;not null"`
 	CreatedAt       time.Time `gorm:"autoCreateTime"`
 
@@ -69,10 +167,158 @@ func GetCampusWarByID(db *gorm.DB, id uint) (*CampusWar, error) {
 	return &war, nil
 }
 
+// UpdateCampusWar saves the definition of a war that has not started. The
+// status is checked in the update so a war the scheduler just started is not
+// edited.
+func UpdateCampusWar(db *gorm.DB, war *CampusWar) error {
+	result := db.Model(&CampusWar{}).
+		Where("id = ? AND status = 'upcoming'", war.ID).
+		Select("name", "description", "war_type", "start_date", "end_date", "metrics", "rewards").
+		Updates(war)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWarNotEditable
+	}
+	return nil
+}
+
+// WarEntityTypes maps each war type to the kind of entity that competes in it.
+// Region wars have no participant entity yet.
+var WarEntityTypes = map[string]string{
+	"campus_vs_campus": "college",
+	"state_vs_state":   "state",
+}
+
+// EntityType is the kind of participant the war enrols.
+func (w *CampusWar) EntityType() string {
+	if w.WarType == nil {
+		return ""
+	}
+	return WarEntityTypes[*w.WarType]
+}
+
+var (
+	ErrWarNotEditable    = errors.New("only upcoming wars can be edited")
+	ErrWarNotCancellable = errors.New("only upcoming or active wars can be cancelled")
+	ErrWarNotExtendable  = errors.New("only upcoming or active wars can be extended")
+)
+
+// CancelCampusWar stops a war that has not finished. Cancelled wars are never
+// scored or settled.
+func CancelCampusWar(db *gorm.DB, war *CampusWar) error {
+	result := db.Model(&CampusWar{}).
+		Where("id = ? AND status IN ('upcoming', 'active') AND settled_at IS NULL", war.ID).
+		Update("status", "cancelled")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWarNotCancellable
+	}
+	war.Status = "cancelled"
+	return nil
+}
+
+// ExtendCampusWar moves the end of a war that has not finished.
+func ExtendCampusWar(db *gorm.DB, war *CampusWar, endDate time.Time) error {
+	result := db.Model(&CampusWar{}).
+		Where("id = ? AND status IN ('upcoming', 'active') AND settled_at IS NULL", war.ID).
+		Update("end_date", endDate)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWarNotExtendable
+	}
+	war.EndDate = endDate
+	return nil
+}
+
 func CreateWarParticipant(db *gorm.DB, participant *WarParticipant) error {
 	return db.Create(participant).Error
 }
 
+// EnrollWarParticipants adds entities of one type to a war, skipping any
+// already enrolled, and returns how many were added.
+func EnrollWarParticipants(db *gorm.DB, war *CampusWar, entityType string, entityIDs []int) (int64, error) {
+	if len(entityIDs) == 0 {
+		return 0, nil
+	}
+	warID := int(war.ID)
+	participants := make([]WarParticipant, 0, len(entityIDs))
+	for _, id := range entityIDs {
+		participants = append(participants, WarParticipant{WarID: &warID, EntityType: entityType, EntityID: id})
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&participants)
+	return result.RowsAffected, result.Error
+}
+
+// MissingWarEntities returns the IDs that do not exist in the entity type's
+// table.
+func MissingWarEntities(db *gorm.DB, entityType string, entityIDs []int) ([]int, error) {
+	var found []int
+	var err error
+	switch entityType {
+	case "college":
+		err = db.Model(&College{}).Where("id IN ?", entityIDs).Pluck("id", &found).Error
+	case "state":
+		err = db.Model(&State{}).Where("id IN ?", entityIDs).Pluck("id", &found).Error
+	default:
+		return nil, fmt.Errorf("unknown war entity type %q", entityType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make(map[int]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+	missing := []int{}
+	for _, id := range entityIDs {
+		if !exists[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// WarEntityFilter selects entities to enrol in bulk. StateIDs limits colleges
+// to those states, or states to that list; MinCAs only applies to colleges.
+type WarEntityFilter struct {
+	StateIDs        []int `json:"state_ids"`
+	MinCAs          int   `json:"min_cas" validate:"gte=0"`
+	IncludeInactive bool  `json:"include_inactive"`
+}
+
+// FindWarEntities lists the IDs of the entities of a type that match filter.
+func FindWarEntities(db *gorm.DB, entityType string, filter WarEntityFilter) ([]int, error) {
+	var ids []int
+	switch entityType {
+	case "college":
+		query := db.Model(&College{}).Where("total_cas >= ?", filter.MinCAs)
+		if len(filter.StateIDs) > 0 {
+			query = query.Where("state_id IN ?", filter.StateIDs)
+		}
+		if !filter.IncludeInactive {
+			query = query.Where("is_active = ?", true)
+		}
+		err := query.Order("id ASC").Pluck("id", &ids).Error
+		return ids, err
+	case "state":
+		query := db.Model(&State{})
+		if len(filter.StateIDs) > 0 {
+			query = query.Where("id IN ?", filter.StateIDs)
+		}
+		err := query.Order("id ASC").Pluck("id", &ids).Error
+		return ids, err
+	default:
+		return nil, fmt.Errorf("unknown war entity type %q", entityType)
+	}
+}
+
 func GetWarParticipantByID(db *gorm.DB, id uint) (*WarParticipant, error) {
 	var participant WarParticipant
 	if err := db.First(&participant, id).Error; err != nil {
@@ -92,3 +338,207 @@ func GetWarLeaderboardSnapshotByID(db *gorm.DB, id uint) (*WarLeaderboardSnapsho
 	}
 	return &snapshot, nil
 }
+
+// warEntityColumns maps a participant entity type to the users column that
+// places a user in it.
+var warEntityColumns = map[string]string{
+	"college": "college_id",
+	"state":   "state_id",
+}
+
+// warMetricQueries total each metric per entity over the war window. %[1]s is
+// the users column of the entity type. XP is read from xp_transactions, which
+// AwardXP writes for every XP change, so all XP earned counts.
+var warMetricQueries = map[string]string{
+	"xp": `SELECT users.%[1]s AS entity_id, COALESCE(SUM(t.amount), 0) AS total
+		FROM xp_transactions t JOIN users ON users.id = t.user_id
+		WHERE t.amount > 0 AND t.created_at >= ? AND t.created_at < ? AND users.%[1]s IN ?
+		GROUP BY users.%[1]s`,
+	"submissions": `SELECT users.%[1]s AS entity_id, COUNT(*) AS total
+		FROM submissions s JOIN users ON users.id = s.user_id
+		WHERE s.status = 'approved' AND s.reviewed_at >= ? AND s.reviewed_at < ? AND users.%[1]s IN ?
+		GROUP BY users.%[1]s`,
+	"referrals": `SELECT users.%[1]s AS entity_id, COUNT(*) AS total
+		FROM referrals r JOIN users ON users.id = r.referrer_id
+		WHERE r.referred_user_id IS NOT NULL AND r.created_at >= ? AND r.created_at < ? AND users.%[1]s IN ?
+		GROUP BY users.%[1]s`,
+}
+
+// warMetricTotals totals one metric for the given entities of one type.
+func warMetricTotals(db *gorm.DB, metric, entityType string, entityIDs []int, start, end time.Time) (map[int]int, error) {
+	var rows []struct {
+		EntityID int
+		Total    int
+	}
+	query := fmt.Sprintf(warMetricQueries[metric], warEntityColumns[entityType])
+	if err := db.Raw(query, start, end, entityIDs).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	totals := make(map[int]int, len(rows))
+	for _, row := range rows {
+		totals[row.EntityID] = row.Total
+	}
+	return totals, nil
+}
+
+// ScoreCampusWar recomputes every participant's totals from the activity of
+// its members between the war's start and now (or the end, once passed),
+// then scores and ranks them. Ties go to the participant enrolled first. The
+// participants are returned best first.
+func ScoreCampusWar(db *gorm.DB, war *CampusWar, now time.Time) ([]WarParticipant, error) {
+	metrics, err := war.ParseMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("invalid metrics for war %d: %w", war.ID, err)
+	}
+
+	var participants []WarParticipant
+	if err := db.Where("war_id = ?", war.ID).Find(&participants).Error; err != nil {
+		return nil, err
+	}
+	if len(participants) == 0 {
+		return participants, nil
+	}
+
+	ids := make(map[string][]int)
+	for _, p := range participants {
+		ids[p.EntityType] = append(ids[p.EntityType], p.EntityID)
+	}
+
+	end := war.scoredUntil(now)
+	totals := make(map[string]map[string]map[int]int)
+	for entityType, entityIDs := range ids {
+		totals[entityType] = make(map[string]map[int]int)
+		for metric := range warMetricQueries {
+			byEntity, err := warMetricTotals(db, metric, entityType, entityIDs, war.StartDate, end)
+			if err != nil {
+				return nil, err
+			}
+			totals[entityType][metric] = byEntity
+		}
+	}
+
+	for i := range participants {
+		p := &participants[i]
+		p.TotalXP = totals[p.EntityType]["xp"][p.EntityID]
+		p.TotalSubmissions = totals[p.EntityType]["submissions"][p.EntityID]
+		p.TotalReferrals = totals[p.EntityType]["referrals"][p.EntityID]
+		p.Score = metrics.Score(p)
+	}
+
+	sort.SliceStable(participants, func(i, j int) bool {
+		if participants[i].Score != participants[j].Score {
+			return participants[i].Score > participants[j].Score
+		}
+		return participants[i].ID < participants[j].ID
+	})
+
+	for i := range participants {
+		p := &participants[i]
+		rank := i + 1
+		p.Rank = &rank
+		if err := db.Model(&WarParticipant{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+			"total_xp":          p.TotalXP,
+			"total_submissions": p.TotalSubmissions,
+			"total_referrals":   p.TotalReferrals,
+			"score":             p.Score,
+			"rank":              rank,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return participants, nil
+}
+
+// WarStanding is one entry of a war leaderboard snapshot.
+type WarStanding struct {
+	Rank             int    `json:"rank"`
+	EntityType       string `json:"entity_type"`
+	EntityID         int    `json:"entity_id"`
+	Score            int    `json:"score"`
+	TotalXP          int    `json:"total_xp"`
+	TotalSubmissions int    `json:"total_submissions"`
+	TotalReferrals   int    `json:"total_referrals"`
+}
+
+// SnapshotCampusWar records the ranked participants as the war's standings at
+// the given time, replacing any snapshot already taken at that time.
+func SnapshotCampusWar(db *gorm.DB, war *CampusWar, participants []WarParticipant, at time.Time) error {
+	standings := make([]WarStanding, 0, len(participants))
+	for _, p := range participants {
+		standing := WarStanding{
+			EntityType:       p.EntityType,
+			EntityID:         p.EntityID,
+			Score:            p.Score,
+			TotalXP:          p.TotalXP,
+			TotalSubmissions: p.TotalSubmissions,
+			TotalReferrals:   p.TotalReferrals,
+		}
+		if p.Rank != nil {
+			standing.Rank = *p.Rank
+		}
+		standings = append(standings, standing)
+	}
+	data, err := json.Marshal(standings)
+	if err != nil {
+		return err
+	}
+
+	warID := int(war.ID)
+	snapshot := &WarLeaderboardSnapshot{
+		WarID:           &warID,
+		SnapshotDate:    at,
+		LeaderboardData: string(data),
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "war_id"}, {Name: "snapshot_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"leaderboard_data"}),
+	}).Create(snapshot).Error
+}
+
+// HasWarSnapshot reports whether the war has a snapshot taken at or after the
+// given time.
+func HasWarSnapshot(db *gorm.DB, warID uint, since time.Time) (bool, error) {
+	var count int64
+	err := db.Model(&WarLeaderboardSnapshot{}).Where("war_id = ? AND snapshot_date >= ?", warID, since).Count(&count).Error
+	return count > 0, err
+}
+
+var ErrWarAlreadySettled = errors.New("campus war already settled")
+
+// SettleCampusWar scores a war that has ended for the last time and records
+// its final standings. The ranked participants are returned, best first.
+func SettleCampusWar(db *gorm.DB, war *CampusWar) ([]WarParticipant, error) {
+	now := time.Now()
+	result := db.Model(&CampusWar{}).
+		Where("id = ? AND settled_at IS NULL", war.ID).
+		Update("settled_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrWarAlreadySettled
+	}
+	war.SettledAt = &now
+
+	participants, err := ScoreCampusWar(db, war, war.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if err := SnapshotCampusWar(db, war, participants, war.EndDate); err != nil {
+		return nil, err
+	}
+	return participants, nil
+}
+
+// GetWarContributors lists the members of a participating college or state
+// who earned XP during the war.
+func GetWarContributors(db *gorm.DB, war *CampusWar, participant *WarParticipant) ([]uint, error) {
+	var userIDs []uint
+	err := db.Model(&XPTransaction{}).
+		Joins("JOIN users ON users.id = xp_transactions.user_id").
+		Where(fmt.Sprintf("users.%s = ?", warEntityColumns[participant.EntityType]), participant.EntityID).
+		Where("xp_transactions.amount > 0 AND xp_transactions.created_at >= ? AND xp_transactions.created_at < ?", war.StartDate, war.EndDate).
+		Distinct().
+		Pluck("xp_transactions.user_id", &userIDs).Error
+	return userIDs, err
+}
@@ -1,9 +1,15 @@
 package store
 
 import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Certificate struct {
@@ -18,10 +24,15 @@ type Certificate struct {
 	CertificateURL    string     `gorm:"type:text;not null"`
 	TemplateID        *int       `gorm:"type:integer"`
 	Metadata          *string    `gorm:"type:jsonb;default:'{}'"`
+	VerificationCode  string     `gorm:"size:20;unique;not null"`
+	SourceType        *string    `gorm:"size:50"`
+	SourceID          *int       `gorm:"type:integer"`
+	RevokedAt         *time.Time `gorm:"type:timestamp"`
 	CreatedAt         time.Time  `gorm:"autoCreateTime"`
 
 	// Relations
-	User *User `gorm:"foreignKey:UserID"`
+	User     *User                `gorm:"foreignKey:UserID"`
+	Template *CertificateTemplate `gorm:"foreignKey:TemplateID"`
 }
 
 func (Certificate) TableName() string {
@@ -39,3 +50,213 @@ func GetCertificateByID(db *gorm.DB, id uint) (*Certificate, error) {
 	}
 	return &certificate, nil
 }
+
+// CertificateTemplate holds the wording and styling used to render a
+// certificate. BodyTemplate may use {{name}}, {{campaign}}, {{badge}},
+// {{rank}}, {{date}} and {{code}}.
+type CertificateTemplate struct {
+	ID              uint      `gorm:"primaryKey"`
+	Name            string    `gorm:"size:100;not null"`
+	CertificateType string    `gorm:"size:50;not null;check:certificate_type IN ('achievement', 'completion', 'winner', 'participation')"`
+	Heading         string    `gorm:"size:200;not null"`
+	BodyTemplate    string    `gorm:"type:text;not null"`
+	AccentColor     string    `gorm:"size:7;default:'#1F6F4A'"`
+	SignatoryName   *string   `gorm:"size:100"`
+	SignatoryTitle  *string   `gorm:"size:100"`
+	IsActive        bool      `gorm:"default:true"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+func (CertificateTemplate) TableName() string {
+	return "certificate_templates"
+}
+
+// CertificateDetails is stored in Certificate.Metadata at issue time so the
+// rendered document does not change when the user or campaign is edited later.
+type CertificateDetails struct {
+	RecipientName string `json:"recipient_name"`
+	CampaignTitle string `json:"campaign_title,omitempty"`
+	BadgeName     string `json:"badge_name,omitempty"`
+	Rank          int    `json:"rank,omitempty"`
+}
+
+// Details decodes the certificate metadata.
+func (c *Certificate) Details() CertificateDetails {
+	var details CertificateDetails
+	if c.Metadata != nil {
+		json.Unmarshal([]byte(*c.Metadata), &details)
+	}
+	return details
+}
+
+// winnerRanks is how many top finishers of a campaign get a winner certificate.
+const winnerRanks = 3
+
+// verificationAlphabet omits characters that are easily misread (0/O, 1/I/L).
+const verificationAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// NewVerificationCode returns a random code such as GG-7KQ2-M9XD-4TPA.
+func NewVerificationCode() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := make([]byte, len(buf))
+	for i, b := range buf {
+		code[i] = verificationAlphabet[int(b)%len(verificationAlphabet)]
+	}
+	return fmt.Sprintf("GG-%s-%s-%s", code[0:4], code[4:8], code[8:12]), nil
+}
+
+func GetCertificateByVerificationCode(db *gorm.DB, code string) (*Certificate, error) {
+	var certificate Certificate
+	if err := db.Where("verification_code = ?", strings.ToUpper(code)).First(&certificate).Error; err != nil {
+		return nil, err
+	}
+	return &certificate, nil
+}
+
+func GetCertificateTemplateByID(db *gorm.DB, id uint) (*CertificateTemplate, error) {
+	var template CertificateTemplate
+	if err := db.First(&template, id).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetDefaultCertificateTemplate returns the newest active template of a type.
+func GetDefaultCertificateTemplate(db *gorm.DB, certificateType string) (*CertificateTemplate, error) {
+	var template CertificateTemplate
+	if err := db.Where("certificate_type = ? AND is_active = ?", certificateType, true).
+		Order("id DESC").
+		First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// IssueCertificate assigns a verification code and stores the certificate. A
+// user gets at most one certificate per source, so issuing again for the same
+// source is a no-op that reports false.
+func IssueCertificate(db *gorm.DB, certificate *Certificate) (bool, error) {
+	code, err := NewVerificationCode()
+	if err != nil {
+		return false, err
+	}
+	certificate.VerificationCode = code
+	certificate.CertificateURL = "/api/v1/certificates/verify/" + code
+	if certificate.IssueDate.IsZero() {
+		certificate.IssueDate = time.Now()
+	}
+
+	result := db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "user_id"}, {Name: "source_type"}, {Name: "source_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "source_type IS NOT NULL"}}},
+		DoNothing:   true,
+	}).Create(certificate)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func newIssuedCertificate(userID int, certificateType, title string, template *CertificateTemplate, sourceType string, sourceID int, details CertificateDetails) (*Certificate, error) {
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	templateID := int(template.ID)
+	metadataJSON := string(metadata)
+	return &Certificate{
+		UserID:           &userID,
+		CertificateType:  &certificateType,
+		Title:            title,
+		IssuingAuthority: "Grove Growth",
+		TemplateID:       &templateID,
+		Metadata:         &metadataJSON,
+		SourceType:       &sourceType,
+		SourceID:         &sourceID,
+	}, nil
+}
+
+// IssueCampaignCertificates issues a certificate to everyone on a campaign's
+// frozen leaderboard: winner certificates for the top ranks and completion
+// certificates for the rest. It returns the certificates newly issued.
+func IssueCampaignCertificates(db *gorm.DB, campaign *Campaign, leaderboard *Leaderboard) ([]Certificate, error) {
+	completion, err := GetDefaultCertificateTemplate(db, "completion")
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	winner, err := GetDefaultCertificateTemplate(db, "winner")
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	entries, err := GetLeaderboardEntries(db, leaderboard.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var issued []Certificate
+	for _, entry := range entries {
+		if entry.UserID == nil || entry.User == nil {
+			continue
+		}
+
+		details := CertificateDetails{
+			RecipientName: entry.User.FirstName + " " + entry.User.LastName,
+			CampaignTitle: campaign.Title,
+		}
+		certificateType, template, title := "completion", completion, campaign.Title+" - Completion"
+		if winner != nil && entry.Rank != nil && *entry.Rank <= winnerRanks {
+			certificateType, template, title = "winner", winner, campaign.Title+" - Winner"
+			details.Rank = *entry.Rank
+		}
+
+		certificate, err := newIssuedCertificate(*entry.UserID, certificateType, title, template, "campaign", int(campaign.ID), details)
+		if err != nil {
+			return nil, err
+		}
+		created, err := IssueCertificate(db, certificate)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			issued = append(issued, *certificate)
+		}
+	}
+	return issued, nil
+}
+
+// IssueBadgeCertificate issues the certificate attached to a badge, if any. It
+// returns nil when the badge has no certificate or the user already has it.
+func IssueBadgeCertificate(db *gorm.DB, user *User, badge *Badge) (*Certificate, error) {
+	if badge.CertificateTemplateID == nil {
+		return nil, nil
+	}
+	template, err := GetCertificateTemplateByID(db, uint(*badge.CertificateTemplateID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	details := CertificateDetails{
+		RecipientName: user.FirstName + " " + user.LastName,
+		BadgeName:     badge.Name,
+	}
+	certificate, err := newIssuedCertificate(int(user.ID), template.CertificateType, badge.Name, template, "badge", int(badge.ID), details)
+	if err != nil {
+		return nil, err
+	}
+	created, err := IssueCertificate(db, certificate)
+	if err != nil || !created {
+		return nil, err
+	}
+	return certificate, nil
+}
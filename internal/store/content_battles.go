@@ -1,6 +1,8 @@
 package store
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -20,6 +22,7 @@ type ContentBattle struct {
 	Status           string     `gorm:"size:20;default:'upcoming';check:status IN ('upcoming', 'submissions', 'voting', 'completed')"`
 	CreatedBy        *int       `gorm:"index"`
 	CreatedAt        time.Time  `gorm:"autoCreateTime"`
+	SettledAt        *time.Time
 
 	// Relations
 	Creator *User `gorm:"foreignKey:CreatedBy"`
@@ -29,6 +32,33 @@ func (ContentBattle) TableName() string {
 	return "content_battles"
 }
 
+// Reward is a bundle of XP, coins and an optional badge, paid for a place in
+// a battle or war or for finishing a quest.
+type Reward struct {
+	XP      int  `json:"xp"`
+	Coins   int  `json:"coins"`
+	BadgeID *int `json:"badge_id,omitempty"`
+}
+
+// BattleRewards is the shape of ContentBattle.Rewards: places[0] is paid to
+// the winner, places[1] to the runner-up and so on, e.g.
+//
+//	{"places": [{"xp": 500, "coins": 100, "badge_id": 7}, {"xp": 250}]}
+type BattleRewards struct {
+	Places []Reward `json:"places"`
+}
+
+// ParseRewards decodes the battle's rewards. An unset or empty value pays
+// nothing.
+func (b *ContentBattle) ParseRewards() (BattleRewards, error) {
+	var rewards BattleRewards
+	if b.Rewards == nil || *b.Rewards == "" {
+		return rewards, nil
+	}
+	err := json.Unmarshal([]byte(*b.Rewards), &rewards)
+	return rewards, err
+}
+
 type BattleSubmission struct {
 	ID           uint      `gorm:"primaryKey"`
 	BattleID     *int      `gorm:"index;constraint:OnDelete:CASCADE"`
@@ -103,3 +133,49 @@ func GetBattleVoteByID(db *gorm.DB, id uint) (*BattleVote, error) {
 	}
 	return &vote, nil
 }
+
+var ErrBattleAlreadySettled = errors.New("content battle already settled")
+
+// SettleContentBattle tallies the votes of a battle whose voting has closed
+// and ranks its submissions. Vote counts are recounted from battle_votes, and
+// ties go to the earlier submission. The top submission is marked the winner
+// if it received any votes. The ranked submissions are returned, best first.
+func SettleContentBattle(db *gorm.DB, battle *ContentBattle) ([]BattleSubmission, error) {
+	now := time.Now()
+	result := db.Model(&ContentBattle{}).
+		Where("id = ? AND settled_at IS NULL", battle.ID).
+		Update("settled_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrBattleAlreadySettled
+	}
+	battle.SettledAt = &now
+
+	if err := db.Exec(`UPDATE battle_submissions s
+		SET vote_count = (SELECT COUNT(*) FROM battle_votes v WHERE v.submission_id = s.id)
+		WHERE s.battle_id = ?`, battle.ID).Error; err != nil {
+		return nil, err
+	}
+
+	var submissions []BattleSubmission
+	if err := db.Where("battle_id = ?", battle.ID).
+		Order("vote_count DESC, submitted_at ASC, id ASC").
+		Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range submissions {
+		rank := i + 1
+		submissions[i].Rank = &rank
+		submissions[i].IsWinner = rank == 1 && submissions[i].VoteCount > 0
+		if err := db.Model(&BattleSubmission{}).Where("id = ?", submissions[i].ID).Updates(map[string]interface{}{
+			"rank":      rank,
+			"is_winner": submissions[i].IsWinner,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return submissions, nil
+}
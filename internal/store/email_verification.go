@@ -0,0 +1,77 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+
+// EmailVerificationToken stores only the SHA-256 hash of the token sent to the user.
+type EmailVerificationToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    int        `gorm:"not null;index"`
+	TokenHash string     `gorm:"size:64;unique;not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time `gorm:"type:timestamp"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (EmailVerificationToken) TableName() string {
+	return "email_verification_tokens"
+}
+
+// CreateEmailVerificationToken stores a new token for the user and invalidates
+// any token previously issued to them.
+func CreateEmailVerificationToken(db *gorm.DB, userID uint, token string, ttl time.Duration) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&EmailVerificationToken{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&EmailVerificationToken{
+			UserID:    int(userID),
+			TokenHash: HashToken(token),
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+}
+
+// VerifyUserEmail consumes a verification token and marks its user's email as
+// verified. It returns the verified user's ID.
+func VerifyUserEmail(db *gorm.DB, token string) (uint, error) {
+	var userID uint
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var verification EmailVerificationToken
+		if err := tx.Where("token_hash = ?", HashToken(token)).First(&verification).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidVerificationToken
+			}
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&EmailVerificationToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", verification.ID, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidVerificationToken
+		}
+
+		userID = uint(verification.UserID)
+		return tx.Model(&User{}).
+			Where("id = ? AND email_verified_at IS NULL", userID).
+			Update("email_verified_at", now).Error
+	})
+	return userID, err
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EventEdge records that a time-boxed event crossed one of its lifecycle
+// edges, such as a content battle opening voting.
+type EventEdge struct {
+	ID        uint      `gorm:"primaryKey"`
+	EventKind string    `gorm:"size:50;not null;uniqueIndex:idx_event_edge"`
+	EventID   uint      `gorm:"not null;uniqueIndex:idx_event_edge"`
+	Edge      string    `gorm:"size:50;not null;uniqueIndex:idx_event_edge"`
+	FiredAt   time.Time `gorm:"not null"`
+}
+
+func (EventEdge) TableName() string {
+	return "event_edges"
+}
+
+// RecordEventEdge marks an edge as fired. It returns false when the edge had
+// already been recorded, so only one caller acts on each edge.
+func RecordEventEdge(db *gorm.DB, kind string, eventID uint, edge string, firedAt time.Time) (bool, error) {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&EventEdge{
+		EventKind: kind,
+		EventID:   eventID,
+		Edge:      edge,
+		FiredAt:   firedAt,
+	})
+	return result.RowsAffected == 1, result.Error
+}
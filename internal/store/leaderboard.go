@@ -73,3 +73,53 @@ func GetLeaderboardEntryByID(db *gorm.DB, id uint) (*LeaderboardEntry, error) {
 	}
 	return &entry, nil
 }
+
+// GetCampaignLeaderboard returns the frozen leaderboard for a campaign.
+func GetCampaignLeaderboard(db *gorm.DB, campaignID uint) (*Leaderboard, error) {
+	var leaderboard Leaderboard
+	if err := db.Where("leaderboard_type = 'campaign' AND entity_id = ?", campaignID).
+		Order("created_at DESC").
+		First(&leaderboard).Error; err != nil {
+		return nil, err
+	}
+	return &leaderboard, nil
+}
+
+func GetLeaderboardEntries(db *gorm.DB, leaderboardID uint, limit int) ([]LeaderboardEntry, error) {
+	var entries []LeaderboardEntry
+	// Only the latest snapshot
+	query := db.Where("leaderboard_id = ?", leaderboardID).
+		Where("snapshot_date = (?)", db.Model(&LeaderboardEntry{}).Select("MAX(snapshot_date)").Where("leaderboard_id = ?", leaderboardID)).
+		Preload("User.ProfileSkin").Preload("College").Order("rank ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FreezeCampaignLeaderboard takes the final snapshot of a campaign's
+// approved-submission standings and closes its leaderboard, so the standings
+// no longer change once the campaign is completed.
+func FreezeCampaignLeaderboard(db *gorm.DB, campaign *Campaign) (*Leaderboard, error) {
+	leaderboard := campaignLeaderboard(campaign)
+	if err := EnsureLeaderboard(db, &leaderboard); err != nil {
+		return nil, err
+	}
+
+	standings, err := GetLeaderboardStandings(db, &leaderboard)
+	if err != nil {
+		return nil, err
+	}
+	if err := SnapshotLeaderboard(db, &leaderboard, standings, time.Now()); err != nil {
+		return nil, err
+	}
+
+	leaderboard.IsActive = false
+	if err := db.Model(&leaderboard).Update("is_active", false).Error; err != nil {
+		return nil, err
+	}
+	return &leaderboard, nil
+}
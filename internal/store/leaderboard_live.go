@@ -0,0 +1,78 @@
+package store
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Live leaderboard kinds that can be streamed to clients.
+const (
+	BoardGlobal   = "global"
+	BoardCollege  = "college"
+	BoardState    = "state"
+	BoardCampaign = "campaign"
+	BoardWar      = "war"
+)
+
+// LeaderboardRow is one ranked entry of a live leaderboard. Entities are users
+// except on war boards, where they are colleges or states.
+type LeaderboardRow struct {
+	EntityType string `json:"entity_type"`
+	EntityID   uint   `json:"entity_id"`
+	Name       string `json:"name"`
+	Score      int    `json:"score"`
+	Rank       int    `json:"rank"`
+}
+
+// Key identifies the row's entity within a board.
+func (r LeaderboardRow) Key() string {
+	return fmt.Sprintf("%s:%d", r.EntityType, r.EntityID)
+}
+
+// GetLiveLeaderboard computes the current top entries of a leaderboard. id is
+// ignored for the global board.
+func GetLiveLeaderboard(db *gorm.DB, board string, id uint, limit int) ([]LeaderboardRow, error) {
+	var rows []LeaderboardRow
+	var query *gorm.DB
+
+	switch board {
+	case BoardGlobal, BoardCollege, BoardState:
+		query = db.Model(&User{}).
+			Select("'user' as entity_type, id as entity_id, TRIM(first_name || ' ' || last_name) as name, xp as score").
+			Where("is_active = ?", true)
+		if board == BoardCollege {
+			query = query.Where("college_id = ?", id)
+		} else if board == BoardState {
+			query = query.Where("state_id = ?", id)
+		}
+		query = query.Order("xp DESC, id ASC")
+
+	case BoardCampaign:
+		query = db.Model(&Submission{}).
+			Select("'user' as entity_type, users.id as entity_id, TRIM(users.first_name || ' ' || users.last_name) as name, COALESCE(SUM(submissions.xp_awarded), 0) as score").
+			Joins("JOIN users ON users.id = submissions.user_id").
+			Where("submissions.campaign_id = ? AND submissions.status = 'approved'", id).
+			Group("users.id, users.first_name, users.last_name").
+			Order("score DESC, users.id ASC")
+
+	case BoardWar:
+		query = db.Model(&WarParticipant{}).
+			Select("war_participants.entity_type, war_participants.entity_id, COALESCE(colleges.name, states.name, '') as name, war_participants.score").
+			Joins("LEFT JOIN colleges ON war_participants.entity_type = 'college' AND colleges.id = war_participants.entity_id").
+			Joins("LEFT JOIN states ON war_participants.entity_type = 'state' AND states.id = war_participants.entity_id").
+			Where("war_participants.war_id = ?", id).
+			Order("war_participants.score DESC, war_participants.id ASC")
+
+	default:
+		return nil, fmt.Errorf("unknown leaderboard %q", board)
+	}
+
+	if err := query.Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Rank = i + 1
+	}
+	return rows, nil
+}
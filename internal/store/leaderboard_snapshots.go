@@ -0,0 +1,354 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Leaderboard kinds that are only snapshotted, never streamed live.
+const (
+	BoardWeekly  = "weekly"
+	BoardMonthly = "monthly"
+)
+
+// Rank trends of a snapshot entry against the previous snapshot.
+const (
+	TrendUp     = "up"
+	TrendDown   = "down"
+	TrendStable = "stable"
+	TrendNew    = "new"
+)
+
+// SnapshotDateFormat is the layout of snapshot dates and the as_of parameter.
+const SnapshotDateFormat = "2006-01-02"
+
+// LeaderboardStanding is a user's score on a leaderboard when it is
+// snapshotted.
+type LeaderboardStanding struct {
+	UserID      int
+	CollegeID   *int
+	StateID     *int
+	XP          int
+	Submissions int
+}
+
+// LeaderboardPeriod returns the period a weekly or monthly leaderboard covers
+// at t: Monday to Sunday, or the calendar month. Other kinds have no period.
+// Like dates read back from the database, the bounds are midnight UTC of
+// t's calendar days.
+func LeaderboardPeriod(board string, t time.Time) (*time.Time, *time.Time) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	var start, end time.Time
+	switch board {
+	case BoardWeekly:
+		start = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		end = start.AddDate(0, 0, 6)
+	case BoardMonthly:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 1, -1)
+	default:
+		return nil, nil
+	}
+	return &start, &end
+}
+
+// LeaderboardName names the series a leaderboard belongs to. Every period of
+// a weekly or monthly leaderboard shares the name, so trends carry over from
+// one period to the next.
+func LeaderboardName(board string, entityID uint) string {
+	switch board {
+	case BoardCollege, BoardState:
+		return fmt.Sprintf("%s:%d", board, entityID)
+	}
+	return board
+}
+
+// FindLeaderboard returns the leaderboard of a series covering at. For series
+// without periods that is the only one.
+func FindLeaderboard(db *gorm.DB, board, name string, at time.Time) (*Leaderboard, error) {
+	query := db.Where("leaderboard_type = ? AND name = ?", board, name)
+	if start, _ := LeaderboardPeriod(board, at); start != nil {
+		query = query.Where("period_start = ?", start.Format(SnapshotDateFormat))
+	}
+
+	var leaderboard Leaderboard
+	if err := query.Order("created_at DESC").First(&leaderboard).Error; err != nil {
+		return nil, err
+	}
+	return &leaderboard, nil
+}
+
+// EnsureLeaderboard loads the leaderboard matching the type, name and, for
+// weekly and monthly leaderboards, period of leaderboard into it, creating it
+// if there is none yet.
+func EnsureLeaderboard(db *gorm.DB, leaderboard *Leaderboard) error {
+	at := time.Now()
+	if leaderboard.PeriodStart != nil {
+		at = *leaderboard.PeriodStart
+	}
+	existing, err := FindLeaderboard(db, leaderboard.LeaderboardType, leaderboard.Name, at)
+	if err == nil {
+		*leaderboard = *existing
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return db.Create(leaderboard).Error
+}
+
+// EnsureSnapshotLeaderboards returns the leaderboards snapshotted at now,
+// creating any that are missing: global, this week's and this month's, one
+// per college and state with active members and one per active campaign.
+func EnsureSnapshotLeaderboards(db *gorm.DB, now time.Time) ([]Leaderboard, error) {
+	var leaderboards []Leaderboard
+	ensure := func(leaderboard Leaderboard) error {
+		if err := EnsureLeaderboard(db, &leaderboard); err != nil {
+			return err
+		}
+		leaderboards = append(leaderboards, leaderboard)
+		return nil
+	}
+
+	for _, board := range []string{BoardGlobal, BoardWeekly, BoardMonthly} {
+		start, end := LeaderboardPeriod(board, now)
+		if err := ensure(Leaderboard{
+			Name:            LeaderboardName(board, 0),
+			LeaderboardType: board,
+			PeriodStart:     start,
+			PeriodEnd:       end,
+			IsActive:        true,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, board := range []string{BoardCollege, BoardState} {
+		column := board + "_id"
+		var ids []int
+		if err := db.Model(&User{}).
+			Where("is_active = ? AND "+column+" IS NOT NULL", true).
+			Distinct(column).
+			Pluck(column, &ids).Error; err != nil {
+			return nil, err
+		}
+		entityType := board
+		for _, id := range ids {
+			entityID := id
+			if err := ensure(Leaderboard{
+				Name:            LeaderboardName(board, uint(id)),
+				LeaderboardType: board,
+				EntityType:      &entityType,
+				EntityID:        &entityID,
+				IsActive:        true,
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var campaigns []Campaign
+	if err := db.Where("status = 'active' AND start_date <= ?", now).Find(&campaigns).Error; err != nil {
+		return nil, err
+	}
+	for i := range campaigns {
+		if err := ensure(campaignLeaderboard(&campaigns[i])); err != nil {
+			return nil, err
+		}
+	}
+
+	return leaderboards, nil
+}
+
+// campaignLeaderboard describes the leaderboard of a campaign.
+func campaignLeaderboard(campaign *Campaign) Leaderboard {
+	campaignID := int(campaign.ID)
+	start := campaign.StartDate
+	end := campaign.EndDate
+	return Leaderboard{
+		Name:            BoardCampaign + ":" + campaign.UUID,
+		LeaderboardType: BoardCampaign,
+		EntityID:        &campaignID,
+		PeriodStart:     &start,
+		PeriodEnd:       &end,
+		IsActive:        true,
+	}
+}
+
+// GetEndedLeaderboards lists the weekly and monthly leaderboards still
+// active after their period ended before now.
+func GetEndedLeaderboards(db *gorm.DB, now time.Time) ([]Leaderboard, error) {
+	var leaderboards []Leaderboard
+	err := db.Where("leaderboard_type IN ? AND is_active = ? AND period_end < ?",
+		[]string{BoardWeekly, BoardMonthly}, true, now.Format(SnapshotDateFormat)).
+		Order("period_end ASC").
+		Find(&leaderboards).Error
+	return leaderboards, err
+}
+
+// GetLeaderboardStandings ranks the users on a leaderboard. Global, college
+// and state leaderboards rank total XP; weekly and monthly ones rank XP
+// earned in the period, ignoring XP spent on rewards; campaign ones rank XP
+// from approved submissions to the campaign.
+func GetLeaderboardStandings(db *gorm.DB, leaderboard *Leaderboard) ([]LeaderboardStanding, error) {
+	var query *gorm.DB
+
+	switch leaderboard.LeaderboardType {
+	case BoardGlobal, BoardCollege, BoardState:
+		query = db.Model(&User{}).
+			Select("id as user_id, college_id, state_id, xp, approved_submissions as submissions").
+			Where("is_active = ?", true)
+		if leaderboard.LeaderboardType != BoardGlobal {
+			if leaderboard.EntityID == nil {
+				return nil, fmt.Errorf("leaderboard %d has no %s", leaderboard.ID, leaderboard.LeaderboardType)
+			}
+			query = query.Where(leaderboard.LeaderboardType+"_id = ?", *leaderboard.EntityID)
+		}
+		query = query.Order("xp DESC, id ASC")
+
+	case BoardWeekly, BoardMonthly:
+		if leaderboard.PeriodStart == nil || leaderboard.PeriodEnd == nil {
+			return nil, fmt.Errorf("leaderboard %d has no period", leaderboard.ID)
+		}
+		start := leaderboard.PeriodStart.Format(SnapshotDateFormat)
+		end := leaderboard.PeriodEnd.AddDate(0, 0, 1).Format(SnapshotDateFormat)
+		query = db.Model(&XPTransaction{}).
+			Select(`users.id as user_id, users.college_id, users.state_id, SUM(xp_transactions.amount) as xp,
+				(SELECT COUNT(*) FROM submissions
+				 WHERE submissions.user_id = users.id AND submissions.status = 'approved'
+				 AND submissions.submitted_at >= ? AND submissions.submitted_at < ?) as submissions`, start, end).
+			Joins("JOIN users ON users.id = xp_transactions.user_id").
+			Where("users.is_active = ? AND xp_transactions.transaction_type <> 'redemption'", true).
+			Where("xp_transactions.created_at >= ? AND xp_transactions.created_at < ?", start, end).
+			Group("users.id, users.college_id, users.state_id").
+			Having("SUM(xp_transactions.amount) > 0").
+			Order("xp DESC, users.id ASC")
+
+	case BoardCampaign:
+		if leaderboard.EntityID == nil {
+			return nil, fmt.Errorf("leaderboard %d has no campaign", leaderboard.ID)
+		}
+		query = db.Model(&Submission{}).
+			Select("submissions.user_id, users.college_id, users.state_id, COALESCE(SUM(submissions.xp_awarded), 0) as xp, COUNT(submissions.id) as submissions").
+			Joins("JOIN users ON users.id = submissions.user_id").
+			Where("submissions.campaign_id = ? AND submissions.status = 'approved'", *leaderboard.EntityID).
+			Group("submissions.user_id, users.college_id, users.state_id").
+			Order("xp DESC, submissions DESC, submissions.user_id ASC")
+
+	default:
+		return nil, fmt.Errorf("leaderboard type %q is not snapshotted", leaderboard.LeaderboardType)
+	}
+
+	var standings []LeaderboardStanding
+	if err := query.Scan(&standings).Error; err != nil {
+		return nil, err
+	}
+	return standings, nil
+}
+
+// SnapshotLeaderboard records standings, in rank order, as the leaderboard's
+// snapshot for day, replacing one taken earlier that day. Previous ranks come
+// from the latest earlier snapshot in the same series, so a new week or month
+// is compared against the end of the last one.
+func SnapshotLeaderboard(db *gorm.DB, leaderboard *Leaderboard, standings []LeaderboardStanding, day time.Time) error {
+	date := day.Format(SnapshotDateFormat)
+
+	previousRanks := make(map[int]int)
+	var latest LeaderboardEntry
+	err := db.Model(&LeaderboardEntry{}).
+		Joins("JOIN leaderboards ON leaderboards.id = leaderboard_entries.leaderboard_id").
+		Where("leaderboards.leaderboard_type = ? AND leaderboards.name = ?", leaderboard.LeaderboardType, leaderboard.Name).
+		Where("leaderboard_entries.snapshot_date < ?", date).
+		Order("leaderboard_entries.snapshot_date DESC").
+		Take(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil {
+		var previous []LeaderboardEntry
+		if err := db.Where("leaderboard_id = ? AND snapshot_date = ? AND user_id IS NOT NULL",
+			*latest.LeaderboardID, latest.SnapshotDate.Format(SnapshotDateFormat)).
+			Find(&previous).Error; err != nil {
+			return err
+		}
+		for _, entry := range previous {
+			if entry.Rank != nil {
+				previousRanks[*entry.UserID] = *entry.Rank
+			}
+		}
+	}
+
+	if err := db.Where("leaderboard_id = ? AND snapshot_date = ?", leaderboard.ID, date).
+		Delete(&LeaderboardEntry{}).Error; err != nil {
+		return err
+	}
+	if len(standings) == 0 {
+		return nil
+	}
+
+	leaderboardID := int(leaderboard.ID)
+	snapshotDate, _ := time.Parse(SnapshotDateFormat, date)
+	entries := make([]LeaderboardEntry, len(standings))
+	for i, standing := range standings {
+		userID := standing.UserID
+		rank := i + 1
+		entry := LeaderboardEntry{
+			LeaderboardID:    &leaderboardID,
+			UserID:           &userID,
+			CollegeID:        standing.CollegeID,
+			StateID:          standing.StateID,
+			XP:               standing.XP,
+			SubmissionsCount: standing.Submissions,
+			Rank:             &rank,
+			SnapshotDate:     snapshotDate,
+		}
+		trend := TrendNew
+		if previousRank, ok := previousRanks[userID]; ok {
+			entry.PreviousRank = &previousRank
+			switch {
+			case rank < previousRank:
+				trend = TrendUp
+			case rank > previousRank:
+				trend = TrendDown
+			default:
+				trend = TrendStable
+			}
+		}
+		entry.Trend = &trend
+		entries[i] = entry
+	}
+	return db.CreateInBatches(entries, 500).Error
+}
+
+// GetLeaderboardSnapshot returns the top entries of the leaderboard's latest
+// snapshot taken on or before asOf, with the snapshot's date. It returns no
+// entries and a nil date if there is none.
+func GetLeaderboardSnapshot(db *gorm.DB, leaderboardID uint, asOf time.Time, limit int) ([]LeaderboardEntry, *time.Time, error) {
+	var latest LeaderboardEntry
+	err := db.Where("leaderboard_id = ? AND snapshot_date <= ?", leaderboardID, asOf.Format(SnapshotDateFormat)).
+		Order("snapshot_date DESC").
+		Take(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []LeaderboardEntry
+	query := db.Where("leaderboard_id = ? AND snapshot_date = ?", leaderboardID, latest.SnapshotDate.Format(SnapshotDateFormat)).
+		Preload("User.ProfileSkin").
+		Preload("College").
+		Order("rank ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, nil, err
+	}
+	return entries, &latest.SnapshotDate, nil
+}
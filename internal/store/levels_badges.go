@@ -35,6 +35,7 @@ type Badge struct {
 	IsSecret         bool       `gorm:"default:false"`
 	IsLimitedEdition bool       `gorm:"default:false"`
 	AvailableUntil   *time.Time `gorm:"type:timestamp"`
+	CertificateTemplateID *int  `gorm:"type:integer"`
 	CreatedAt        time.Time  `gorm:"autoCreateTime"`
 }
 
@@ -43,18 +44,19 @@ func (Badge) TableName() string {
 }
 
 type ProfileSkin struct {
-	ID          uint      `gorm:"primaryKey"`
-	Name        string    `gorm:"size:100;not null"`
-	Description *string   `gorm:"type:text"`
-	PreviewURL  string    `gorm:"type:text;not null"`
-	UnlockMethod string   `gorm:"size:50;check:unlock_method IN ('xp', 'campaign', 'purchase', 'special')"`
-	XPCost      int       `gorm:"default:0"`
-	CampaignID  *int      `gorm:"index"`
-	IsActive    bool      `gorm:"default:true"`
-	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	Name            string    `gorm:"size:100;not null" json:"name"`
+	Description     *string   `gorm:"type:text" json:"description,omitempty"`
+	PreviewURL      string    `gorm:"type:text;not null" json:"preview_url"`
+	UnlockMethod    string    `gorm:"size:50;check:unlock_method IN ('xp', 'level', 'campaign', 'purchase', 'special')" json:"unlock_method"`
+	XPCost          int       `gorm:"default:0" json:"xp_cost"`
+	RequiredLevelID *int      `gorm:"type:integer" json:"required_level_id,omitempty"`
+	CampaignID      *int      `gorm:"index" json:"campaign_id,omitempty"`
+	IsActive        bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
 
 	// Relations
-	Campaign *Campaign `gorm:"foreignKey:CampaignID"`
+	Campaign *Campaign `gorm:"foreignKey:CampaignID" json:"-"`
 }
 
 func (ProfileSkin) TableName() string {
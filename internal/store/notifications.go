@@ -29,6 +29,30 @@ func (Notification) TableName() string {
 	return "notifications"
 }
 
+// NotificationListener is called once a new notification has committed. It is
+// set by services.InitNotifier to push notifications to connected clients.
+var NotificationListener func(db *gorm.DB, notification *Notification)
+
+// AfterCreate hands every new notification to the listener, whichever code
+// path created it, once the transaction creating it has committed so a
+// rollback never delivers it. Notifications scheduled for later are queued,
+// in the same transaction, as a job that pushes them when they come due.
+func (n *Notification) AfterCreate(tx *gorm.DB) error {
+	if n.UserID == nil {
+		return nil
+	}
+	if n.ScheduledFor != nil && n.ScheduledFor.After(time.Now()) {
+		_, err := EnqueueJob(tx.Session(&gorm.Session{NewDB: true}), JobTypePushNotification, map[string]uint{"notification_id": n.ID}, *n.ScheduledFor)
+		return err
+	}
+	if NotificationListener != nil {
+		AfterCommit(tx, func(db *gorm.DB) {
+			NotificationListener(db, n)
+		})
+	}
+	return nil
+}
+
 func CreateNotification(db *gorm.DB, notification *Notification) error {
 	return db.Create(notification).Error
 }
@@ -40,3 +64,26 @@ func GetNotificationByID(db *gorm.DB, id uint) (*Notification, error) {
 	}
 	return &notification, nil
 }
+
+func CountUnreadNotifications(db *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	if err := db.Model(&Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetNotificationsAfter returns the user's delivered notifications with an ID
+// greater than afterID, oldest first, so a reconnecting client can catch up.
+func GetNotificationsAfter(db *gorm.DB, userID uint, afterID uint, limit int) ([]Notification, error) {
+	var notifications []Notification
+	if err := db.Where("user_id = ? AND id > ? AND (scheduled_for IS NULL OR scheduled_for <= ?)", userID, afterID, time.Now()).
+		Order("id ASC").
+		Limit(limit).
+		Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+// PasswordResetToken stores only the SHA-256 hash of the token sent to the user.
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    int        `gorm:"not null;index"`
+	TokenHash string     `gorm:"size:64;unique;not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time `gorm:"type:timestamp"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// HashToken returns the hex encoded SHA-256 digest of a plaintext token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreatePasswordResetToken stores a new token for the user and invalidates any
+// token previously issued to them.
+func CreatePasswordResetToken(db *gorm.DB, userID uint, token string, ttl time.Duration) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&PasswordResetToken{
+			UserID:    int(userID),
+			TokenHash: HashToken(token),
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+}
+
+// ConsumePasswordResetToken marks an unused, unexpired token as used and
+// returns it. A token can only be consumed once.
+func ConsumePasswordResetToken(db *gorm.DB, token string) (*PasswordResetToken, error) {
+	var resetToken PasswordResetToken
+	if err := db.Where("token_hash = ?", HashToken(token)).First(&resetToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidResetToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	result := db.Model(&PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", resetToken.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidResetToken
+	}
+
+	resetToken.UsedAt = &now
+	return &resetToken, nil
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Profile skin unlock methods
+const (
+	SkinUnlockXP       = "xp"
+	SkinUnlockLevel    = "level"
+	SkinUnlockCampaign = "campaign"
+	SkinUnlockPurchase = "purchase"
+	SkinUnlockSpecial  = "special"
+)
+
+var (
+	ErrSkinLocked       = errors.New("profile skin is locked")
+	ErrSkinNotOwned     = errors.New("you have not unlocked this profile skin")
+	ErrSkinAlreadyOwned = errors.New("you have already unlocked this profile skin")
+)
+
+// UserProfileSkin records a profile skin a user has unlocked and how.
+type UserProfileSkin struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	SkinID      uint      `gorm:"not null;index" json:"skin_id"`
+	UnlockedVia string    `gorm:"size:50;not null" json:"unlocked_via"` // xp, level, campaign, reward, etc.
+	UnlockedAt  time.Time `gorm:"autoCreateTime" json:"unlocked_at"`
+
+	// Relations
+	Skin *ProfileSkin `gorm:"foreignKey:SkinID" json:"skin,omitempty"`
+}
+
+func (UserProfileSkin) TableName() string { return "user_profile_skins" }
+
+// GetActiveProfileSkins lists the skins users can currently see.
+func GetActiveProfileSkins(db *gorm.DB) ([]ProfileSkin, error) {
+	var skins []ProfileSkin
+	err := db.Where("is_active = ?", true).Order("id ASC").Find(&skins).Error
+	return skins, err
+}
+
+// GetUserProfileSkins lists the skins the user has unlocked.
+func GetUserProfileSkins(db *gorm.DB, userID uint) ([]UserProfileSkin, error) {
+	var owned []UserProfileSkin
+	err := db.Where("user_id = ?", userID).Order("unlocked_at ASC").Find(&owned).Error
+	return owned, err
+}
+
+// HasProfileSkin reports whether the user has unlocked a skin.
+func HasProfileSkin(db *gorm.DB, userID, skinID uint) (bool, error) {
+	var count int64
+	err := db.Model(&UserProfileSkin{}).Where("user_id = ? AND skin_id = ?", userID, skinID).Count(&count).Error
+	return count > 0, err
+}
+
+// GrantProfileSkin unlocks a skin for a user. It reports false if they
+// already had it.
+func GrantProfileSkin(db *gorm.DB, userID, skinID uint, via string) (bool, error) {
+	owned := UserProfileSkin{
+		UserID:      userID,
+		SkinID:      skinID,
+		UnlockedVia: via,
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&owned)
+	return result.RowsAffected > 0, result.Error
+}
+
+// EquipProfileSkin sets the user's equipped skin. A nil skinID unequips it;
+// otherwise the user must have unlocked the skin.
+func EquipProfileSkin(db *gorm.DB, userID uint, skinID *uint) error {
+	var value interface{}
+	if skinID != nil {
+		owned, err := HasProfileSkin(db, userID, *skinID)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			return ErrSkinNotOwned
+		}
+		value = *skinID
+	}
+	return db.Model(&User{}).Where("id = ?", userID).Update("profile_skin_id", value).Error
+}
+
+// GetEquippedSkins maps each of the users that has a skin equipped to it.
+func GetEquippedSkins(db *gorm.DB, userIDs []uint) (map[uint]*ProfileSkin, error) {
+	equipped := make(map[uint]*ProfileSkin)
+	if len(userIDs) == 0 {
+		return equipped, nil
+	}
+
+	var users []User
+	if err := db.Select("id", "profile_skin_id").
+		Where("id IN ? AND profile_skin_id IS NOT NULL", userIDs).
+		Preload("ProfileSkin").
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if users[i].ProfileSkin != nil {
+			equipped[users[i].ID] = users[i].ProfileSkin
+		}
+	}
+	return equipped, nil
+}
+
+// HasReachedLevel reports whether the user's level ranks at or above the
+// given level.
+func HasReachedLevel(db *gorm.DB, user *User, levelID uint) (bool, error) {
+	if user.LevelID == nil {
+		return false, nil
+	}
+	var count int64
+	err := db.Table("levels AS current").
+		Joins("JOIN levels AS required ON required.id = ?", levelID).
+		Where("current.id = ? AND current.rank_order >= required.rank_order", *user.LevelID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// HasCompletedCampaign reports whether the campaign is completed and the user
+// had a submission approved in it.
+func HasCompletedCampaign(db *gorm.DB, userID, campaignID uint) (bool, error) {
+	var count int64
+	err := db.Model(&Submission{}).
+		Joins("JOIN campaigns ON campaigns.id = submissions.campaign_id").
+		Where("submissions.user_id = ? AND submissions.campaign_id = ?", userID, campaignID).
+		Where("submissions.status = 'approved' AND campaigns.status = 'completed'").
+		Count(&count).Error
+	return count > 0, err
+}
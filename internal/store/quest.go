@@ -1,9 +1,14 @@
 package store
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Quest struct {
@@ -22,6 +27,88 @@ func (Quest) TableName() string {
 	return "quests"
 }
 
+// Quest step types. Each is advanced by the domain event of the same name.
+const (
+	QuestStepSubmissionApproved = "submission_approved"
+	QuestStepReferral           = "referral"
+	QuestStepBattleEntered      = "battle_entered"
+	QuestStepBattleWon          = "battle_won"
+)
+
+var questStepTypes = map[string]bool{
+	QuestStepSubmissionApproved: true,
+	QuestStepReferral:           true,
+	QuestStepBattleEntered:      true,
+	QuestStepBattleWon:          true,
+}
+
+// QuestStep is one declarative condition of a quest: Count events of Type,
+// optionally limited to a campaign or task. Quest.Steps holds them in order,
+// e.g.
+//
+//	[{"type": "submission_approved", "count": 3, "campaign_id": 12, "description": "Get 3 proofs approved"},
+//	 {"type": "referral", "count": 1},
+//	 {"type": "battle_won", "count": 1}]
+type QuestStep struct {
+	Type        string `json:"type"`
+	Count       int    `json:"count"`
+	CampaignID  *int   `json:"campaign_id,omitempty"`
+	TaskID      *int   `json:"task_id,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// QuestEvent is a domain event that may advance a quest step.
+type QuestEvent struct {
+	Type       string
+	CampaignID *int
+	TaskID     *int
+}
+
+// Matches reports whether the event counts towards the step.
+func (s QuestStep) Matches(event QuestEvent) bool {
+	if s.Type != event.Type {
+		return false
+	}
+	if s.CampaignID != nil && (event.CampaignID == nil || *event.CampaignID != *s.CampaignID) {
+		return false
+	}
+	if s.TaskID != nil && (event.TaskID == nil || *event.TaskID != *s.TaskID) {
+		return false
+	}
+	return true
+}
+
+// ParseSteps decodes and checks the quest's steps. A step without a count
+// needs one event.
+func (q *Quest) ParseSteps() ([]QuestStep, error) {
+	var steps []QuestStep
+	if err := json.Unmarshal([]byte(q.Steps), &steps); err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("quest has no steps")
+	}
+	for i := range steps {
+		if !questStepTypes[steps[i].Type] {
+			return nil, fmt.Errorf("step %d has unknown type %q", i+1, steps[i].Type)
+		}
+		if steps[i].Count <= 0 {
+			steps[i].Count = 1
+		}
+	}
+	return steps, nil
+}
+
+// ParseRewards decodes the reward paid when the quest is completed.
+func (q *Quest) ParseRewards() (Reward, error) {
+	var reward Reward
+	if q.Rewards == nil || *q.Rewards == "" {
+		return reward, nil
+	}
+	err := json.Unmarshal([]byte(*q.Rewards), &reward)
+	return reward, err
+}
+
 type UserQuest struct {
 	ID           uint       `gorm:"primaryKey"`
 	UserID       *int       `gorm:"index;constraint:OnDelete:CASCADE"`
@@ -31,6 +118,7 @@ type UserQuest struct {
 	Status       string     `gorm:"size:20;default:'in_progress';check:status IN ('in_progress', 'completed', 'failed', 'abandoned')"`
 	StartedAt    time.Time  `gorm:"autoCreateTime"`
 	CompletedAt  *time.Time `gorm:"type:timestamp"`
+	ExpiresAt    *time.Time `gorm:"type:timestamp"`
 	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
 
 	// Relations
@@ -42,6 +130,24 @@ func (UserQuest) TableName() string {
 	return "user_quests"
 }
 
+// QuestProgress is the shape of UserQuest.ProgressData: how many matching
+// events each step has counted.
+type QuestProgress struct {
+	Counts []int `json:"counts"`
+}
+
+// Progress decodes the quest's progress, sized to its steps.
+func (uq *UserQuest) Progress(steps int) QuestProgress {
+	var progress QuestProgress
+	if uq.ProgressData != nil {
+		json.Unmarshal([]byte(*uq.ProgressData), &progress)
+	}
+	for len(progress.Counts) < steps {
+		progress.Counts = append(progress.Counts, 0)
+	}
+	return progress
+}
+
 func CreateQuest(db *gorm.DB, quest *Quest) error {
 	return db.Create(quest).Error
 }
@@ -65,3 +171,182 @@ func GetUserQuestByID(db *gorm.DB, id uint) (*UserQuest, error) {
 	}
 	return &userQuest, nil
 }
+
+// GetActiveQuests lists the quests users can accept.
+func GetActiveQuests(db *gorm.DB) ([]Quest, error) {
+	var quests []Quest
+	err := db.Where("is_active = ?", true).Order("created_at DESC").Find(&quests).Error
+	return quests, err
+}
+
+// GetUserQuests lists a user's quests, most recent first, with their quest.
+// An empty status returns all of them.
+func GetUserQuests(db *gorm.DB, userID uint, status string) ([]UserQuest, error) {
+	var userQuests []UserQuest
+	query := db.Preload("Quest").Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("started_at DESC").Find(&userQuests).Error
+	return userQuests, err
+}
+
+// GetUserQuest returns a user's attempt at a quest.
+func GetUserQuest(db *gorm.DB, userID, questID uint) (*UserQuest, error) {
+	var userQuest UserQuest
+	if err := db.Where("user_id = ? AND quest_id = ?", userID, questID).First(&userQuest).Error; err != nil {
+		return nil, err
+	}
+	return &userQuest, nil
+}
+
+var (
+	ErrQuestInProgress = errors.New("quest already in progress")
+	ErrQuestCompleted  = errors.New("quest already completed")
+)
+
+// AcceptQuest starts a quest for a user. A failed or abandoned attempt is
+// restarted from the first step.
+func AcceptQuest(db *gorm.DB, userID uint, quest *Quest) (*UserQuest, error) {
+	now := time.Now()
+	var expiresAt *time.Time
+	if quest.TimeLimitDays != nil && *quest.TimeLimitDays > 0 {
+		at := now.AddDate(0, 0, *quest.TimeLimitDays)
+		expiresAt = &at
+	}
+
+	var userQuest *UserQuest
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing UserQuest
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND quest_id = ?", userID, quest.ID).
+			First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err == nil {
+			switch existing.Status {
+			case "in_progress":
+				return ErrQuestInProgress
+			case "completed":
+				return ErrQuestCompleted
+			}
+			empty := "{}"
+			existing.CurrentStep = 1
+			existing.ProgressData = &empty
+			existing.Status = "in_progress"
+			existing.StartedAt = now
+			existing.CompletedAt = nil
+			existing.ExpiresAt = expiresAt
+			if err := tx.Save(&existing).Error; err != nil {
+				return err
+			}
+			userQuest = &existing
+			return nil
+		}
+
+		userIDInt := int(userID)
+		questID := int(quest.ID)
+		userQuest = &UserQuest{
+			UserID:      &userIDInt,
+			QuestID:     &questID,
+			CurrentStep: 1,
+			Status:      "in_progress",
+			StartedAt:   now,
+			ExpiresAt:   expiresAt,
+		}
+		return tx.Create(userQuest).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userQuest, nil
+}
+
+// AdvanceQuests counts an event towards the current step of each of the
+// user's quests in progress. A step that reaches its count moves the quest to
+// the next step, and finishing the last step completes it. Quests completed
+// by the event are returned with their Quest loaded. Quests whose steps do
+// not parse are logged and skipped.
+func AdvanceQuests(db *gorm.DB, userID uint, event QuestEvent, now time.Time) ([]UserQuest, error) {
+	var userQuests []UserQuest
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND status = 'in_progress' AND (expires_at IS NULL OR expires_at > ?)", userID, now).
+		Find(&userQuests).Error; err != nil {
+		return nil, err
+	}
+
+	var completed []UserQuest
+	for i := range userQuests {
+		userQuest := &userQuests[i]
+		quest, err := GetQuestByID(db, uint(*userQuest.QuestID))
+		if err != nil {
+			return nil, err
+		}
+		steps, err := quest.ParseSteps()
+		if err != nil {
+			// A broken quest must not hold back the user's other quests
+			log.Printf("quests: skipping quest %d with invalid steps: %v", quest.ID, err)
+			continue
+		}
+
+		index := userQuest.CurrentStep - 1
+		if index < 0 || index >= len(steps) || !steps[index].Matches(event) {
+			continue
+		}
+
+		progress := userQuest.Progress(len(steps))
+		progress.Counts[index]++
+		updates := map[string]interface{}{}
+		if progress.Counts[index] >= steps[index].Count {
+			if index+1 < len(steps) {
+				userQuest.CurrentStep++
+			} else {
+				userQuest.Status = "completed"
+				userQuest.CompletedAt = &now
+				updates["status"] = userQuest.Status
+				updates["completed_at"] = now
+			}
+		}
+		progressJSON, _ := json.Marshal(progress)
+		progressData := string(progressJSON)
+		userQuest.ProgressData = &progressData
+		updates["current_step"] = userQuest.CurrentStep
+		updates["progress_data"] = progressData
+
+		if err := db.Model(&UserQuest{}).Where("id = ?", userQuest.ID).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+		if userQuest.Status == "completed" {
+			userQuest.Quest = quest
+			completed = append(completed, *userQuest)
+		}
+	}
+	return completed, nil
+}
+
+// ExpireQuests fails every quest in progress whose time limit has passed and
+// returns them.
+func ExpireQuests(db *gorm.DB, now time.Time) ([]UserQuest, error) {
+	var expired []UserQuest
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Preload("Quest").
+			Where("status = 'in_progress' AND expires_at <= ?", now).
+			Find(&expired).Error; err != nil {
+			return err
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(expired))
+		for i := range expired {
+			ids[i] = expired[i].ID
+			expired[i].Status = "failed"
+		}
+		return tx.Model(&UserQuest{}).Where("id IN ?", ids).Update("status", "failed").Error
+	})
+	return expired, err
+}
@@ -21,6 +21,7 @@ type RewardStore struct {
 	IsFeatured      bool       `gorm:"default:false"`
 	IsActive        bool       `gorm:"default:true"`
 	ValidityDays    *int       `gorm:"type:integer"`
+	ProfileSkinID   *int       `gorm:"type:integer"` // skin granted by profile_skin rewards
 	CreatedAt       time.Time  `gorm:"autoCreateTime"`
 	UpdatedAt       time.Time  `gorm:"autoUpdateTime"`
 }
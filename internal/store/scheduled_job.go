@@ -0,0 +1,240 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Scheduled job statuses
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job types
+const (
+	// JobTypePushNotification pushes a scheduled notification once it is due
+	JobTypePushNotification = "push_notification"
+	// JobTypeExpireRevisions rejects submissions past their revision deadline
+	JobTypeExpireRevisions = "expire_revisions"
+	// JobTypeExpireQuests fails accepted quests past their time limit
+	JobTypeExpireQuests = "expire_quests"
+	// JobTypeCampusWarScoring rescores the active campus wars
+	JobTypeCampusWarScoring = "campus_war_scoring"
+	// JobTypeLeaderboardSnapshots rebuilds the day's leaderboard snapshots
+	JobTypeLeaderboardSnapshots = "leaderboard_snapshots"
+)
+
+var (
+	ErrJobNotCancellable = errors.New("only pending jobs can be cancelled")
+	ErrJobNotRerunnable  = errors.New("only completed, failed or cancelled jobs can be re-run")
+)
+
+// EnqueueJob schedules a job of the given type. data is stored as the job's
+// JSON payload.
+func EnqueueJob(db *gorm.DB, jobType string, data interface{}, runAt time.Time) (*ScheduledJob, error) {
+	job := &ScheduledJob{
+		JobType:      jobType,
+		ScheduledFor: runAt,
+		Status:       JobPending,
+	}
+	if data != nil {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		jobData := string(payload)
+		job.JobData = &jobData
+	}
+	if err := db.Create(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// EnsureRecurringJob creates the recurring job of the given type, first due
+// at runAt, unless it already exists. An existing one is left as it is, so a
+// recurring job an admin cancelled stays cancelled until it is re-run.
+func EnsureRecurringJob(db *gorm.DB, jobType string, runAt time.Time) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "job_type"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "is_recurring"}}},
+		DoNothing:   true,
+	}).Create(&ScheduledJob{
+		JobType:      jobType,
+		ScheduledFor: runAt,
+		Status:       JobPending,
+		IsRecurring:  true,
+	}).Error
+}
+
+// ClaimDueJob locks the oldest due pending job of one of the given types and
+// marks it running. Rows locked by other workers are skipped, so concurrent
+// workers never claim the same job. It returns gorm.ErrRecordNotFound when no
+// job is due.
+func ClaimDueJob(db *gorm.DB, jobTypes []string, now time.Time) (*ScheduledJob, error) {
+	var job ScheduledJob
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND scheduled_for <= ? AND job_type IN ?", JobPending, now, jobTypes).
+			Order("scheduled_for ASC, id ASC").
+			First(&job).Error; err != nil {
+			return err
+		}
+
+		job.Status = JobRunning
+		job.Attempts++
+		job.StartedAt = &now
+		job.CompletedAt = nil
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status":       job.Status,
+			"attempts":     job.Attempts,
+			"started_at":   job.StartedAt,
+			"completed_at": nil,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CompleteJob records a successful run.
+func CompleteJob(db *gorm.DB, job *ScheduledJob, result *string) error {
+	now := time.Now()
+	return db.Model(job).Updates(map[string]interface{}{
+		"status":        JobCompleted,
+		"result":        result,
+		"error_message": nil,
+		"completed_at":  now,
+	}).Error
+}
+
+// FailJob records a failed run. A job with a retryAt goes back to pending;
+// otherwise it is marked failed for good.
+func FailJob(db *gorm.DB, job *ScheduledJob, message string, retryAt *time.Time) error {
+	updates := map[string]interface{}{
+		"error_message": message,
+	}
+	if retryAt != nil {
+		updates["status"] = JobPending
+		updates["scheduled_for"] = *retryAt
+	} else {
+		updates["status"] = JobFailed
+		updates["completed_at"] = time.Now()
+	}
+	return db.Model(job).Updates(updates).Error
+}
+
+// RescheduleJob records the outcome of a recurring job's run and queues its
+// next run at runAt with a fresh attempt count.
+func RescheduleJob(db *gorm.DB, job *ScheduledJob, result, message *string, runAt time.Time) error {
+	return db.Model(job).Updates(map[string]interface{}{
+		"status":        JobPending,
+		"scheduled_for": runAt,
+		"attempts":      0,
+		"result":        result,
+		"error_message": message,
+		"completed_at":  time.Now(),
+	}).Error
+}
+
+// ReleaseJob returns a claimed job to the queue without counting the attempt,
+// for runs interrupted by a shutdown.
+func ReleaseJob(db *gorm.DB, job *ScheduledJob) error {
+	return db.Model(job).Updates(map[string]interface{}{
+		"status":   JobPending,
+		"attempts": gorm.Expr("GREATEST(attempts - 1, 0)"),
+	}).Error
+}
+
+// RequeueStaleJobs returns jobs that have been running since before the
+// cutoff to the queue. Such jobs were claimed by a worker that died.
+func RequeueStaleJobs(db *gorm.DB, startedBefore time.Time) (int64, error) {
+	result := db.Model(&ScheduledJob{}).
+		Where("status = ? AND started_at < ?", JobRunning, startedBefore).
+		Updates(map[string]interface{}{
+			"status":        JobPending,
+			"error_message": "requeued after the worker stopped responding",
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListScheduledJobs returns jobs newest first, optionally filtered by status
+// and type, with the total count for pagination.
+func ListScheduledJobs(db *gorm.DB, status, jobType string, limit, offset int) ([]ScheduledJob, int64, error) {
+	query := db.Model(&ScheduledJob{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if jobType != "" {
+		query = query.Where("job_type = ?", jobType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []ScheduledJob
+	if err := query.Order("scheduled_for DESC, id DESC").Limit(limit).Offset(offset).Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// CancelJob cancels a pending job.
+func CancelJob(db *gorm.DB, id uint) (*ScheduledJob, error) {
+	result := db.Model(&ScheduledJob{}).
+		Where("id = ? AND status = ?", id, JobPending).
+		Updates(map[string]interface{}{
+			"status":       JobCancelled,
+			"completed_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	job, err := GetScheduledJobByID(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrJobNotCancellable
+	}
+	return job, nil
+}
+
+// RerunJob queues a finished job to run again now with a fresh attempt count.
+func RerunJob(db *gorm.DB, id uint) (*ScheduledJob, error) {
+	result := db.Model(&ScheduledJob{}).
+		Where("id = ? AND status IN ?", id, []string{JobCompleted, JobFailed, JobCancelled}).
+		Updates(map[string]interface{}{
+			"status":        JobPending,
+			"scheduled_for": time.Now(),
+			"attempts":      0,
+			"result":        nil,
+			"error_message": nil,
+			"started_at":    nil,
+			"completed_at":  nil,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	job, err := GetScheduledJobByID(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrJobNotRerunnable
+	}
+	return job, nil
+}
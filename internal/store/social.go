@@ -19,6 +19,8 @@ type SocialPost struct {
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt   *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+
+	AuthorSkin *ProfileSkin `gorm:"-" json:"author_skin,omitempty"` // author's equipped skin, filled for feeds
 }
 
 func (SocialPost) TableName() string { return "social_posts" }
@@ -69,6 +71,18 @@ func GetSocialFeed(db *gorm.DB, userID uint, limit int, offset int) ([]SocialPos
 	if err := query.Find(&posts).Error; err != nil {
 		return nil, err
 	}
+
+	authorIDs := make([]uint, len(posts))
+	for i := range posts {
+		authorIDs[i] = posts[i].UserID
+	}
+	skins, err := GetEquippedSkins(db, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range posts {
+		posts[i].AuthorSkin = skins[posts[i].UserID]
+	}
 	return posts, nil
 }
 
@@ -1,9 +1,11 @@
 package store
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Submission struct {
@@ -15,7 +17,7 @@ type Submission struct {
 	ProofType        string     `gorm:"size:50;not null"`
 	ProofURL         string     `gorm:"type:text;not null"`
 	ProofText        *string    `gorm:"type:text"`
-	Status           string     `gorm:"size:20;default:'pending';check:status IN ('draft', 'pending', 'under_review', 'approved', 'rejected', 'needs_revision')"`
+	Status           string     `gorm:"size:20;default:'pending';check:status IN ('draft', 'pending', 'under_review', 'approved', 'rejected', 'needs_revision', 'appealed')"`
 	SubmissionStage  string     `gorm:"size:20;default:'initial';check:submission_stage IN ('initial', 'resubmission')"`
 	SubmittedAt      time.Time  `gorm:"autoCreateTime"`
 	ReviewedAt       *time.Time `gorm:"type:timestamp"`
@@ -40,6 +42,30 @@ func (Submission) TableName() string {
 	return "submissions"
 }
 
+// SubmissionRevision is a snapshot of a submission's proof taken before the
+// user resubmits it.
+type SubmissionRevision struct {
+	ID               uint       `gorm:"primaryKey"`
+	SubmissionID     int        `gorm:"not null;index"`
+	SubmissionStage  string     `gorm:"size:20;not null;check:submission_stage IN ('initial', 'resubmission')"`
+	ProofType        string     `gorm:"size:50;not null"`
+	ProofURL         string     `gorm:"type:text;not null"`
+	ProofText        *string    `gorm:"type:text"`
+	SubmittedAt      time.Time  `gorm:"not null"`
+	ReviewedBy       *int       `gorm:"index"`
+	ReviewedAt       *time.Time `gorm:"type:timestamp"`
+	ReviewComments   *string    `gorm:"type:text"`
+	RevisionDeadline *time.Time `gorm:"type:timestamp"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime"`
+
+	// Relations
+	Submission *Submission `gorm:"foreignKey:SubmissionID;constraint:OnDelete:CASCADE"`
+}
+
+func (SubmissionRevision) TableName() string {
+	return "submission_revisions"
+}
+
 type SubmissionMedia struct {
 	ID           uint      `gorm:"primaryKey"`
 	SubmissionID *int      `gorm:"index"`
@@ -97,10 +123,234 @@ func GetSubmissionsByUserAndTasks(db *gorm.DB, userID uint, taskIDs []uint) ([]S
 	return submissions, nil
 }
 
-func GetTaskAssignmentsByUser(db *gorm.DB, userID uint) ([]TaskAssignment, error) {
+// GetTaskAssignmentsByUser returns assignments that target the user directly
+// or through their role, college or state.
+func GetTaskAssignmentsByUser(db *gorm.DB, user *User) ([]TaskAssignment, error) {
 	var assignments []TaskAssignment
-	if err := db.Where("assignee_id = ?", userID).Find(&assignments).Error; err != nil {
+	query := db.Where("assignee_type = 'user' AND assignee_id = ?", user.ID).
+		Or("assignee_type = 'role' AND assignee_role = ?", user.Role)
+	if user.CollegeID != nil {
+		query = query.Or("assignee_type = 'college' AND assignee_id = ?", *user.CollegeID)
+	}
+	if user.StateID != nil {
+		query = query.Or("assignee_type = 'state' AND assignee_id = ?", *user.StateID)
+	}
+	if err := query.Find(&assignments).Error; err != nil {
 		return nil, err
 	}
 	return assignments, nil
 }
+
+// PaySubmissionRewards credits the XP and coins of a submission's task to its
+// author and records the amounts on the submission. The source identifies what
+// triggered the payout (a review, an appeal, ...). Callers are responsible for
+// guarding against paying the same submission twice.
+func PaySubmissionRewards(db *gorm.DB, submission *Submission, sourceType string, sourceID uint) error {
+	if submission.TaskID == nil || submission.UserID == nil {
+		return errors.New("submission is missing task or user")
+	}
+
+	task, err := GetTaskByID(db, uint(*submission.TaskID))
+	if err != nil {
+		return err
+	}
+
+	userID := uint(*submission.UserID)
+	if task.XPReward != 0 {
+		if _, err := AwardXP(db, userID, task.XPReward, "task_completion", sourceType, sourceID, "Task completed: "+task.Title); err != nil {
+			return err
+		}
+	}
+	if task.CoinReward != 0 {
+		if _, err := CreditCoins(db, userID, task.CoinReward, "Task completed: "+task.Title, sourceType, sourceID); err != nil {
+			return err
+		}
+	}
+
+	submission.XPAwarded = task.XPReward
+	submission.CoinsAwarded = task.CoinReward
+	if err := db.Model(&Submission{}).Where("id = ?", submission.ID).Updates(map[string]interface{}{
+		"xp_awarded":    task.XPReward,
+		"coins_awarded": task.CoinReward,
+	}).Error; err != nil {
+		return err
+	}
+
+	if err := db.Model(&User{}).Where("id = ?", userID).
+		UpdateColumn("approved_submissions", gorm.Expr("approved_submissions + 1")).Error; err != nil {
+		return err
+	}
+
+	return RefreshUserWinRate(db, userID)
+}
+
+var (
+	ErrSubmissionAlreadyReviewed = errors.New("submission has already been reviewed")
+	ErrSubmissionNotRevisable    = errors.New("submission is no longer open for revision")
+)
+
+// ReviewSubmission records a reviewer's decision on a pending submission and,
+// when approved, pays out its rewards in the same transaction. The status
+// change only applies to submissions still awaiting review, so a submission
+// can never be paid twice.
+func ReviewSubmission(db *gorm.DB, submission *Submission, status string, reviewerID int, comments string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&Submission{}).
+			Where("id = ? AND status IN ('pending', 'under_review')", submission.ID).
+			Updates(map[string]interface{}{
+				"status":          status,
+				"reviewed_by":     reviewerID,
+				"reviewed_at":     now,
+				"review_comments": comments,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSubmissionAlreadyReviewed
+		}
+
+		submission.Status = status
+		submission.ReviewedBy = &reviewerID
+		submission.ReviewedAt = &now
+		submission.ReviewComments = &comments
+
+		if status != "approved" {
+			if submission.UserID == nil {
+				return nil
+			}
+			return RefreshUserWinRate(tx, uint(*submission.UserID))
+		}
+		return PaySubmissionRewards(tx, submission, "submission", submission.ID)
+	})
+}
+
+// RefreshUserWinRate recomputes the percentage of a user's submissions that
+// were approved.
+func RefreshUserWinRate(db *gorm.DB, userID uint) error {
+	return db.Model(&User{}).Where("id = ?", userID).
+		UpdateColumn("win_rate", gorm.Expr(
+			"CASE WHEN total_submissions > 0 THEN LEAST(ROUND(approved_submissions * 100.0 / total_submissions, 2), 100) ELSE 0 END",
+		)).Error
+}
+
+// RequestSubmissionRevision sends a pending submission back to its author
+// with a deadline for resubmitting.
+func RequestSubmissionRevision(db *gorm.DB, submission *Submission, reviewerID int, comments string, deadline time.Time) error {
+	now := time.Now()
+	result := db.Model(&Submission{}).
+		Where("id = ? AND status IN ('pending', 'under_review')", submission.ID).
+		Updates(map[string]interface{}{
+			"status":            "needs_revision",
+			"reviewed_by":       reviewerID,
+			"reviewed_at":       now,
+			"review_comments":   comments,
+			"revision_deadline": deadline,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSubmissionAlreadyReviewed
+	}
+
+	submission.Status = "needs_revision"
+	submission.ReviewedBy = &reviewerID
+	submission.ReviewedAt = &now
+	submission.ReviewComments = &comments
+	submission.RevisionDeadline = &deadline
+	return nil
+}
+
+// ResubmitSubmission archives the current proof of a submission that needs
+// revision and replaces it with the new proof as a resubmission. It returns
+// ErrSubmissionNotRevisable once the submission is no longer awaiting
+// revision or its deadline has passed.
+func ResubmitSubmission(db *gorm.DB, submission *Submission, proofURL string, proofText *string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		revision := &SubmissionRevision{
+			SubmissionID:     int(submission.ID),
+			SubmissionStage:  submission.SubmissionStage,
+			ProofType:        submission.ProofType,
+			ProofURL:         submission.ProofURL,
+			ProofText:        submission.ProofText,
+			SubmittedAt:      submission.SubmittedAt,
+			ReviewedBy:       submission.ReviewedBy,
+			ReviewedAt:       submission.ReviewedAt,
+			ReviewComments:   submission.ReviewComments,
+			RevisionDeadline: submission.RevisionDeadline,
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&Submission{}).
+			Where("id = ? AND status = 'needs_revision' AND (revision_deadline IS NULL OR revision_deadline > ?)", submission.ID, now).
+			Updates(map[string]interface{}{
+				"status":           "pending",
+				"submission_stage": "resubmission",
+				"proof_url":        proofURL,
+				"proof_text":       proofText,
+				"submitted_at":     now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Resubmitted concurrently, or the deadline passed meanwhile
+			return ErrSubmissionNotRevisable
+		}
+
+		submission.Status = "pending"
+		submission.SubmissionStage = "resubmission"
+		submission.ProofURL = proofURL
+		submission.ProofText = proofText
+		submission.SubmittedAt = now
+		return nil
+	})
+}
+
+func GetSubmissionRevisions(db *gorm.DB, submissionID uint) ([]SubmissionRevision, error) {
+	var revisions []SubmissionRevision
+	if err := db.Where("submission_id = ?", submissionID).Order("created_at ASC").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// ExpireSubmissionRevisions rejects every submission whose revision deadline
+// has passed and returns the submissions it rejected.
+func ExpireSubmissionRevisions(db *gorm.DB, now time.Time) ([]Submission, error) {
+	var expired []Submission
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = 'needs_revision' AND revision_deadline IS NOT NULL AND revision_deadline < ?", now).
+			Find(&expired).Error; err != nil {
+			return err
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(expired))
+		for i, sub := range expired {
+			ids[i] = sub.ID
+		}
+		if err := tx.Model(&Submission{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":          "rejected",
+			"reviewed_at":     now,
+			"review_comments": "Automatically rejected: revision deadline passed",
+		}).Error; err != nil {
+			return err
+		}
+
+		for i := range expired {
+			expired[i].Status = "rejected"
+			expired[i].ReviewedAt = &now
+		}
+		return nil
+	})
+	return expired, err
+}
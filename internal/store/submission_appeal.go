@@ -0,0 +1,125 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrAppealAlreadyResolved = errors.New("appeal has already been resolved")
+
+type SubmissionAppeal struct {
+	ID             uint       `gorm:"primaryKey"`
+	SubmissionID   int        `gorm:"not null;unique"`
+	UserID         int        `gorm:"not null;index"`
+	Reason         string     `gorm:"type:text;not null"`
+	Status         string     `gorm:"size:20;default:'pending';check:status IN ('pending', 'upheld', 'overturned')"`
+	ReviewedBy     *int       `gorm:"index"`
+	ReviewComments *string    `gorm:"type:text"`
+	ReviewedAt     *time.Time `gorm:"type:timestamp"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+
+	// Relations
+	Submission *Submission `gorm:"foreignKey:SubmissionID;constraint:OnDelete:CASCADE"`
+	User       *User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Reviewer   *User       `gorm:"foreignKey:ReviewedBy"`
+}
+
+func (SubmissionAppeal) TableName() string {
+	return "submission_appeals"
+}
+
+func GetSubmissionAppealByID(db *gorm.DB, id uint) (*SubmissionAppeal, error) {
+	var appeal SubmissionAppeal
+	if err := db.First(&appeal, id).Error; err != nil {
+		return nil, err
+	}
+	return &appeal, nil
+}
+
+func GetSubmissionAppealBySubmission(db *gorm.DB, submissionID uint) (*SubmissionAppeal, error) {
+	var appeal SubmissionAppeal
+	if err := db.Where("submission_id = ?", submissionID).First(&appeal).Error; err != nil {
+		return nil, err
+	}
+	return &appeal, nil
+}
+
+// CreateSubmissionAppeal files an appeal and moves the rejected submission to
+// the appealed status.
+func CreateSubmissionAppeal(db *gorm.DB, appeal *SubmissionAppeal) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Submission{}).
+			Where("id = ? AND status = 'rejected'", appeal.SubmissionID).
+			Update("status", "appealed")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("only rejected submissions can be appealed")
+		}
+		return tx.Create(appeal).Error
+	})
+}
+
+// ResolveSubmissionAppeal records the reviewer's decision on a pending appeal.
+// Overturning approves the submission and retroactively pays its rewards;
+// upholding returns it to rejected.
+func ResolveSubmissionAppeal(db *gorm.DB, appeal *SubmissionAppeal, overturn bool, reviewerID int, comments string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		status := "upheld"
+		submissionStatus := "rejected"
+		if overturn {
+			status = "overturned"
+			submissionStatus = "approved"
+		}
+
+		result := tx.Model(&SubmissionAppeal{}).
+			Where("id = ? AND status = 'pending'", appeal.ID).
+			Updates(map[string]interface{}{
+				"status":          status,
+				"reviewed_by":     reviewerID,
+				"review_comments": comments,
+				"reviewed_at":     now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAppealAlreadyResolved
+		}
+
+		result = tx.Model(&Submission{}).
+			Where("id = ? AND status = 'appealed'", appeal.SubmissionID).
+			Updates(map[string]interface{}{
+				"status":          submissionStatus,
+				"reviewed_by":     reviewerID,
+				"review_comments": comments,
+				"reviewed_at":     now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("submission is no longer under appeal")
+		}
+
+		appeal.Status = status
+		appeal.ReviewedBy = &reviewerID
+		appeal.ReviewComments = &comments
+		appeal.ReviewedAt = &now
+
+		if !overturn {
+			return nil
+		}
+
+		submission, err := GetSubmissionByID(tx, uint(appeal.SubmissionID))
+		if err != nil {
+			return err
+		}
+		return PaySubmissionRewards(tx, submission, "submission_appeal", appeal.ID)
+	})
+}
@@ -1,9 +1,20 @@
 package store
 
 import (
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Trivia modes. Async tournaments hand out every question at once; live
+// tournaments are played question by question over WebSocket.
+const (
+	TriviaModeAsync = "async"
+	TriviaModeLive  = "live"
 )
 
 type TriviaTournament struct {
@@ -19,9 +30,22 @@ type TriviaTournament struct {
 	EntryFeeXP      int        `gorm:"default:0"`
 	Rewards         *string    `gorm:"type:jsonb;default:'{}'"`
 	Status          string     `gorm:"size:20;default:'upcoming';check:status IN ('upcoming', 'active', 'completed')"`
+	Mode            string     `gorm:"size:20;not null;default:'async';check:mode IN ('async', 'live')"`
+	QuestionSeconds int        `gorm:"not null;default:20"`
+	CurrentQuestion int        `gorm:"not null;default:0"`
 	CreatedAt       time.Time  `gorm:"autoCreateTime"`
 }
 
+// ParseQuestions decodes the tournament's questions. Each question carries its
+// "correct_answer", which must be stripped before it is sent to players.
+func (t *TriviaTournament) ParseQuestions() ([]map[string]interface{}, error) {
+	var questions []map[string]interface{}
+	if err := json.Unmarshal([]byte(t.Questions), &questions); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
 func (TriviaTournament) TableName() string {
 	return "trivia_tournaments"
 }
@@ -36,6 +60,10 @@ type TriviaParticipant struct {
 	Rank             *int      `gorm:"type:integer"`
 	RewardClaimed    bool      `gorm:"default:false"`
 	ParticipatedAt   time.Time `gorm:"autoCreateTime"`
+	StartedAt        *time.Time
+	DeadlineAt       *time.Time
+	SubmittedAt      *time.Time
+	QuestionOrder    *string   `gorm:"type:jsonb"`
 
 	// Relations
 	Trivia *TriviaTournament `gorm:"foreignKey:TriviaID"`
@@ -46,6 +74,80 @@ func (TriviaParticipant) TableName() string {
 	return "trivia_participants"
 }
 
+// TriviaAnswer is one participant's answer to one question.
+type TriviaAnswer struct {
+	ID            uint      `gorm:"primaryKey"`
+	ParticipantID uint      `gorm:"not null;uniqueIndex:idx_trivia_answer_participant_question"`
+	TriviaID      uint      `gorm:"not null;index:idx_trivia_answers_trivia_question"`
+	QuestionIndex int       `gorm:"not null;uniqueIndex:idx_trivia_answer_participant_question;index:idx_trivia_answers_trivia_question"`
+	Answer        string    `gorm:"type:text;not null"`
+	IsCorrect     bool      `gorm:"not null;default:false"`
+	AnsweredAt    time.Time `gorm:"not null"`
+}
+
+func (TriviaAnswer) TableName() string {
+	return "trivia_answers"
+}
+
+// TriviaQuestionOrder is one entry of the order a participant was served the
+// questions in. Options lists the original option indexes in served order.
+type TriviaQuestionOrder struct {
+	QuestionID int   `json:"question_id"`
+	Options    []int `json:"options,omitempty"`
+}
+
+// ShuffleTriviaQuestions picks a random question order, and a random option
+// order within each question, for one participant.
+func ShuffleTriviaQuestions(questions []map[string]interface{}) []TriviaQuestionOrder {
+	order := make([]TriviaQuestionOrder, len(questions))
+	for i, question := range questions {
+		order[i].QuestionID = i
+		if options, ok := question["options"].([]interface{}); ok {
+			order[i].Options = rand.Perm(len(options))
+		}
+	}
+	rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	return order
+}
+
+// ArrangeTriviaQuestions returns the questions in the participant's order,
+// without their answers. Each question is tagged with the question_id that
+// answers must reference.
+func ArrangeTriviaQuestions(questions []map[string]interface{}, order []TriviaQuestionOrder) []map[string]interface{} {
+	arranged := make([]map[string]interface{}, 0, len(order))
+	for _, entry := range order {
+		if entry.QuestionID < 0 || entry.QuestionID >= len(questions) {
+			continue
+		}
+		question := make(map[string]interface{}, len(questions[entry.QuestionID])+1)
+		for k, v := range questions[entry.QuestionID] {
+			if k != "correct_answer" {
+				question[k] = v
+			}
+		}
+		if options, ok := question["options"].([]interface{}); ok && len(entry.Options) == len(options) {
+			shuffled := make([]interface{}, len(options))
+			for i, idx := range entry.Options {
+				shuffled[i] = options[idx]
+			}
+			question["options"] = shuffled
+		}
+		question["question_id"] = entry.QuestionID
+		arranged = append(arranged, question)
+	}
+	return arranged
+}
+
+// Order returns the question order the participant was served.
+func (p *TriviaParticipant) Order() ([]TriviaQuestionOrder, error) {
+	var order []TriviaQuestionOrder
+	if p.QuestionOrder == nil {
+		return order, nil
+	}
+	err := json.Unmarshal([]byte(*p.QuestionOrder), &order)
+	return order, err
+}
+
 func CreateTriviaTournament(db *gorm.DB, tournament *TriviaTournament) error {
 	return db.Create(tournament).Error
 }
@@ -69,3 +171,194 @@ func GetTriviaParticipantByID(db *gorm.DB, id uint) (*TriviaParticipant, error)
 	}
 	return &participant, nil
 }
+
+// JoinTriviaTournament creates the user's participant record. joined is false
+// when the user was already a participant.
+func JoinTriviaTournament(db *gorm.DB, triviaID, userID uint) (participant *TriviaParticipant, joined bool, err error) {
+	triviaIDInt := int(triviaID)
+	userIDInt := int(userID)
+	participant = &TriviaParticipant{
+		TriviaID:       &triviaIDInt,
+		UserID:         &userIDInt,
+		ParticipatedAt: time.Now(),
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(participant)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		participant, err = GetTriviaParticipant(db, triviaID, userID)
+		return participant, false, err
+	}
+	return participant, true, nil
+}
+
+func GetTriviaParticipant(db *gorm.DB, triviaID, userID uint) (*TriviaParticipant, error) {
+	var participant TriviaParticipant
+	if err := db.Where("trivia_id = ? AND user_id = ?", triviaID, userID).First(&participant).Error; err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+func CountTriviaParticipants(db *gorm.DB, triviaID uint) (int64, error) {
+	var count int64
+	err := db.Model(&TriviaParticipant{}).Where("trivia_id = ?", triviaID).Count(&count).Error
+	return count, err
+}
+
+// GetTriviaParticipantIDs maps the users taking part in a tournament to their
+// participant IDs.
+func GetTriviaParticipantIDs(db *gorm.DB, triviaID uint) (map[uint]uint, error) {
+	var rows []struct {
+		ID     uint
+		UserID uint
+	}
+	if err := db.Model(&TriviaParticipant{}).Select("id, user_id").Where("trivia_id = ?", triviaID).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	ids := make(map[uint]uint, len(rows))
+	for _, row := range rows {
+		ids[row.UserID] = row.ID
+	}
+	return ids, nil
+}
+
+var ErrTriviaAlreadyStarted = errors.New("trivia attempt already started")
+
+// StartTriviaAttempt creates the participant record for an async attempt,
+// with the server-side deadline and the participant's question order. It
+// returns ErrTriviaAlreadyStarted when a concurrent start created it first.
+func StartTriviaAttempt(db *gorm.DB, trivia *TriviaTournament, userID uint, order []TriviaQuestionOrder) (*TriviaParticipant, error) {
+	orderJSON, err := json.Marshal(order)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deadline := now.Add(time.Duration(trivia.DurationMinutes) * time.Minute)
+	if deadline.After(trivia.EndDate) {
+		deadline = trivia.EndDate
+	}
+
+	triviaIDInt := int(trivia.ID)
+	userIDInt := int(userID)
+	orderStr := string(orderJSON)
+	participant := &TriviaParticipant{
+		TriviaID:       &triviaIDInt,
+		UserID:         &userIDInt,
+		ParticipatedAt: now,
+		StartedAt:      &now,
+		DeadlineAt:     &deadline,
+		QuestionOrder:  &orderStr,
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(participant)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrTriviaAlreadyStarted
+	}
+	return participant, nil
+}
+
+var ErrTriviaAlreadySubmitted = errors.New("trivia answers already submitted")
+
+// CompleteTriviaAttempt stores the result of an attempt and its answers. The
+// update only applies to an unsubmitted attempt, so concurrent submissions
+// cannot both be scored.
+func CompleteTriviaAttempt(db *gorm.DB, participant *TriviaParticipant, answers []TriviaAnswer, submittedAt time.Time) error {
+	result := db.Model(&TriviaParticipant{}).
+		Where("id = ? AND submitted_at IS NULL", participant.ID).
+		Updates(map[string]interface{}{
+			"score":              participant.Score,
+			"correct_answers":    participant.CorrectAnswers,
+			"time_taken_seconds": participant.TimeTakenSeconds,
+			"submitted_at":       submittedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTriviaAlreadySubmitted
+	}
+	participant.SubmittedAt = &submittedAt
+	return CreateTriviaAnswers(db, answers)
+}
+
+// CreateTriviaAnswers records answers, ignoring repeats of an already
+// answered question.
+func CreateTriviaAnswers(db *gorm.DB, answers []TriviaAnswer) error {
+	if len(answers) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&answers).Error
+}
+
+// TriviaQuestionStat summarises how one question was answered.
+type TriviaQuestionStat struct {
+	QuestionIndex int `json:"question_id"`
+	Answered      int `json:"answered"`
+	Correct       int `json:"correct"`
+}
+
+func GetTriviaQuestionStats(db *gorm.DB, triviaID uint) ([]TriviaQuestionStat, error) {
+	var stats []TriviaQuestionStat
+	err := db.Model(&TriviaAnswer{}).
+		Select("question_index, COUNT(*) as answered, COUNT(*) FILTER (WHERE is_correct) as correct").
+		Where("trivia_id = ?", triviaID).
+		Group("question_index").
+		Order("question_index ASC").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// AddTriviaScore adds the points and response time of one answer to a
+// participant's running totals.
+func AddTriviaScore(db *gorm.DB, triviaID, userID uint, points int, correct bool, seconds int) error {
+	correctAnswers := 0
+	if correct {
+		correctAnswers = 1
+	}
+	return db.Model(&TriviaParticipant{}).
+		Where("trivia_id = ? AND user_id = ?", triviaID, userID).
+		Updates(map[string]interface{}{
+			"score":              gorm.Expr("score + ?", points),
+			"correct_answers":    gorm.Expr("correct_answers + ?", correctAnswers),
+			"time_taken_seconds": gorm.Expr("COALESCE(time_taken_seconds, 0) + ?", seconds),
+		}).Error
+}
+
+// RankTriviaParticipants recomputes ranks by score, breaking ties by the
+// faster total answer time.
+func RankTriviaParticipants(db *gorm.DB, triviaID uint) error {
+	return db.Exec(`
+		UPDATE trivia_participants p SET rank = r.rank
+		FROM (
+			SELECT id, RANK() OVER (ORDER BY score DESC, COALESCE(time_taken_seconds, 0) ASC) AS rank
+			FROM trivia_participants WHERE trivia_id = ?
+		) r
+		WHERE p.id = r.id`, triviaID).Error
+}
+
+// TriviaStanding is one row of a tournament's standings.
+type TriviaStanding struct {
+	UserID           uint   `json:"user_id"`
+	Name             string `json:"name"`
+	Score            int    `json:"score"`
+	CorrectAnswers   int    `json:"correct_answers"`
+	TimeTakenSeconds int    `json:"time_taken_seconds"`
+	Rank             int    `json:"rank"`
+}
+
+func GetTriviaStandings(db *gorm.DB, triviaID uint, limit int) ([]TriviaStanding, error) {
+	var standings []TriviaStanding
+	err := db.Model(&TriviaParticipant{}).
+		Select("trivia_participants.user_id, TRIM(users.first_name || ' ' || users.last_name) as name, trivia_participants.score, trivia_participants.correct_answers, COALESCE(trivia_participants.time_taken_seconds, 0) as time_taken_seconds, COALESCE(trivia_participants.rank, 0) as rank").
+		Joins("JOIN users ON users.id = trivia_participants.user_id").
+		Where("trivia_participants.trivia_id = ?", triviaID).
+		Order("trivia_participants.rank ASC NULLS LAST, trivia_participants.score DESC").
+		Limit(limit).
+		Scan(&standings).Error
+	return standings, err
+}
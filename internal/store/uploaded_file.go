@@ -0,0 +1,77 @@
+package store
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UploadedFile records who uploaded a stored object and what it was uploaded
+// for, which decides who may download it.
+type UploadedFile struct {
+	ID           uint      `gorm:"primaryKey"`
+	StorageKey   string    `gorm:"size:255;not null;uniqueIndex:idx_uploaded_files_key_owner_category"`
+	OwnerID      int       `gorm:"not null;uniqueIndex:idx_uploaded_files_key_owner_category"`
+	Category     string    `gorm:"size:20;not null;uniqueIndex:idx_uploaded_files_key_owner_category;check:category IN ('avatar', 'resume', 'submission', 'feed')"`
+	ContentType  string    `gorm:"size:100;not null"`
+	Size         int64     `gorm:"not null"`
+	OriginalName *string   `gorm:"size:255"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+
+	// Relations
+	Owner *User `gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE"`
+}
+
+func (UploadedFile) TableName() string {
+	return "uploaded_files"
+}
+
+// RecordUploadedFile stores the upload record. Uploading the same bytes twice
+// for the same owner and category keeps the original record; each category
+// gets its own, so downloads are allowed if any of them is public.
+func RecordUploadedFile(db *gorm.DB, file *UploadedFile) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "storage_key"}, {Name: "owner_id"}, {Name: "category"}},
+		DoNothing: true,
+	}).Create(file).Error
+}
+
+func GetUploadedFilesByKey(db *gorm.DB, key string) ([]UploadedFile, error) {
+	var files []UploadedFile
+	if err := db.Where("storage_key = ?", key).Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func GetSubmissionMediaBySubmission(db *gorm.DB, submissionID uint) ([]SubmissionMedia, error) {
+	var media []SubmissionMedia
+	if err := db.Where("submission_id = ?", submissionID).Order("created_at ASC").Find(&media).Error; err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// GetSubmissionThumbnails returns the first thumbnail URL of each submission
+// that has one, keyed by submission ID.
+func GetSubmissionThumbnails(db *gorm.DB, submissionIDs []uint) (map[uint]string, error) {
+	thumbnails := make(map[uint]string)
+	if len(submissionIDs) == 0 {
+		return thumbnails, nil
+	}
+
+	var media []SubmissionMedia
+	if err := db.Where("submission_id IN ? AND thumbnail_url IS NOT NULL", submissionIDs).
+		Order("created_at ASC").
+		Find(&media).Error; err != nil {
+		return nil, err
+	}
+	for _, m := range media {
+		id := uint(*m.SubmissionID)
+		if _, ok := thumbnails[id]; !ok {
+			thumbnails[id] = *m.ThumbnailURL
+		}
+	}
+	return thumbnails, nil
+}
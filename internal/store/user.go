@@ -31,8 +31,14 @@ type User struct {
 	AvatarURL           *string    `gorm:"type:text" json:"avatar_url,omitempty"`
 	ResumeURL           *string    `gorm:"type:text" json:"resume_url,omitempty"`
 	IsActive            bool       `gorm:"default:true" json:"is_active"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at,omitempty"`
 	CreatedAt           time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt           time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relations
+	College     *College     `gorm:"foreignKey:CollegeID" json:"college,omitempty"`
+	State       *State       `gorm:"foreignKey:StateID" json:"state,omitempty"`
+	ProfileSkin *ProfileSkin `gorm:"foreignKey:ProfileSkinID" json:"profile_skin,omitempty"`
 }
 
 func (User) TableName() string { return "users" }
@@ -141,7 +147,7 @@ func UpdateUserStreak(db *gorm.DB, streak *UserStreak) error {
 
 func GetUserWithRelations(db *gorm.DB, userID uint) (*User, error) {
 	var user User
-	if err := db.Preload("College").Preload("State").First(&user, userID).Error; err != nil {
+	if err := db.Preload("College").Preload("State").Preload("ProfileSkin").First(&user, userID).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -162,3 +168,8 @@ func GetUserCertificates(db *gorm.DB, userID uint) ([]Certificate, error) {
 	}
 	return certificates, nil
 }
+
+// DeleteSessionsByUser revokes every session belonging to the user.
+func DeleteSessionsByUser(db *gorm.DB, userID uint) error {
+	return db.Where("user_id = ?", userID).Delete(&UserSession{}).Error
+}
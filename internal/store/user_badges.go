@@ -25,6 +25,13 @@ func CreateUserBadge(db *gorm.DB, userBadge *UserBadge) error {
 	return db.Create(userBadge).Error
 }
 
+// HasUserBadge reports whether the user already holds the badge.
+func HasUserBadge(db *gorm.DB, userID uint, badgeID int) (bool, error) {
+	var count int64
+	err := db.Model(&UserBadge{}).Where("user_id = ? AND badge_id = ?", userID, badgeID).Count(&count).Error
+	return count > 0, err
+}
+
 func GetUserBadgeByID(db *gorm.DB, id uint) (*UserBadge, error) {
 	var userBadge UserBadge
 	if err := db.First(&userBadge, id).Error; err != nil {
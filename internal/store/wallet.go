@@ -70,3 +70,29 @@ func GetWalletTransactions(db *gorm.DB, userID uint, limit int) ([]WalletTransac
 	return transactions, nil
 }
 
+
+// CreditCoins adds coins to a user's wallet and records the credit.
+func CreditCoins(db *gorm.DB, userID uint, amount int, description, referenceType string, referenceID uint) (*WalletTransaction, error) {
+	if _, err := GetUserWallet(db, userID); err != nil {
+		return nil, err
+	}
+
+	if err := db.Model(&UserWallet{}).Where("user_id = ?", userID).
+		UpdateColumn("coins", gorm.Expr("coins + ?", amount)).Error; err != nil {
+		return nil, err
+	}
+
+	transaction := &WalletTransaction{
+		UserID:        userID,
+		Type:          "credit",
+		Amount:        float64(amount),
+		Currency:      "coins",
+		Description:   description,
+		ReferenceID:   &referenceID,
+		ReferenceType: &referenceType,
+	}
+	if err := db.Create(transaction).Error; err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
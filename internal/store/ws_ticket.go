@@ -0,0 +1,71 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidWSTicket = errors.New("invalid or expired ticket")
+
+// WSTicket lets a browser open a WebSocket without putting its session token
+// in the URL. Only the SHA-256 hash of the ticket is stored.
+type WSTicket struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    int        `gorm:"not null;index"`
+	TokenHash string     `gorm:"size:64;unique;not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time `gorm:"type:timestamp"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (WSTicket) TableName() string {
+	return "ws_tickets"
+}
+
+// CreateWSTicket stores a new ticket for the user and removes their used or
+// expired ones.
+func CreateWSTicket(db *gorm.DB, userID uint, ticket string, ttl time.Duration) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Where("user_id = ? AND (used_at IS NOT NULL OR expires_at <= ?)", userID, now).
+			Delete(&WSTicket{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&WSTicket{
+			UserID:    int(userID),
+			TokenHash: HashToken(ticket),
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+}
+
+// ConsumeWSTicket marks an unused, unexpired ticket as used and returns it. A
+// ticket can only be consumed once.
+func ConsumeWSTicket(db *gorm.DB, ticket string) (*WSTicket, error) {
+	var wsTicket WSTicket
+	if err := db.Where("token_hash = ?", HashToken(ticket)).First(&wsTicket).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidWSTicket
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	result := db.Model(&WSTicket{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", wsTicket.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidWSTicket
+	}
+
+	wsTicket.UsedAt = &now
+	return &wsTicket, nil
+}
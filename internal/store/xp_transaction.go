@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type XPTransaction struct {
@@ -26,6 +27,31 @@ func (XPTransaction) TableName() string {
 	return "xp_transactions"
 }
 
+// xpListeners are called after an XP transaction is recorded, e.g. to refresh
+// live leaderboards and re-check achievements.
+var xpListeners []func(userID uint)
+
+// OnXPChange registers a listener for XP movements. Listeners are registered
+// at startup, before any XP is awarded.
+func OnXPChange(listener func(userID uint)) {
+	xpListeners = append(xpListeners, listener)
+}
+
+// AfterCreate reports every XP movement to the listeners once it has
+// committed, so they see the new balance and never a rolled-back one.
+func (t *XPTransaction) AfterCreate(tx *gorm.DB) error {
+	if t.UserID == nil || len(xpListeners) == 0 {
+		return nil
+	}
+	userID := uint(*t.UserID)
+	AfterCommit(tx, func(*gorm.DB) {
+		for _, listener := range xpListeners {
+			listener(userID)
+		}
+	})
+	return nil
+}
+
 func CreateXPTransaction(db *gorm.DB, transaction *XPTransaction) error {
 	return db.Create(transaction).Error
 }
@@ -37,3 +63,46 @@ func GetXPTransactionByID(db *gorm.DB, id uint) (*XPTransaction, error) {
 	}
 	return &transaction, nil
 }
+
+// AwardXP credits (or debits, for a negative amount) a user's XP and logs the
+// change as an XPTransaction. All XP changes go through it so the log, the
+// balance and the XP listeners stay in step. The user row is locked so
+// concurrent awards cannot lose updates, and the balance is written
+// explicitly so the result is the same whether or not the xp_transactions
+// trigger also adjusted it. transactionType must be one the table's CHECK
+// allows; sourceType says what the XP was for.
+func AwardXP(db *gorm.DB, userID uint, amount int, transactionType, sourceType string, sourceID uint, description string) (*XPTransaction, error) {
+	var transaction *XPTransaction
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		balance := user.XP + amount
+		if balance < 0 {
+			balance = 0
+		}
+
+		userIDInt := int(userID)
+		sourceIDInt := int(sourceID)
+		transaction = &XPTransaction{
+			UserID:          &userIDInt,
+			TransactionType: transactionType,
+			Amount:          amount,
+			BalanceAfter:    balance,
+			SourceID:        &sourceIDInt,
+			SourceType:      &sourceType,
+			Description:     &description,
+		}
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&User{}).Where("id = ?", userID).Update("xp", balance).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
@@ -0,0 +1,3 @@
+-- user_wallets and wallet_transactions are kept: the up migration only
+-- creates them if they are missing
+DROP INDEX IF EXISTS idx_wallet_transactions_user;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS submission_appeals;
+
+UPDATE submissions SET status = 'rejected' WHERE status = 'appealed';
+ALTER TABLE submissions DROP CONSTRAINT IF EXISTS submissions_status_check;
+ALTER TABLE submissions ADD CONSTRAINT submissions_status_check
+CHECK (status IN ('draft', 'pending', 'under_review', 'approved', 'rejected', 'needs_revision'));
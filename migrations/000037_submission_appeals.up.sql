@@ -0,0 +1,29 @@
+-- Allow submissions to be marked as appealed
+ALTER TABLE submissions DROP CONSTRAINT IF EXISTS submissions_status_check;
+ALTER TABLE submissions ADD CONSTRAINT submissions_status_check
+CHECK (status IN ('draft', 'pending', 'under_review', 'approved', 'rejected', 'needs_revision', 'appealed'));
+
+-- Submission Appeals
+CREATE TABLE submission_appeals (
+    id SERIAL PRIMARY KEY,
+    submission_id INTEGER NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'upheld', 'overturned')),
+    reviewed_by INTEGER,
+    review_comments TEXT,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add foreign keys
+ALTER TABLE submission_appeals 
+ADD CONSTRAINT fk_submission_appeals_submission 
+FOREIGN KEY (submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+ADD CONSTRAINT fk_submission_appeals_user 
+FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+ADD CONSTRAINT fk_submission_appeals_reviewed_by 
+FOREIGN KEY (reviewed_by) REFERENCES users(id);
+
+CREATE INDEX idx_submission_appeals_status ON submission_appeals(status);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_submissions_revision_deadline;
+DROP TABLE IF EXISTS submission_revisions;
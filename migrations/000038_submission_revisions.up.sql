@@ -0,0 +1,25 @@
+-- Submission Revisions: proof history kept when a submission is resubmitted
+CREATE TABLE submission_revisions (
+    id SERIAL PRIMARY KEY,
+    submission_id INTEGER NOT NULL,
+    submission_stage VARCHAR(20) NOT NULL CHECK (submission_stage IN ('initial', 'resubmission')),
+    proof_type VARCHAR(50) NOT NULL,
+    proof_url TEXT NOT NULL,
+    proof_text TEXT,
+    submitted_at TIMESTAMP NOT NULL,
+    reviewed_by INTEGER,
+    reviewed_at TIMESTAMP,
+    review_comments TEXT,
+    revision_deadline TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add foreign keys
+ALTER TABLE submission_revisions 
+ADD CONSTRAINT fk_submission_revisions_submission 
+FOREIGN KEY (submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+ADD CONSTRAINT fk_submission_revisions_reviewed_by 
+FOREIGN KEY (reviewed_by) REFERENCES users(id);
+
+CREATE INDEX idx_submission_revisions_submission ON submission_revisions(submission_id);
+CREATE INDEX idx_submissions_revision_deadline ON submissions(revision_deadline) WHERE status = 'needs_revision';
@@ -0,0 +1,19 @@
+-- Uploaded Files
+CREATE TABLE uploaded_files (
+    id SERIAL PRIMARY KEY,
+    storage_key VARCHAR(255) NOT NULL,
+    owner_id INTEGER NOT NULL,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('avatar', 'resume', 'submission')),
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    original_name VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (storage_key, owner_id)
+);
+
+-- Add foreign keys
+ALTER TABLE uploaded_files 
+ADD CONSTRAINT fk_uploaded_files_owner 
+FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_uploaded_files_storage_key ON uploaded_files(storage_key);
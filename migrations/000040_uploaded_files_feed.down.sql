@@ -0,0 +1,4 @@
+DELETE FROM uploaded_files WHERE category = 'feed';
+ALTER TABLE uploaded_files DROP CONSTRAINT IF EXISTS uploaded_files_category_check;
+ALTER TABLE uploaded_files ADD CONSTRAINT uploaded_files_category_check
+CHECK (category IN ('avatar', 'resume', 'submission'));
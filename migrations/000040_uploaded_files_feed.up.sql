@@ -0,0 +1,4 @@
+-- Allow uploads shown in public feeds (battles, weekly vibe)
+ALTER TABLE uploaded_files DROP CONSTRAINT IF EXISTS uploaded_files_category_check;
+ALTER TABLE uploaded_files ADD CONSTRAINT uploaded_files_category_check
+CHECK (category IN ('avatar', 'resume', 'submission', 'feed'));
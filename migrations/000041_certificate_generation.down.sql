@@ -0,0 +1,13 @@
+ALTER TABLE badges DROP CONSTRAINT IF EXISTS fk_badges_certificate_template;
+ALTER TABLE badges DROP COLUMN IF EXISTS certificate_template_id;
+
+ALTER TABLE certificates DROP CONSTRAINT IF EXISTS fk_certificates_template;
+DROP INDEX IF EXISTS idx_certificates_user_source;
+ALTER TABLE certificates DROP CONSTRAINT IF EXISTS uq_certificates_verification_code;
+ALTER TABLE certificates
+DROP COLUMN IF EXISTS verification_code,
+DROP COLUMN IF EXISTS source_type,
+DROP COLUMN IF EXISTS source_id,
+DROP COLUMN IF EXISTS revoked_at;
+
+DROP TABLE IF EXISTS certificate_templates;
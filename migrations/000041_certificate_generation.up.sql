@@ -0,0 +1,57 @@
+-- Certificate Templates
+CREATE TABLE certificate_templates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    certificate_type VARCHAR(50) NOT NULL CHECK (certificate_type IN ('achievement', 'completion', 'winner', 'participation')),
+    heading VARCHAR(200) NOT NULL,
+    body_template TEXT NOT NULL,
+    accent_color VARCHAR(7) DEFAULT '#1F6F4A',
+    signatory_name VARCHAR(100),
+    signatory_title VARCHAR(100),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Verification codes and issuing source on certificates
+ALTER TABLE certificates
+ADD COLUMN verification_code VARCHAR(20),
+ADD COLUMN source_type VARCHAR(50),
+ADD COLUMN source_id INTEGER,
+ADD COLUMN revoked_at TIMESTAMP;
+
+UPDATE certificates
+SET verification_code = 'GG-' || upper(substr(md5(random()::text || id::text), 1, 4)) || '-' ||
+    upper(substr(md5(random()::text || id::text), 1, 4)) || '-' ||
+    upper(substr(md5(random()::text || id::text), 1, 4))
+WHERE verification_code IS NULL;
+
+ALTER TABLE certificates ALTER COLUMN verification_code SET NOT NULL;
+ALTER TABLE certificates ADD CONSTRAINT uq_certificates_verification_code UNIQUE (verification_code);
+
+-- A user receives one certificate per issuing source
+CREATE UNIQUE INDEX idx_certificates_user_source ON certificates(user_id, source_type, source_id)
+WHERE source_type IS NOT NULL;
+
+ALTER TABLE certificates
+ADD CONSTRAINT fk_certificates_template
+FOREIGN KEY (template_id) REFERENCES certificate_templates(id) ON DELETE SET NULL;
+
+-- Badges that come with a certificate
+ALTER TABLE badges ADD COLUMN certificate_template_id INTEGER;
+ALTER TABLE badges
+ADD CONSTRAINT fk_badges_certificate_template
+FOREIGN KEY (certificate_template_id) REFERENCES certificate_templates(id) ON DELETE SET NULL;
+
+-- Default templates
+INSERT INTO certificate_templates (name, certificate_type, heading, body_template, signatory_name, signatory_title)
+VALUES
+('Campaign Completion', 'completion', 'Certificate of Completion',
+ 'This is to certify that {{name}} has successfully completed the {{campaign}} campaign on {{date}}.',
+ 'Grove Growth', 'Campus Ambassador Program'),
+('Campaign Winner', 'winner', 'Certificate of Excellence',
+ 'This is to certify that {{name}} finished at rank {{rank}} in the {{campaign}} campaign on {{date}}.',
+ 'Grove Growth', 'Campus Ambassador Program'),
+('Badge Achievement', 'achievement', 'Certificate of Achievement',
+ 'This is to certify that {{name}} earned the {{badge}} badge on {{date}}.',
+ 'Grove Growth', 'Campus Ambassador Program');
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_trivia_tournaments_mode_status;
+ALTER TABLE trivia_tournaments
+DROP COLUMN IF EXISTS current_question,
+DROP COLUMN IF EXISTS question_seconds,
+DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,7 @@
+-- Live trivia: a server-driven game loop pushes one question at a time
+ALTER TABLE trivia_tournaments
+ADD COLUMN mode VARCHAR(20) NOT NULL DEFAULT 'async' CHECK (mode IN ('async', 'live')),
+ADD COLUMN question_seconds INTEGER NOT NULL DEFAULT 20 CHECK (question_seconds > 0),
+ADD COLUMN current_question INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_trivia_tournaments_mode_status ON trivia_tournaments(mode, status);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS trivia_answers;
+ALTER TABLE trivia_participants
+DROP COLUMN IF EXISTS question_order,
+DROP COLUMN IF EXISTS submitted_at,
+DROP COLUMN IF EXISTS deadline_at,
+DROP COLUMN IF EXISTS started_at;
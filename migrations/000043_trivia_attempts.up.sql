@@ -0,0 +1,20 @@
+-- Server-side trivia attempts: the server owns the timer and the question order
+ALTER TABLE trivia_participants
+ADD COLUMN started_at TIMESTAMP,
+ADD COLUMN deadline_at TIMESTAMP,
+ADD COLUMN submitted_at TIMESTAMP,
+ADD COLUMN question_order JSONB;
+
+-- Per-question answers, used for scoring and question difficulty stats
+CREATE TABLE trivia_answers (
+    id SERIAL PRIMARY KEY,
+    participant_id INTEGER NOT NULL REFERENCES trivia_participants(id) ON DELETE CASCADE,
+    trivia_id INTEGER NOT NULL REFERENCES trivia_tournaments(id) ON DELETE CASCADE,
+    question_index INTEGER NOT NULL,
+    answer TEXT NOT NULL,
+    is_correct BOOLEAN NOT NULL DEFAULT false,
+    answered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (participant_id, question_index)
+);
+
+CREATE INDEX idx_trivia_answers_trivia_question ON trivia_answers(trivia_id, question_index);
@@ -0,0 +1,2 @@
+-- Workers claim due jobs in scheduled order
+CREATE INDEX idx_scheduled_jobs_due ON scheduled_jobs(scheduled_for, id) WHERE status = 'pending';
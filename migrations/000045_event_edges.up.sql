@@ -0,0 +1,10 @@
+-- Log of lifecycle edges fired by the event scheduler. Each edge fires once
+-- per event; the unique key makes firing idempotent across server processes.
+CREATE TABLE event_edges (
+    id SERIAL PRIMARY KEY,
+    event_kind VARCHAR(50) NOT NULL,
+    event_id INTEGER NOT NULL,
+    edge VARCHAR(50) NOT NULL,
+    fired_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (event_kind, event_id, edge)
+);
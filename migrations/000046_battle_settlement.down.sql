@@ -0,0 +1,2 @@
+-- social_posts is kept: the up migration only creates it if it is missing
+ALTER TABLE content_battles DROP COLUMN IF EXISTS settled_at;
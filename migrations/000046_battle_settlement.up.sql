@@ -0,0 +1,20 @@
+-- Content battles are settled once when voting closes
+ALTER TABLE content_battles ADD COLUMN settled_at TIMESTAMP;
+
+-- The social feed model had no table; battle results are posted to it
+CREATE TABLE IF NOT EXISTS social_posts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    media_urls TEXT,
+    post_type VARCHAR(50) DEFAULT 'text',
+    is_public BOOLEAN DEFAULT true,
+    likes_count INTEGER DEFAULT 0,
+    comments_count INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_social_posts_user_id ON social_posts(user_id);
+CREATE INDEX IF NOT EXISTS idx_social_posts_created_at ON social_posts(created_at);
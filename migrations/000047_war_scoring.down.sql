@@ -0,0 +1,9 @@
+ALTER TABLE campus_wars DROP COLUMN IF EXISTS settled_at;
+
+DELETE FROM war_leaderboard_snapshots a
+USING war_leaderboard_snapshots b
+WHERE a.war_id = b.war_id AND a.snapshot_date::date = b.snapshot_date::date AND a.id < b.id;
+ALTER TABLE war_leaderboard_snapshots ALTER COLUMN snapshot_date TYPE DATE;
+
+DROP INDEX IF EXISTS idx_war_participants_war_score;
+ALTER TABLE war_participants DROP COLUMN IF EXISTS score;
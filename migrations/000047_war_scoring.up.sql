@@ -0,0 +1,9 @@
+-- Weighted war score maintained by the scoring engine
+ALTER TABLE war_participants ADD COLUMN score INTEGER DEFAULT 0;
+CREATE INDEX idx_war_participants_war_score ON war_participants(war_id, score DESC);
+
+-- Snapshots are taken hourly, so the snapshot key needs a time of day
+ALTER TABLE war_leaderboard_snapshots ALTER COLUMN snapshot_date TYPE TIMESTAMP;
+
+-- Wars are settled once when they end
+ALTER TABLE campus_wars ADD COLUMN settled_at TIMESTAMP;
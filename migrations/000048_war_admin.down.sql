@@ -0,0 +1,7 @@
+ALTER TABLE campus_wars DROP CONSTRAINT IF EXISTS fk_campus_wars_created_by;
+ALTER TABLE campus_wars DROP COLUMN IF EXISTS created_by;
+
+UPDATE campus_wars SET status = 'completed' WHERE status = 'cancelled';
+ALTER TABLE campus_wars DROP CONSTRAINT IF EXISTS campus_wars_status_check;
+ALTER TABLE campus_wars ADD CONSTRAINT campus_wars_status_check
+CHECK (status IN ('upcoming', 'active', 'completed'));
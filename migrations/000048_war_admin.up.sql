@@ -0,0 +1,9 @@
+-- Admins can cancel a war that has not finished
+ALTER TABLE campus_wars DROP CONSTRAINT IF EXISTS campus_wars_status_check;
+ALTER TABLE campus_wars ADD CONSTRAINT campus_wars_status_check
+CHECK (status IN ('upcoming', 'active', 'completed', 'cancelled'));
+
+ALTER TABLE campus_wars ADD COLUMN created_by INTEGER;
+ALTER TABLE campus_wars
+ADD CONSTRAINT fk_campus_wars_created_by
+FOREIGN KEY (created_by) REFERENCES users(id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_quests_expiry;
+DROP INDEX IF EXISTS idx_user_quests_user_status;
+ALTER TABLE user_quests DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,5 @@
+-- Accepted quests with a time limit fail once expires_at passes
+ALTER TABLE user_quests ADD COLUMN expires_at TIMESTAMP;
+
+CREATE INDEX idx_user_quests_user_status ON user_quests(user_id, status);
+CREATE INDEX idx_user_quests_expiry ON user_quests(expires_at) WHERE status = 'in_progress';
@@ -0,0 +1,4 @@
+-- achievements and user_achievements are kept: the up migration only
+-- creates them if they are missing
+DROP INDEX IF EXISTS idx_user_achievements_user_achievement;
+ALTER TABLE achievements DROP COLUMN IF EXISTS threshold;
@@ -0,0 +1,9 @@
+ALTER TABLE rewards_store DROP COLUMN IF EXISTS profile_skin_id;
+
+DROP TABLE IF EXISTS user_profile_skins;
+
+ALTER TABLE profile_skins DROP COLUMN IF EXISTS required_level_id;
+UPDATE profile_skins SET unlock_method = 'special' WHERE unlock_method = 'level';
+ALTER TABLE profile_skins DROP CONSTRAINT IF EXISTS profile_skins_unlock_method_check;
+ALTER TABLE profile_skins ADD CONSTRAINT profile_skins_unlock_method_check
+    CHECK (unlock_method IN ('xp', 'campaign', 'purchase', 'special'));
@@ -0,0 +1,18 @@
+-- Skins can also unlock on reaching a level
+ALTER TABLE profile_skins DROP CONSTRAINT IF EXISTS profile_skins_unlock_method_check;
+ALTER TABLE profile_skins ADD CONSTRAINT profile_skins_unlock_method_check
+    CHECK (unlock_method IN ('xp', 'level', 'campaign', 'purchase', 'special'));
+ALTER TABLE profile_skins ADD COLUMN required_level_id INTEGER REFERENCES levels(id);
+
+-- Skins each user has unlocked
+CREATE TABLE user_profile_skins (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    skin_id INTEGER NOT NULL REFERENCES profile_skins(id) ON DELETE CASCADE,
+    unlocked_via VARCHAR(50) NOT NULL,
+    unlocked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, skin_id)
+);
+
+-- Reward store items of type profile_skin grant this skin
+ALTER TABLE rewards_store ADD COLUMN profile_skin_id INTEGER REFERENCES profile_skins(id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_leaderboard_entries_leaderboard_snapshot;
+DROP INDEX IF EXISTS idx_leaderboards_series;
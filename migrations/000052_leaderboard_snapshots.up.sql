@@ -0,0 +1,9 @@
+-- Leaderboards without a period (global, college, state) have one row per
+-- name, which the period unique constraint cannot enforce for NULL periods
+CREATE UNIQUE INDEX idx_leaderboards_series
+ON leaderboards (leaderboard_type, name)
+WHERE period_start IS NULL;
+
+-- Snapshots are read by leaderboard and date
+CREATE INDEX idx_leaderboard_entries_leaderboard_snapshot
+ON leaderboard_entries (leaderboard_id, snapshot_date);
@@ -0,0 +1,16 @@
+-- Single-use tickets for authenticating browser WebSocket connections
+CREATE TABLE ws_tickets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add foreign key
+ALTER TABLE ws_tickets 
+ADD CONSTRAINT fk_ws_tickets_user 
+FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_ws_tickets_user ON ws_tickets(user_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_scheduled_jobs_recurring;
+ALTER TABLE scheduled_jobs DROP COLUMN IF EXISTS is_recurring;
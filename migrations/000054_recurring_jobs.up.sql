@@ -0,0 +1,5 @@
+-- Recurring jobs are rescheduled after every run instead of completing
+ALTER TABLE scheduled_jobs ADD COLUMN is_recurring BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- One recurring job per type, shared by every server process
+CREATE UNIQUE INDEX idx_scheduled_jobs_recurring ON scheduled_jobs(job_type) WHERE is_recurring;
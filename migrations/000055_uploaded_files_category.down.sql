@@ -0,0 +1,6 @@
+-- Keep the first record of each owner's upload
+DELETE FROM uploaded_files f USING uploaded_files earlier
+WHERE f.storage_key = earlier.storage_key AND f.owner_id = earlier.owner_id AND f.id > earlier.id;
+ALTER TABLE uploaded_files DROP CONSTRAINT IF EXISTS uploaded_files_storage_key_owner_id_category_key;
+ALTER TABLE uploaded_files ADD CONSTRAINT uploaded_files_storage_key_owner_id_key
+UNIQUE (storage_key, owner_id);
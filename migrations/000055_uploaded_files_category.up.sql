@@ -0,0 +1,5 @@
+-- The same bytes may be uploaded by one owner under several categories, each
+-- with its own visibility
+ALTER TABLE uploaded_files DROP CONSTRAINT IF EXISTS uploaded_files_storage_key_owner_id_key;
+ALTER TABLE uploaded_files ADD CONSTRAINT uploaded_files_storage_key_owner_id_category_key
+UNIQUE (storage_key, owner_id, category);
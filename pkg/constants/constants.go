@@ -27,6 +27,7 @@ const (
 const (
 	CampaignStatusDraft     = "draft"
 	CampaignStatusActive   = "active"
+	CampaignStatusPaused    = "paused"
 	CampaignStatusCompleted = "completed"
 	CampaignStatusCancelled = "cancelled"
 )
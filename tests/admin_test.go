@@ -64,21 +64,30 @@ func TestAdminResetPassword(t *testing.T) {
 // TestAdminCreateTask tests creating a task (admin)
 func TestAdminCreateTask(t *testing.T) {
 	// TODO: Implement when router setup is testable
-	// Note: This endpoint returns 501 Not Implemented
+	// Test cases:
+	// 1. Valid task with role/college/state/individual assignment target
+	// 2. Invalid task_type, proof_type, priority or assignment_type
+	// 3. Assignment target referencing unknown colleges/states/users
+	// 4. Unknown campaign
 	t.Log("Admin create task endpoint: POST /api/v1/admin/tasks")
 }
 
 // TestAdminUpdateTask tests updating a task (admin)
 func TestAdminUpdateTask(t *testing.T) {
 	// TODO: Implement when router setup is testable
-	// Note: This endpoint returns 501 Not Implemented
+	// Test cases:
+	// 1. Partial update keeps existing assignments
+	// 2. Changing assignment_target removes dropped assignees and adds new ones
+	// 3. Assignees in both the old and new target keep their accepted/completed status
 	t.Log("Admin update task endpoint: PUT /api/v1/admin/tasks/{id}")
 }
 
 // TestAdminDeleteTask tests deleting a task (admin)
 func TestAdminDeleteTask(t *testing.T) {
 	// TODO: Implement when router setup is testable
-	// Note: This endpoint returns 501 Not Implemented
+	// Test cases:
+	// 1. Task without submissions is deleted (204)
+	// 2. Task with submissions is deactivated instead (200)
 	t.Log("Admin delete task endpoint: DELETE /api/v1/admin/tasks/{id}")
 }
 
@@ -87,24 +96,41 @@ func TestAdminDeleteTask(t *testing.T) {
 // TestAdminCreateCampaign tests creating a campaign (admin)
 func TestAdminCreateCampaign(t *testing.T) {
 	// TODO: Implement when router setup is testable
-	// Note: This endpoint returns 501 Not Implemented
+	// Test cases:
+	// 1. Valid campaign is created as draft
+	// 2. end_date before start_date
 	t.Log("Admin create campaign endpoint: POST /api/v1/admin/campaigns")
 }
 
 // TestAdminUpdateCampaign tests updating a campaign (admin)
 func TestAdminUpdateCampaign(t *testing.T) {
 	// TODO: Implement when router setup is testable
-	// Note: This endpoint returns 501 Not Implemented
+	// Test cases:
+	// 1. Update draft or active campaign
+	// 2. Completed/cancelled campaign cannot be edited
 	t.Log("Admin update campaign endpoint: PUT /api/v1/admin/campaigns/{id}")
 }
 
 // TestAdminDeleteCampaign tests deleting a campaign (admin)
 func TestAdminDeleteCampaign(t *testing.T) {
 	// TODO: Implement when router setup is testable
-	// Note: This endpoint returns 501 Not Implemented
+	// Test cases:
+	// 1. Draft campaign is deleted
+	// 2. Non-draft campaign returns 409
 	t.Log("Admin delete campaign endpoint: DELETE /api/v1/admin/campaigns/{id}")
 }
 
+// TestAdminTransitionCampaign tests changing campaign status (admin)
+func TestAdminTransitionCampaign(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. draft -> active, active <-> paused, active -> completed
+	// 2. Illegal transition (e.g. completed -> active) returns 409
+	// 3. Completing freezes the campaign leaderboard
+	// 4. Paused campaign rejects joins and submissions
+	t.Log("Admin transition campaign endpoint: POST /api/v1/admin/campaigns/{id}/status")
+}
+
 // Admin Submission Review Tests
 
 // TestAdminGetPendingSubmissions tests getting pending submissions (admin)
@@ -126,9 +152,27 @@ func TestAdminReviewSubmission(t *testing.T) {
 	// 1. Approve submission
 	// 2. Reject submission
 	// 3. Invalid status
+	// 4. Approval awards XP and coins exactly once
+	// 5. Reviewing an already reviewed submission returns 409
 	t.Log("Admin review submission endpoint: POST /api/v1/admin/submissions/{id}/review")
 }
 
+// TestAdminGetSubmissionAppeals tests the appeal queue (admin)
+func TestAdminGetSubmissionAppeals(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	t.Log("Admin get submission appeals endpoint: GET /api/v1/admin/submissions/appeals")
+}
+
+// TestAdminResolveSubmissionAppeal tests resolving an appeal (admin)
+func TestAdminResolveSubmissionAppeal(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Uphold returns the submission to rejected
+	// 2. Overturn approves the submission and awards XP and coins
+	// 3. Resolving an already resolved appeal returns 409
+	t.Log("Admin resolve submission appeal endpoint: POST /api/v1/admin/submissions/appeals/{id}/resolve")
+}
+
 // Admin Gamification Tests
 
 // TestAdminAwardXP tests awarding XP to user (admin)
@@ -152,6 +196,115 @@ func TestAdminAwardBadge(t *testing.T) {
 	t.Log("Admin award badge endpoint: POST /api/v1/admin/badges/award")
 }
 
+// TestAdminTriviaQuestionStats tests per-question trivia stats (admin)
+func TestAdminTriviaQuestionStats(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Correct rate per question across async and live answers
+	// 2. Questions with few answers are unrated
+	// 3. Non-existent trivia
+	t.Log("Admin trivia question stats endpoint: GET /api/v1/admin/trivia/{id}/question-stats")
+}
+
+// TestAdminCreateWar tests creating a campus war (admin)
+func TestAdminCreateWar(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Valid war is created upcoming
+	// 2. end_date before start_date rejected
+	// 3. Invalid or all-disabled metrics rejected
+	// 4. Negative rewards or unknown badge rejected
+	t.Log("Admin create war endpoint: POST /api/v1/admin/wars")
+}
+
+// TestAdminUpdateWar tests editing an upcoming campus war (admin)
+func TestAdminUpdateWar(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Upcoming war is updated
+	// 2. Active war rejected with 409
+	// 3. war_type change with participants rejected with 409
+	t.Log("Admin update war endpoint: PUT /api/v1/admin/wars/{id}")
+}
+
+// TestAdminEnrollWarParticipants tests enrolling colleges or states (admin)
+func TestAdminEnrollWarParticipants(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Explicit entity_ids enrolled; unknown IDs rejected with 400
+	// 2. Filter enrols matching colleges by state and min_cas
+	// 3. Already enrolled entities skipped
+	// 4. Both or neither of entity_ids and filter rejected
+	t.Log("Admin enroll war participants endpoint: POST /api/v1/admin/wars/{id}/participants")
+}
+
+// TestAdminCancelWar tests cancelling a campus war (admin)
+func TestAdminCancelWar(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Upcoming or active war is cancelled
+	// 2. Completed war rejected with 409
+	t.Log("Admin cancel war endpoint: POST /api/v1/admin/wars/{id}/cancel")
+}
+
+// TestAdminExtendWar tests extending a campus war (admin)
+func TestAdminExtendWar(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Later end_date accepted
+	// 2. Earlier or past end_date rejected
+	// 3. Completed war rejected with 409
+	t.Log("Admin extend war endpoint: POST /api/v1/admin/wars/{id}/extend")
+}
+
+// TestAdminCreateBingo tests creating a bingo card (admin)
+func TestAdminCreateBingo(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Valid 3x3 card with badge, activity and free cells
+	// 2. Wrong cell count or a cell with two kinds rejected
+	// 3. Unknown badge in a cell or reward rejected
+	t.Log("Admin create bingo endpoint: POST /api/v1/admin/bingo")
+}
+
+// TestAdminUpdateBingo tests updating a bingo card (admin)
+func TestAdminUpdateBingo(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Bingo that has not started is updated
+	// 2. Started bingo rejected with 409
+	t.Log("Admin update bingo endpoint: PUT /api/v1/admin/bingo/{id}")
+}
+
+// TestAdminGetJobs tests listing scheduled jobs (admin)
+func TestAdminGetJobs(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Filter by status and job type
+	// 2. Invalid status filter
+	t.Log("Admin jobs endpoint: GET /api/v1/admin/jobs")
+}
+
+// TestAdminCancelJob tests cancelling a pending job (admin)
+func TestAdminCancelJob(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Pending job is cancelled
+	// 2. Running or finished job is rejected with 409
+	// 3. Cancelled recurring job is not recreated on restart
+	t.Log("Admin cancel job endpoint: POST /api/v1/admin/jobs/{id}/cancel")
+}
+
+// TestAdminRerunJob tests re-running a finished job (admin)
+func TestAdminRerunJob(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Failed job is queued again with attempts reset
+	// 2. Pending job is rejected with 409
+	// 3. Cancelled recurring job resumes its schedule
+	t.Log("Admin rerun job endpoint: POST /api/v1/admin/jobs/{id}/rerun")
+}
+
 // Admin Dashboard & Analytics Tests
 
 // TestAdminDashboard tests getting admin dashboard (admin)
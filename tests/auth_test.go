@@ -73,6 +73,8 @@ func TestResetPassword(t *testing.T) {
 	// 2. Invalid token
 	// 3. Expired token
 	// 4. Weak password
+	// 5. Token already used
+	// 6. Existing sessions are revoked after reset
 	t.Log("Reset password endpoint: POST /api/v1/auth/reset-password")
 }
 
@@ -83,5 +85,6 @@ func TestVerifyEmail(t *testing.T) {
 	// 1. Valid token
 	// 2. Invalid token
 	// 3. Expired token
+	// 4. Token already used
 	t.Log("Verify email endpoint: GET /api/v1/auth/verify-email/{token}")
 }
@@ -0,0 +1,33 @@
+package tests
+
+import (
+	"testing"
+)
+
+// TestGetActiveBingos tests getting running bingo cards
+func TestGetActiveBingos(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	t.Log("Get active bingos endpoint: GET /api/v1/bingo/active")
+}
+
+// TestGetBingo tests getting a bingo card with the user's progress
+func TestGetBingo(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Valid bingo ID without progress shows only free cells marked
+	// 2. Invalid bingo ID
+	t.Log("Get bingo endpoint: GET /api/v1/bingo/{id}")
+}
+
+// TestBingoGameplay tests marking cells and paying line bundles
+func TestBingoGameplay(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Earning a badge marks its cell on every running card
+	// 2. Activities mark cells matching their campaign or task
+	// 3. Completing a row, column or diagonal pays its bundle exactly once
+	// 4. Cells on bingos outside their window are not marked
+	// 5. Badges held before the bingo started are marked on the user's first event
+	// 6. A bingo with an invalid card is skipped; other cards are still marked
+	t.Log("Badge bingo: cells marked by badges and activities, lines detected automatically")
+}
@@ -38,5 +38,9 @@ func TestGetCampaignTasks(t *testing.T) {
 // TestGetCampaignLeaderboard tests getting campaign leaderboard
 func TestGetCampaignLeaderboard(t *testing.T) {
 	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Active campaign serves its latest snapshot with trends
+	// 2. Completed campaign serves the snapshot frozen at completion
+	// 3. as_of serves an earlier snapshot
 	t.Log("Get campaign leaderboard endpoint: GET /api/v1/campaigns/{id}/leaderboard")
 }
@@ -19,6 +19,7 @@ func TestGetCollegeLeaderboard(t *testing.T) {
 	// Test cases:
 	// 1. Default limit
 	// 2. Custom limit
+	// 3. as_of serves the latest snapshot on or before that date
 	t.Log("Get college leaderboard endpoint: GET /api/v1/colleges/{id}/leaderboard")
 }
 
@@ -37,5 +38,6 @@ func TestGetStateLeaderboard(t *testing.T) {
 	// Test cases:
 	// 1. Default limit
 	// 2. Custom limit
+	// 3. as_of serves the latest snapshot on or before that date
 	t.Log("Get state leaderboard endpoint: GET /api/v1/states/{id}/leaderboard")
 }
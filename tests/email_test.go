@@ -22,5 +22,8 @@ func TestUpdateEmailPreferences(t *testing.T) {
 // TestResendVerificationEmail tests resending verification email
 func TestResendVerificationEmail(t *testing.T) {
 	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Unverified user gets a new token, previous token is invalidated
+	// 2. Already verified user returns 409
 	t.Log("Resend verification email endpoint: POST /api/v1/email/verify/resend")
 }
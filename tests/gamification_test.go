@@ -44,6 +44,9 @@ func TestGetBadges(t *testing.T) {
 // TestGetBadgeByID tests getting a badge by ID
 func TestGetBadgeByID(t *testing.T) {
 	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Public badge
+	// 2. Secret badge hidden unless the user holds it
 	t.Log("Get badge by ID endpoint: GET /api/v1/badges/{id}")
 }
 
@@ -53,6 +56,18 @@ func TestGetUserBadges(t *testing.T) {
 	t.Log("Get user badges endpoint: GET /api/v1/badges/me")
 }
 
+// TestAchievementEngine tests automatic badge and achievement awarding
+func TestAchievementEngine(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Reaching a badge's criteria_value awards it once with its xp_reward
+	// 2. Achievements awarded when their category stat reaches the threshold
+	// 3. Limited edition badges not awarded after available_until
+	// 4. Special badges never awarded automatically
+	// 5. Concurrent checks for one user award nothing twice
+	t.Log("Achievement engine: re-checked after activities, streaks, posts and XP changes")
+}
+
 // TestGetStreaks tests getting user streaks
 func TestGetStreaks(t *testing.T) {
 	// TODO: Implement when router setup is testable
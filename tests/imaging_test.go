@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/rohit21755/gg_server.git/internal/imaging"
+)
+
+// encodePNG builds a PNG of the given size filled with one colour.
+func encodePNG(t *testing.T, width, height int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader builds just the signature and IHDR chunk of a PNG claiming the
+// given size, which is all DecodeConfig reads.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:4], width)
+	binary.BigEndian.PutUint32(ihdr[4:8], height)
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 6 // RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+// TestImagingDecode tests decoding uploaded images
+func TestImagingDecode(t *testing.T) {
+	img, format, err := imaging.Decode(encodePNG(t, 4, 3, color.White))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if format != "png" || img.Bounds().Dx() != 4 || img.Bounds().Dy() != 3 {
+		t.Errorf("got %s %v, want png 4x3", format, img.Bounds())
+	}
+
+	if _, _, err := imaging.Decode([]byte("%PDF-1.4 not an image")); !errors.Is(err, imaging.ErrUnsupportedFormat) {
+		t.Errorf("non-image: got %v, want ErrUnsupportedFormat", err)
+	}
+
+	if _, _, err := imaging.Decode(pngHeader(20000, 20000)); !errors.Is(err, imaging.ErrImageTooLarge) {
+		t.Errorf("oversized image: got %v, want ErrImageTooLarge", err)
+	}
+}
+
+// TestImagingFit tests scaling images down to a bounding box
+func TestImagingFit(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+
+	if got := imaging.Fit(img, 1000, 1000); got != image.Image(img) {
+		t.Errorf("image that already fits was resized to %v", got.Bounds())
+	}
+
+	tests := []struct {
+		maxWidth, maxHeight int
+		want                image.Rectangle
+	}{
+		{100, 100, image.Rect(0, 0, 100, 50)},
+		{400, 50, image.Rect(0, 0, 100, 50)},
+		{300, 1000, image.Rect(0, 0, 300, 150)},
+	}
+	for _, tt := range tests {
+		if got := imaging.Fit(img, tt.maxWidth, tt.maxHeight).Bounds(); got != tt.want {
+			t.Errorf("Fit(400x200, %d, %d) = %v, want %v", tt.maxWidth, tt.maxHeight, got, tt.want)
+		}
+	}
+}
+
+// TestImagingThumbnail tests centre-cropped thumbnails
+func TestImagingThumbnail(t *testing.T) {
+	// Left and right thirds red, centre blue: a square crop keeps only blue
+	img := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 100 && x < 200 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	thumb := imaging.Thumbnail(img, 10, 10)
+	if thumb.Bounds() != image.Rect(0, 0, 10, 10) {
+		t.Fatalf("thumbnail is %v, want 10x10", thumb.Bounds())
+	}
+	for _, p := range []image.Point{{0, 0}, {9, 9}, {5, 5}} {
+		if r, _, b, _ := thumb.At(p.X, p.Y).RGBA(); r != 0 || b != 0xffff {
+			t.Errorf("pixel %v is not from the centre of the source", p)
+		}
+	}
+}
+
+// TestImagingEncode tests re-encoding keeps the format family
+func TestImagingEncode(t *testing.T) {
+	img, format, err := imaging.Decode(encodePNG(t, 8, 8, color.RGBA{G: 255, A: 128}))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+
+	data, err := imaging.Encode(img, format)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if _, format, err := image.DecodeConfig(bytes.NewReader(data)); err != nil || format != "png" {
+		t.Errorf("re-encoded png decoded as %q (%v)", format, err)
+	}
+
+	data, err = imaging.Encode(img, "jpeg")
+	if err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	if _, format, err := image.DecodeConfig(bytes.NewReader(data)); err != nil || format != "jpeg" {
+		t.Errorf("re-encoded jpeg decoded as %q (%v)", format, err)
+	}
+}
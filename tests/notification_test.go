@@ -39,3 +39,24 @@ func TestDeleteNotification(t *testing.T) {
 	// 2. Invalid notification ID
 	t.Log("Delete notification endpoint: DELETE /api/v1/notifications/{id}")
 }
+
+// TestNotificationPush tests live notification delivery over the WebSocket
+func TestNotificationPush(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Created notification is pushed with the new unread count
+	// 2. Marking as read pushes the updated unread count
+	// 3. Reconnecting with last_notification_id replays missed notifications
+	// 4. Notification created in a transaction is pushed only after it commits, never after a rollback
+	t.Log("Notification push: WS /ws")
+}
+
+// TestWebSocketTicket tests authenticating browser WebSocket connections
+func TestWebSocketTicket(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Ticket from POST /ws/ticket opens /ws?ticket=... once
+	// 2. Reused or expired ticket is rejected with 401
+	// 3. Session token in the query string is rejected
+	t.Log("WebSocket ticket endpoint: POST /api/v1/ws/ticket")
+}
@@ -23,5 +23,41 @@ func TestGetGlobalLeaderboard(t *testing.T) {
 	// 1. Default limit
 	// 2. Custom limit
 	// 3. Limit exceeds maximum
+	// 4. period=weekly and period=monthly rank XP earned in the period
+	// 5. as_of serves the latest snapshot on or before that date
+	// 6. Invalid period or as_of
 	t.Log("Global leaderboard endpoint: GET /api/v1/leaderboards/global")
 }
+
+// TestLeaderboardSnapshots tests the periodic leaderboard snapshots
+func TestLeaderboardSnapshots(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Each run replaces the day's snapshot instead of adding another
+	// 2. Previous rank and trend compare against the latest earlier snapshot
+	// 3. A new week's first snapshot compares against the previous week's final one
+	// 4. Ended weekly and monthly leaderboards get a final snapshot and are deactivated
+	// 5. Runs as one recurring job however many servers are running
+	t.Log("Leaderboard snapshots: global, weekly, monthly, college, state and campaign")
+}
+
+// TestVerifyCertificate tests public certificate verification
+func TestVerifyCertificate(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Valid code
+	// 2. Revoked certificate
+	// 3. Unknown code
+	t.Log("Verify certificate endpoint: GET /api/v1/certificates/verify/{code}")
+}
+
+// TestLiveLeaderboard tests leaderboard subscriptions over the WebSocket
+func TestLiveLeaderboard(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Subscribing replies with a snapshot of the board
+	// 2. A burst of XP awards produces a single coalesced delta
+	// 3. Unknown board or missing id is rejected
+	// 4. XP from spin wheel, mystery boxes, secret codes, battles, surveys, refunds and admin awards streams a delta
+	t.Log("Live leaderboard: WS /ws leaderboard.subscribe")
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"testing"
+)
+
+// TestGetQuests tests listing available quests
+func TestGetQuests(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	t.Log("Get quests endpoint: GET /api/v1/quests")
+}
+
+// TestGetMyQuests tests listing the user's accepted quests
+func TestGetMyQuests(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. No filter returns every attempt
+	// 2. status=in_progress filters attempts
+	// 3. Unknown status is rejected
+	t.Log("Get my quests endpoint: GET /api/v1/quests/me")
+}
+
+// TestAcceptQuest tests accepting a quest
+func TestAcceptQuest(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Accepting starts at step 1 with expires_at from time_limit_days
+	// 2. Accepting a quest already in progress or completed is rejected
+	// 3. Failed or abandoned quests can be accepted again
+	// 4. Inactive quest is rejected
+	t.Log("Accept quest endpoint: POST /api/v1/quests/{id}/accept")
+}
+
+// TestGetQuestProgress tests getting quest progress
+func TestGetQuestProgress(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	t.Log("Get quest progress endpoint: GET /api/v1/quests/{id}/progress")
+}
+
+// TestQuestEngine tests quest progress driven by domain events
+func TestQuestEngine(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Matching events count toward the current step only
+	// 2. Campaign and task filters on a step are respected
+	// 3. Finishing the last step completes the quest and pays rewards once
+	// 4. Quests past expires_at are failed by the sweep
+	// 5. A quest with unparseable steps is skipped; the user's other quests still advance
+	t.Log("Quest engine: submission_approved, referral, battle_entered and battle_won events")
+}
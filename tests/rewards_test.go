@@ -24,6 +24,7 @@ func TestRedeemReward(t *testing.T) {
 	// 2. Insufficient points/balance
 	// 3. Reward not found
 	// 4. Already redeemed
+	// 5. profile_skin reward unlocks its skin and is delivered; owned skin returns 409
 	t.Log("Redeem reward endpoint: POST /api/v1/rewards/{id}/redeem")
 }
 
@@ -0,0 +1,36 @@
+package tests
+
+import (
+	"testing"
+)
+
+// TestGetProfileSkins tests listing skins with lock state
+func TestGetProfileSkins(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Unlocked skins show unlocked_via and whether they are equipped
+	// 2. Locked skins show can_unlock and locked_reason
+	t.Log("Get profile skins endpoint: GET /api/v1/skins")
+}
+
+// TestUnlockProfileSkin tests unlocking a skin
+func TestUnlockProfileSkin(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. xp skin spends its XP cost
+	// 2. level skin requires the level's rank or higher
+	// 3. campaign skin requires a completed campaign with an approved submission
+	// 4. purchase and special skins cannot be unlocked directly
+	// 5. Unlocking an owned skin returns 409
+	t.Log("Unlock profile skin endpoint: POST /api/v1/skins/{id}/unlock")
+}
+
+// TestEquipProfileSkin tests equipping and unequipping skins
+func TestEquipProfileSkin(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Equipping an unlocked skin shows it on profile, leaderboards and feed
+	// 2. Equipping a locked skin returns 400
+	// 3. Unequipping clears the skin
+	t.Log("Equip profile skin endpoints: POST /api/v1/skins/{id}/equip, POST /api/v1/skins/unequip")
+}
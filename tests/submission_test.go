@@ -38,6 +38,8 @@ func TestUpdateSubmission(t *testing.T) {
 	// 1. Valid update
 	// 2. Update non-existent submission
 	// 3. Update submission that's already reviewed
+	// 4. Needs-revision submission is resubmitted and the old proof kept as a revision
+	// 5. Resubmission after the revision deadline is rejected
 	t.Log("Update submission endpoint: PUT /api/v1/submissions/{id}")
 }
 
@@ -59,6 +61,20 @@ func TestGetSubmissionProof(t *testing.T) {
 // TestAppealSubmission tests appealing a rejected submission
 func TestAppealSubmission(t *testing.T) {
 	// TODO: Implement when router setup is testable
-	// Note: This endpoint returns 501 Not Implemented
+	// Test cases:
+	// 1. Rejected submission within the appeal window
+	// 2. Submission that is not rejected
+	// 3. Appeal window has closed
+	// 4. Second appeal for the same submission
 	t.Log("Appeal submission endpoint: POST /api/v1/submissions/{id}/appeal")
 }
+
+// TestUploadSubmissionMedia tests attaching media to a submission
+func TestUploadSubmissionMedia(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Image upload creates an image media row
+	// 2. File whose content does not match an allowed type
+	// 3. Submission owned by another user
+	t.Log("Upload submission media endpoint: POST /api/v1/submissions/{id}/media")
+}
@@ -77,3 +77,14 @@ func TestGetUserStats(t *testing.T) {
 	// 2. Invalid user ID
 	t.Log("Get user stats endpoint: GET /api/v1/users/{id}/stats")
 }
+
+// TestDownloadFile tests downloading an uploaded file
+func TestDownloadFile(t *testing.T) {
+	// TODO: Implement when router setup is testable
+	// Test cases:
+	// 1. Avatar readable by any authenticated user
+	// 2. Resume readable only by its owner and staff
+	// 3. Unknown key
+	// 4. Same bytes uploaded as a resume and then as feed media become readable by anyone
+	t.Log("Download file endpoint: GET /api/v1/files/{key}")
+}
@@ -1,10 +1,67 @@
 package ws
 
-import "github.com/gorilla/websocket"
+import (
+	"encoding/json"
+	"log"
 
+	"github.com/gorilla/websocket"
+)
+
+// Client is one authenticated WebSocket connection. A user may have several.
 type Client struct {
-	Conn *websocket.Conn
-	Send chan []byte
+	Conn      *websocket.Conn
+	Send      chan []byte
+	UserID    uint
+	CollegeID *int
+	StateID   *int
+
+	// rooms is only touched by the hub goroutine
+	rooms map[string]bool
+}
+
+// Message is the JSON envelope for every frame exchanged over the socket.
+type Message struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// Encode marshals a typed envelope.
+func Encode(messageType string, data interface{}) []byte {
+	msg, err := json.Marshal(Message{Type: messageType, Data: data})
+	if err != nil {
+		log.Printf("ws: failed to encode %s message: %v", messageType, err)
+		return nil
+	}
+	return msg
+}
+
+type targetKind int
+
+const (
+	toUser targetKind = iota
+	toCollege
+	toState
+	toRoom
+	toClient
+)
+
+type delivery struct {
+	kind    targetKind
+	id      int
+	room    string
+	client  *Client
+	message []byte
+}
+
+type roomSizeRequest struct {
+	room  string
+	reply chan int
+}
+
+type membership struct {
+	client *Client
+	room   string
+	join   bool
 }
 
 type Hub struct {
@@ -12,14 +69,30 @@ type Hub struct {
 	Broadcast  chan []byte
 	Register   chan *Client
 	Unregister chan *Client
+
+	users    map[uint]map[*Client]bool
+	colleges map[int]map[*Client]bool
+	states   map[int]map[*Client]bool
+	rooms    map[string]map[*Client]bool
+
+	deliver     chan delivery
+	memberships chan membership
+	roomSizes   chan roomSizeRequest
 }
 
 func NewHub() *Hub {
 	return &Hub{
-		Clients:    make(map[*Client]bool),
-		Broadcast:  make(chan []byte),
-		Register:   make(chan *Client),
-		Unregister: make(chan *Client),
+		Clients:     make(map[*Client]bool),
+		Broadcast:   make(chan []byte),
+		Register:    make(chan *Client),
+		Unregister:  make(chan *Client),
+		users:       make(map[uint]map[*Client]bool),
+		colleges:    make(map[int]map[*Client]bool),
+		states:      make(map[int]map[*Client]bool),
+		rooms:       make(map[string]map[*Client]bool),
+		deliver:     make(chan delivery, 256),
+		memberships: make(chan membership, 64),
+		roomSizes:   make(chan roomSizeRequest),
 	}
 }
 
@@ -28,14 +101,142 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.Register:
 			h.Clients[client] = true
+			client.rooms = make(map[string]bool)
+			addTo(h.users, client.UserID, client)
+			if client.CollegeID != nil {
+				addTo(h.colleges, *client.CollegeID, client)
+			}
+			if client.StateID != nil {
+				addTo(h.states, *client.StateID, client)
+			}
 
 		case client := <-h.Unregister:
-			delete(h.Clients, client)
+			h.remove(client)
 
 		case message := <-h.Broadcast:
 			for client := range h.Clients {
-				client.Send <- message
+				h.send(client, message)
+			}
+
+		case d := <-h.deliver:
+			var targets map[*Client]bool
+			switch d.kind {
+			case toUser:
+				targets = h.users[uint(d.id)]
+			case toCollege:
+				targets = h.colleges[d.id]
+			case toState:
+				targets = h.states[d.id]
+			case toRoom:
+				targets = h.rooms[d.room]
+			case toClient:
+				if h.Clients[d.client] {
+					targets = map[*Client]bool{d.client: true}
+				}
+			}
+			for client := range targets {
+				h.send(client, d.message)
+			}
+
+		case req := <-h.roomSizes:
+			req.reply <- len(h.rooms[req.room])
+
+		case m := <-h.memberships:
+			if !h.Clients[m.client] {
+				continue
+			}
+			if m.join {
+				addTo(h.rooms, m.room, m.client)
+				m.client.rooms[m.room] = true
+			} else {
+				removeFrom(h.rooms, m.room, m.client)
+				delete(m.client.rooms, m.room)
 			}
 		}
 	}
 }
+
+// SendToUser delivers a message to every connection of a user.
+func (h *Hub) SendToUser(userID uint, message []byte) {
+	h.deliver <- delivery{kind: toUser, id: int(userID), message: message}
+}
+
+// SendToCollege delivers a message to every connected user of a college.
+func (h *Hub) SendToCollege(collegeID int, message []byte) {
+	h.deliver <- delivery{kind: toCollege, id: collegeID, message: message}
+}
+
+// SendToState delivers a message to every connected user of a state.
+func (h *Hub) SendToState(stateID int, message []byte) {
+	h.deliver <- delivery{kind: toState, id: stateID, message: message}
+}
+
+// SendToRoom delivers a message to every connection that joined the room.
+func (h *Hub) SendToRoom(room string, message []byte) {
+	h.deliver <- delivery{kind: toRoom, room: room, message: message}
+}
+
+// SendToClient delivers a message to a single connection, e.g. a reply to a
+// frame it sent.
+func (h *Hub) SendToClient(client *Client, message []byte) {
+	h.deliver <- delivery{kind: toClient, client: client, message: message}
+}
+
+// JoinRoom subscribes a connection to a named room.
+func (h *Hub) JoinRoom(client *Client, room string) {
+	h.memberships <- membership{client: client, room: room, join: true}
+}
+
+// LeaveRoom unsubscribes a connection from a named room.
+func (h *Hub) LeaveRoom(client *Client, room string) {
+	h.memberships <- membership{client: client, room: room, join: false}
+}
+
+// RoomSize returns how many connections are currently in a room.
+func (h *Hub) RoomSize(room string) int {
+	reply := make(chan int, 1)
+	h.roomSizes <- roomSizeRequest{room: room, reply: reply}
+	return <-reply
+}
+
+// send queues a message without blocking the hub. A client whose buffer is
+// full is too slow to keep up and is disconnected.
+func (h *Hub) send(client *Client, message []byte) {
+	select {
+	case client.Send <- message:
+	default:
+		h.remove(client)
+	}
+}
+
+func (h *Hub) remove(client *Client) {
+	if !h.Clients[client] {
+		return
+	}
+	delete(h.Clients, client)
+	removeFrom(h.users, client.UserID, client)
+	if client.CollegeID != nil {
+		removeFrom(h.colleges, *client.CollegeID, client)
+	}
+	if client.StateID != nil {
+		removeFrom(h.states, *client.StateID, client)
+	}
+	for room := range client.rooms {
+		removeFrom(h.rooms, room, client)
+	}
+	close(client.Send)
+}
+
+func addTo[K comparable](index map[K]map[*Client]bool, key K, client *Client) {
+	if index[key] == nil {
+		index[key] = make(map[*Client]bool)
+	}
+	index[key][client] = true
+}
+
+func removeFrom[K comparable](index map[K]map[*Client]bool, key K, client *Client) {
+	delete(index[key], client)
+	if len(index[key]) == 0 {
+		delete(index, key)
+	}
+}